@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/snapshot"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var snapshots *snapshot.Manager
+
+// SetSnapshotManager enables POST /api/v1/admin/snapshot.
+func SetSnapshotManager(m *snapshot.Manager) {
+	snapshots = m
+}
+
+// takeSnapshot saves the service state now rather than waiting for the next
+// periodic snapshot.
+func takeSnapshot(c *gin.Context) {
+	if snapshots == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "snapshots not configured"})
+		return
+	}
+	info, err := snapshots.Save(c.Request.Context(), sim.Now())
+	if err != nil {
+		requestLogger(c).Error("Snapshot failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	requestLogger(c).Info("snapshot saved", zap.Int("resources", info.Resources), zap.Int("bytes", info.Bytes))
+	c.JSON(http.StatusOK, info)
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/chanducheryala/cloud-resource/internal/anomaly"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/gin-gonic/gin"
+)
+
+// getAnomalies returns the resource and owner cost series whose latest point is
+// anomalous, among the resources selected by ?type=, ?owner=, ?region= and
+// ?tag=.
+func getAnomalies(c *gin.Context) {
+	res, ok := filteredResources(c)
+	if !ok {
+		return
+	}
+	anomalies := anomaly.Detect(res, history.Default(), anomaly.CurrentConfig())
+	if anomalies == nil {
+		anomalies = []anomaly.Anomaly{}
+	}
+	c.JSON(http.StatusOK, anomalies)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"github.com/chanducheryala/cloud-resource/internal/analyzer"
 	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/replay"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
@@ -24,8 +26,9 @@ var (
 )
 
 var (
-	redisAddr string
-	redisDB   int
+	redisAddr     string
+	redisDB       int
+	recordingsDir string
 )
 
 func LoadAPIConfig() {
@@ -45,6 +48,11 @@ func LoadAPIConfig() {
 			redisDB = v
 		}
 	}
+
+	recordingsDir = os.Getenv("RECORDINGS_DIR")
+	if recordingsDir == "" {
+		recordingsDir = "recordings"
+	}
 }
 
 func GetLogger() *zap.Logger {
@@ -76,46 +84,82 @@ func setupRedis() {
 }
 
 func getAllResources(c *gin.Context) {
-	resourceMutex.RLock()
-	defer resourceMutex.RUnlock()
-	logger.Info("all resources", zap.Int("count", len(resources)))
-	c.JSON(http.StatusOK, resources)
+	res, ok := filteredResources(c)
+	if !ok {
+		return
+	}
+	requestLogger(c).Info("all resources", zap.Int("count", len(res)))
+	c.JSON(http.StatusOK, models.Resources(res))
 }
 
 func getResourceByID(c *gin.Context) {
 	id := c.Param("id")
-	resourceMutex.RLock()
-	defer resourceMutex.RUnlock()
-	for _, r := range resources {
+	for _, r := range currentResources(c) {
 		if r.GetId() == id {
-			logger.Info("resource by id", zap.String("id", id))
-			c.JSON(http.StatusOK, r)
+			b, err := models.Marshal(r)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			requestLogger(c).Info("resource by id", zap.String("id", id))
+			c.Data(http.StatusOK, "application/json; charset=utf-8", b)
 			return
 		}
 	}
-	logger.Warn("Resource not found", zap.String("id", id))
+	requestLogger(c).Warn("Resource not found", zap.String("id", id))
 	c.JSON(http.StatusNotFound, gin.H{"error": "resource not found"})
 }
 
 func getResourceHistory(c *gin.Context) {
 	id := c.Param("id")
 	ctx := context.Background()
-	entries, err := redisClient.LRange(ctx, "resource:"+ id +":history", 0, -1).Result()
+	entries, err := redisClient.LRange(ctx, snapshotKey(tenantOf(c), id), 0, -1).Result()
 	if err != nil {
-		logger.Error("Redis LRange failed", zap.String("id", id), zap.Error(err))
+		requestLogger(c).Error("Redis LRange failed", zap.String("id", id), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	var history []map[string]interface{}
+	history := []replay.Snapshot{}
 	for _, entry := range entries {
-		var m map[string]interface{}
-		json.Unmarshal([]byte(entry), &m)
-		history = append(history, m)
+		var snap replay.Snapshot
+		if err := json.Unmarshal([]byte(entry), &snap); err != nil {
+			requestLogger(c).Warn("Skipping malformed snapshot", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		history = append(history, snap)
 	}
-	logger.Info("resources history for id", zap.String("id", id), zap.Int("entries", len(history)))
+	requestLogger(c).Info("resources history for id", zap.String("id", id), zap.Int("entries", len(history)))
 	c.JSON(http.StatusOK, history)
 }
 
+// snapshotHistoryLen caps the snapshots kept per resource in Redis.
+const snapshotHistoryLen = 1000
+
+// snapshotKey is the Redis list holding the snapshots of resource id, scoped
+// to tenant t so that tenants cannot read each other's history.
+func snapshotKey(t, id string) string {
+	return tenant.Key(t, "resource:"+id+":history")
+}
+
+// SaveSnapshot appends res, as of t, to its snapshot list in Redis, which
+// backs /api/v1/resources/:id/history.
+func SaveSnapshot(ctx context.Context, t time.Time, res models.CloudResource) error {
+	snap, err := replay.NewSnapshot(t, res)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	key := snapshotKey(tenant.Of(res), res.GetId())
+	pipe := GetRedisClient().TxPipeline()
+	pipe.RPush(ctx, key, b)
+	pipe.LTrim(ctx, key, -snapshotHistoryLen, -1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
 func GetRedisClient() *redis.Client {
 	if redisClient == nil {
 		setupRedis()
@@ -123,7 +167,38 @@ func GetRedisClient() *redis.Client {
 	return redisClient
 }
 
-func StartAPIServer(ctx context.Context, sharedResources *[]models.CloudResource, suggestionSink analyzer.SuggestionSink, suggestionSinkType string) *http.Server {
+// newRouter registers every route behind the audit, authentication and tenant
+// middleware.
+func newRouter() *gin.Engine {
+	r := gin.Default()
+	r.Use(auditTrail, authenticate, scopeTenant)
+
+	r.GET("/api/v1/resources", getAllResources)
+	r.GET("/api/v1/resources/:id", getResourceByID)
+	r.GET("/api/v1/resources/:id/history", getResourceHistory)
+	r.GET("/api/v1/resources/:id/graph", getResourceGraph)
+	r.GET("/api/v1/suggestions", getSuggestions)
+	r.POST("/api/v1/suggestions/clear", clearSuggestions)
+	r.GET("/api/v1/status", getStatus)
+	r.POST("/api/v1/backtest", runBacktest)
+	r.GET("/api/v1/forecast", getForecast)
+	r.GET("/api/v1/budgets", listBudgets)
+	r.POST("/api/v1/budgets", createBudget)
+	r.GET("/api/v1/budgets/:id", getBudget)
+	r.PUT("/api/v1/budgets/:id", updateBudget)
+	r.DELETE("/api/v1/budgets/:id", deleteBudget)
+	r.GET("/api/v1/reports/chargeback", getChargeback)
+	r.GET("/api/v1/savings", listFindings)
+	r.POST("/api/v1/savings/:id/done", markFindingDone)
+	r.GET("/api/v1/savings/ledger", getSavingsLedger)
+	r.GET("/api/v1/commitments", getCommitments)
+	r.GET("/api/v1/anomalies", getAnomalies)
+	r.POST("/api/v1/admin/snapshot", requireAdmin, takeSnapshot)
+	r.GET("/api/v1/admin/audit", requireAdmin, getAuditLog)
+	return r
+}
+
+func StartAPIServer(ctx context.Context, sharedResources *[]models.CloudResource, suggestionSink *analyzer.TenantSinks, suggestionSinkType string) *http.Server {
 	LoadAPIConfig()
 	resources = *sharedResources	
 	setupLogger()
@@ -133,14 +208,7 @@ func StartAPIServer(ctx context.Context, sharedResources *[]models.CloudResource
 	
 	SetSuggestionSink(suggestionSink, suggestionSinkType)
 
-	r := gin.Default()
-	
-	r.GET("/api/v1/resources", getAllResources)
-	r.GET("/api/v1/resources/:id", getResourceByID)
-	r.GET("/api/v1/resources/:id/history", getResourceHistory)
-	r.GET("/api/v1/suggestions", getSuggestions)
-	r.POST("/api/v1/suggestions/clear", clearSuggestions)
-	r.GET("/api/v1/status", getStatus)
+	r := newRouter()
 
 	httpServer := &http.Server{
         Addr:    ":8080",
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/audit"
+	"github.com/chanducheryala/cloud-resource/internal/auth"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var authenticator *auth.Authenticator
+
+// SetAuthenticator makes every API request authenticate with a. Without one,
+// requests act as auth.Anonymous.
+func SetAuthenticator(a *auth.Authenticator) {
+	authenticator = a
+}
+
+const identityKey = "identity"
+
+// authenticate attaches the caller's identity to the request, both to the gin
+// context and to the request's context.Context, or rejects the request.
+func authenticate(c *gin.Context) {
+	id := auth.Anonymous
+	if authenticator != nil {
+		var err error
+		id, err = authenticator.Authenticate(c.Request)
+		if errors.Is(err, auth.ErrUnavailable) {
+			logger.Error("Authentication unavailable", zap.String("path", c.Request.URL.Path), zap.Error(err))
+			c.Error(err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": auth.ErrUnavailable.Error()})
+			return
+		}
+		if err != nil {
+			logger.Warn("Authentication failed", zap.String("path", c.Request.URL.Path), zap.String("remote", c.ClientIP()), zap.Error(err))
+			c.Error(err)
+			c.Header("WWW-Authenticate", `Bearer realm="cloud-resource"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.Set(identityKey, id)
+	c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), id))
+	c.Next()
+}
+
+// identityOf returns the identity authenticate attached to c.
+func identityOf(c *gin.Context) auth.Identity {
+	if id, ok := auth.FromContext(c.Request.Context()); ok {
+		return id
+	}
+	return auth.Identity{}
+}
+
+// requireAdmin restricts a route to admin callers.
+func requireAdmin(c *gin.Context) {
+	if !identityOf(c).Admin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+	c.Next()
+}
+
+// requestLogger returns the logger annotated with the caller and tenant of c.
+func requestLogger(c *gin.Context) *zap.Logger {
+	id := identityOf(c)
+	return logger.With(zap.String("caller", id.Subject), zap.String("auth", id.Method), zap.String("tenant", tenantOf(c)))
+}
+
+// auditTrail records every request that changes state, and every request
+// refused for its credentials or tenant, once it has been handled.
+func auditTrail(c *gin.Context) {
+	c.Next()
+	status := c.Writer.Status()
+	if (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) &&
+		status != http.StatusUnauthorized && status != http.StatusForbidden {
+		return
+	}
+	id := identityOf(c)
+	e := audit.Entry{
+		Time:       time.Now().UTC(),
+		Subject:    id.Subject,
+		AuthMethod: id.Method,
+		Tenant:     c.GetString(tenantKey),
+		Action:     c.Request.Method + " " + c.FullPath(),
+		Path:       c.Request.URL.Path,
+		Remote:     c.ClientIP(),
+		Status:     status,
+	}
+	if e.Tenant == "" {
+		e.Tenant = c.GetHeader(tenantHeader)
+	}
+	if err := c.Errors.Last(); err != nil {
+		e.Error = err.Error()
+	}
+	audit.Default().Record(e)
+	logger.Info("audit", zap.String("caller", e.Subject), zap.String("auth", e.AuthMethod), zap.String("tenant", e.Tenant),
+		zap.String("action", e.Action), zap.String("path", e.Path), zap.String("remote", e.Remote), zap.Int("status", e.Status), zap.String("error", e.Error))
+}
+
+// getAuditLog returns the audit trail, optionally only that of ?tenant=.
+func getAuditLog(c *gin.Context) {
+	c.JSON(http.StatusOK, audit.Default().Entries(c.Query("tenant")))
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/audit"
+)
+
+func TestAuthentication(t *testing.T) {
+	r := newTestServer(t)
+	for _, key := range []string{"", "wrong-key"} {
+		w := call(r, "GET", "/api/v1/suggestions", key)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("key %q: status %d, WWW-Authenticate %q, want 401 with a challenge", key, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+	if w := call(r, "GET", "/api/v1/suggestions", analyticsKey); w.Code != http.StatusOK {
+		t.Errorf("valid key: status %d", w.Code)
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	r := newTestServer(t)
+	for _, route := range [][2]string{{"POST", "/api/v1/admin/snapshot"}, {"GET", "/api/v1/admin/audit"}} {
+		if w := call(r, route[0], route[1], analyticsKey); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as a tenant: status %d, want 403", route[0], route[1], w.Code)
+		}
+	}
+	if w := call(r, "GET", "/api/v1/admin/audit", adminKey); w.Code != http.StatusOK {
+		t.Errorf("audit as admin: status %d", w.Code)
+	}
+}
+
+func TestAuditTrail(t *testing.T) {
+	r := newTestServer(t)
+	before := len(audit.Default().Entries(""))
+	call(r, "GET", "/api/v1/suggestions", analyticsKey)
+	call(r, "GET", "/api/v1/suggestions", "wrong-key")
+	call(r, "GET", "/api/v1/suggestions", analyticsKey, tenantHeader, "web")
+	call(r, "POST", "/api/v1/suggestions/clear", analyticsKey)
+
+	entries := audit.Default().Entries("")[before:]
+	want := []struct {
+		action string
+		status int
+	}{
+		{"GET /api/v1/suggestions", http.StatusUnauthorized},
+		{"GET /api/v1/suggestions", http.StatusForbidden},
+		{"POST /api/v1/suggestions/clear", http.StatusOK},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i].Action != w.action || entries[i].Status != w.status {
+			t.Errorf("entry %d = %s %d, want %s %d", i, entries[i].Action, entries[i].Status, w.action, w.status)
+		}
+	}
+	if e := entries[2]; e.Subject != "analytics-ci" || e.Tenant != "analytics" {
+		t.Errorf("clear audited as %q for tenant %q", e.Subject, e.Tenant)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/replay"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// backtestRequest names a recording in the tenant's recordings directory and
+// the candidate thresholds; rules only needs the keys that differ from the
+// defaults.
+type backtestRequest struct {
+	Recording string          `json:"recording"`
+	Rules     json.RawMessage `json:"rules"`
+}
+
+// tenantRecordings is the directory holding the request's tenant's
+// recordings: RECORDINGS_DIR itself for tenant.Default, so that single-tenant
+// setups keep working, and RECORDINGS_DIR/<tenant> otherwise.
+func tenantRecordings(c *gin.Context) string {
+	if t := tenantOf(c); t != tenant.Default {
+		return filepath.Join(recordingsDir, t)
+	}
+	return recordingsDir
+}
+
+func runBacktest(c *gin.Context) {
+	var req backtestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Recording == "" || req.Recording == "." || req.Recording == ".." || filepath.Base(req.Recording) != req.Recording {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recording must be a file name inside the recordings directory"})
+		return
+	}
+
+	rules := analyzer.DefaultRules()
+	if len(req.Rules) > 0 {
+		if err := json.Unmarshal(req.Rules, &rules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rules: " + err.Error()})
+			return
+		}
+	}
+
+	path := filepath.Join(tenantRecordings(c), req.Recording)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			requestLogger(c).Error("Backtest failed", zap.String("recording", req.Recording), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "backtest failed"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "recording not found"})
+		return
+	}
+
+	report, err := replay.Backtest(c.Request.Context(), path, rules)
+	if errors.Is(err, os.ErrNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "recording not found"})
+		return
+	}
+	if err != nil {
+		// The error can carry file paths and parser details; keep them in the log.
+		requestLogger(c).Error("Backtest failed", zap.String("recording", req.Recording), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "backtest failed"})
+		return
+	}
+	requestLogger(c).Info("backtest", zap.String("recording", req.Recording), zap.Int("snapshots", report.Snapshots))
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBacktestRecordingValidation(t *testing.T) {
+	r := newTestServer(t)
+	dir := t.TempDir()
+	defer func(old string) { recordingsDir = old }(recordingsDir)
+	recordingsDir = dir
+	if err := os.MkdirAll(filepath.Join(dir, "analytics", "subdir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "analytics", "broken.jsonl"), []byte("not json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		recording string
+		want      int
+	}{
+		{"", http.StatusBadRequest},
+		{".", http.StatusBadRequest},
+		{"..", http.StatusBadRequest},
+		{"../web/run.jsonl", http.StatusBadRequest},
+		{"subdir", http.StatusNotFound},
+		{"missing.jsonl", http.StatusNotFound},
+		{"broken.jsonl", http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/api/v1/backtest", strings.NewReader(`{"recording":"`+c.recording+`"}`))
+		req.Header.Set("X-API-Key", analyticsKey)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("recording %q: status %d, want %d (%s)", c.recording, w.Code, c.want, w.Body)
+		}
+		if w.Code == http.StatusInternalServerError && strings.Contains(w.Body.String(), dir) {
+			t.Errorf("recording %q: 500 body leaks the path: %s", c.recording, w.Body)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/chanducheryala/cloud-resource/internal/budget"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// tenantBudget returns the budget with id if it belongs to the request's
+// tenant, writing a 404 response otherwise.
+func tenantBudget(c *gin.Context, id string) (budget.Budget, bool) {
+	b, err := budget.Default().Get(id)
+	if err == nil && tenant.Or(b.Tenant) != tenantOf(c) {
+		err = budget.ErrNotFound
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return b, false
+	}
+	return b, true
+}
+
+// listBudgets returns the tenant's budgets with their month-to-date and
+// forecasted spend.
+func listBudgets(c *gin.Context) {
+	res, now := currentResources(c), sim.Now()
+	statuses := []budget.Status{}
+	for _, b := range budget.Default().List() {
+		if tenant.Or(b.Tenant) != tenantOf(c) {
+			continue
+		}
+		statuses = append(statuses, budget.Evaluate(b, res, history.Default(), now))
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+func getBudget(c *gin.Context) {
+	b, ok := tenantBudget(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, budget.Evaluate(b, currentResources(c), history.Default(), sim.Now()))
+}
+
+func createBudget(c *gin.Context) {
+	var b budget.Budget
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	b.Tenant = tenantOf(c)
+	created, err := budget.Default().Create(b, sim.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requestLogger(c).Info("budget created", zap.String("id", created.ID), zap.String("name", created.Name))
+	c.JSON(http.StatusCreated, created)
+}
+
+func updateBudget(c *gin.Context) {
+	var b budget.Budget
+	if err := c.ShouldBindJSON(&b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := tenantBudget(c, c.Param("id")); !ok {
+		return
+	}
+	b.Tenant = tenantOf(c)
+	updated, err := budget.Default().Update(c.Param("id"), b, sim.Now())
+	if errors.Is(err, budget.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requestLogger(c).Info("budget updated", zap.String("id", updated.ID))
+	c.JSON(http.StatusOK, updated)
+}
+
+func deleteBudget(c *gin.Context) {
+	if _, ok := tenantBudget(c, c.Param("id")); !ok {
+		return
+	}
+	if err := budget.Default().Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	requestLogger(c).Info("budget deleted", zap.String("id", c.Param("id")))
+	c.JSON(http.StatusOK, gin.H{"message": "Budget deleted"})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/chargeback"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getChargeback reports per-owner spend for a period.
+//
+//	GET /api/v1/reports/chargeback?month=2025-01&format=csv
+//	GET /api/v1/reports/chargeback?start=2025-01-01T00:00:00Z&end=2025-01-08T00:00:00Z&currency=EUR
+//	GET /api/v1/reports/chargeback?tag=environment=production
+//
+// Without a period it covers the current month to date. Amounts are converted
+// at the exchange rate in effect at the end of the period. A period starting
+// before the history's retention is rejected; covered_from tells where the
+// recorded history begins when it starts after the period.
+func getChargeback(c *gin.Context) {
+	code, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	now := sim.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := now
+	if m := c.Query("month"); m != "" {
+		t, err := time.Parse("2006-01", m)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month must look like 2025-01"})
+			return
+		}
+		start, end = t, t.AddDate(0, 1, 0)
+		if end.After(now) {
+			end = now
+		}
+	}
+	if s := c.Query("start"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start must be RFC 3339"})
+			return
+		}
+		start = t
+	}
+	if e := c.Query("end"); e != "" {
+		t, err := time.Parse(time.RFC3339, e)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end must be RFC 3339"})
+			return
+		}
+		end = t
+	}
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+		return
+	}
+	if retained := now.Add(-history.DefaultRetention); start.Before(retained) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start must not be before " + retained.Format(time.RFC3339) + ": older cost history is not retained"})
+		return
+	}
+
+	res, ok := filteredResources(c)
+	if !ok {
+		return
+	}
+	report := chargeback.Build(res, history.Default(), chargeback.CurrentSplits(), start, end)
+	factor, err := usdFactor(code, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	report.Scale(factor, code)
+	requestLogger(c).Info("chargeback report", zap.Time("start", start), zap.Time("end", end), zap.Time("covered_from", report.CoveredFrom), zap.Int("owners", len(report.Owners)))
+	if report.CoveredFrom.After(start) {
+		requestLogger(c).Warn("Chargeback period starts before the recorded cost history", zap.Time("start", start), zap.Time("covered_from", report.CoveredFrom))
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", `attachment; filename="chargeback-`+start.Format("20060102")+`-`+end.Format("20060102")+`.csv"`)
+		c.Status(http.StatusOK)
+		if err := report.WriteCSV(c.Writer); err != nil {
+			requestLogger(c).Error("Failed to write chargeback CSV", zap.Error(err))
+		}
+	case "json":
+		c.JSON(http.StatusOK, report)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/commitment"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getCommitments recommends reserved instance and savings plan purchases.
+//
+//	GET /api/v1/commitments?lookback_days=14&percentile=10&currency=EUR
+func getCommitments(c *gin.Context) {
+	code, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	opts := commitment.Options{Now: sim.Now()}
+	if v := c.Query("lookback_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lookback_days must be a positive integer"})
+			return
+		}
+		opts.Lookback = time.Duration(days) * 24 * time.Hour
+	}
+	if v := c.Query("percentile"); v != "" {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p <= 0 || p > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "percentile must be between 0 and 100"})
+			return
+		}
+		opts.Percentile = p
+	}
+
+	res, ok := filteredResources(c)
+	if !ok {
+		return
+	}
+	plan := commitment.Build(res, history.Default(), analyzer.CurrentCatalog(), commitment.CurrentOffers(), opts)
+	factor, err := usdFactor(code, opts.Now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	plan.Scale(factor, code)
+	requestLogger(c).Info("commitment plan", zap.Int("reserved", len(plan.Reserved)), zap.Int("lookback_hours", plan.LookbackHours))
+	c.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/currency"
+	"github.com/gin-gonic/gin"
+)
+
+// requestedCurrency returns the upper-cased ?currency= code, or USD when it is
+// absent. For currencies without exchange rates it responds 400 and returns
+// false.
+func requestedCurrency(c *gin.Context) (string, bool) {
+	code := strings.ToUpper(c.DefaultQuery("currency", currency.USD))
+	if !currency.CurrentTable().Supports(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no exchange rate for currency " + code})
+		return "", false
+	}
+	return code, true
+}
+
+// usdFactor is the multiplier from USD into code at at.
+func usdFactor(code string, at time.Time) (float64, error) {
+	return currency.CurrentTable().Factor(code, at)
+}
+
+// convertedSuggestion carries the estimated saving in the requested currency,
+// converted at the rate in effect when the suggestion was raised.
+type convertedSuggestion struct {
+	analyzer.Suggestion
+	EstimatedSavings currency.Money `json:"estimated_savings"`
+}
+
+func convertSuggestions(suggestions []analyzer.Suggestion, code string) ([]convertedSuggestion, error) {
+	out := make([]convertedSuggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		m, err := currency.CurrentTable().Convert(currency.Money{Amount: s.EstimatedSavingsUSD, Currency: currency.USD}, code, s.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, convertedSuggestion{Suggestion: s, EstimatedSavings: m})
+	}
+	return out, nil
+}
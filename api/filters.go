@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// tagFilter parses repeated ?tag=key=value (or ?tag=key, for "has the tag")
+// parameters. It writes a 400 response and returns false if one is malformed.
+func tagFilter(c *gin.Context) (models.TagFilter, bool) {
+	params := c.QueryArray("tag")
+	if len(params) == 0 {
+		return nil, true
+	}
+	f := make(models.TagFilter, len(params))
+	for _, p := range params {
+		k, v, _ := strings.Cut(p, "=")
+		k = strings.TrimSpace(k)
+		if k == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tag must look like key=value or key"})
+			return nil, false
+		}
+		f[k] = strings.TrimSpace(v)
+	}
+	return f, true
+}
+
+// providers are the values ?provider= accepts.
+var providers = map[string]bool{models.ProviderAWS: true, models.ProviderGCP: true, models.ProviderAzure: true}
+
+// filteredResources returns the tenant's resources matching the request's
+// ?provider=, ?account=, ?type=, ?owner=, ?region= and ?tag= parameters.
+// Each may be left out.
+func filteredResources(c *gin.Context) ([]models.CloudResource, bool) {
+	tags, ok := tagFilter(c)
+	if !ok {
+		return nil, false
+	}
+	provider := c.Query("provider")
+	if provider != "" && !providers[provider] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider must be aws, gcp or azure"})
+		return nil, false
+	}
+	account, typ, owner, region := c.Query("account"), c.Query("type"), c.Query("owner"), c.Query("region")
+	res := currentResources(c)
+	matched := make([]models.CloudResource, 0, len(res))
+	for _, r := range res {
+		if (provider == "" || r.GetProvider() == provider) &&
+			(account == "" || r.GetAccount() == account) &&
+			(typ == "" || r.GetType() == typ) &&
+			(owner == "" || r.GetOwner() == owner) &&
+			(region == "" || r.GetRegion() == region) &&
+			tags.Matches(r) {
+			matched = append(matched, r)
+		}
+	}
+	return matched, true
+}
+
+// hasResourceFilter reports whether the request filters resources at all.
+func hasResourceFilter(c *gin.Context) bool {
+	for _, k := range []string{"provider", "account", "type", "owner", "region", "tag"} {
+		if _, ok := c.GetQuery(k); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxHorizonDays keeps forecasts within the span of history they can be
+// fitted on.
+var maxHorizonDays = int(history.DefaultRetention / (24 * time.Hour))
+
+// getForecast projects spend from the recorded cost history.
+//
+//	GET /api/v1/forecast?group_by=resource|owner|type|provider&horizon=30,90&model=linear,holt_winters&currency=EUR&tag=cost-center=CC-1001
+func getForecast(c *gin.Context) {
+	code, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	opts := forecast.Options{Now: sim.Now()}
+	if h := c.Query("horizon"); h != "" {
+		for _, part := range strings.Split(h, ",") {
+			days, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || days <= 0 || days > maxHorizonDays {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "horizon must be a comma-separated list of days between 1 and " + strconv.Itoa(maxHorizonDays)})
+				return
+			}
+			opts.HorizonsDays = append(opts.HorizonsDays, days)
+		}
+	}
+	if m := c.Query("model"); m != "" {
+		for _, part := range strings.Split(m, ",") {
+			model := strings.TrimSpace(part)
+			if model != forecast.Linear && model != forecast.HoltWintersName {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "model must be linear or holt_winters"})
+				return
+			}
+			opts.Models = append(opts.Models, model)
+		}
+	}
+
+	res, ok := filteredResources(c)
+	if !ok {
+		return
+	}
+	subjects := make([]forecast.Subject, 0, len(res))
+	for _, r := range res {
+		subjects = append(subjects, forecast.Subject{ID: r.GetId(), Type: r.GetType(), Owner: r.GetOwner(), Provider: r.GetProvider()})
+	}
+
+	groupBy := c.DefaultQuery("group_by", forecast.ByResource)
+	forecasts, err := forecast.Build(history.Default(), subjects, groupBy, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	factor, err := usdFactor(code, opts.Now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range forecasts {
+		forecasts[i].Scale(factor)
+	}
+	requestLogger(c).Info("forecast", zap.String("group_by", groupBy), zap.Int("groups", len(forecasts)))
+	c.JSON(http.StatusOK, gin.H{
+		"generated_at": opts.Now,
+		"group_by":     groupBy,
+		"currency":     code,
+		"forecasts":    forecasts,
+	})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/gin-gonic/gin"
+)
+
+// maxGraphDepth bounds ?depth= so one request cannot walk the whole inventory
+// of a large account.
+const maxGraphDepth = 5
+
+// resourceGraph is a resource's neighborhood plus what it refers to, what
+// refers to it, and what removing it would affect.
+type resourceGraph struct {
+	graph.View
+	DependsOn   []graph.Edge   `json:"depends_on"`
+	Dependents  []graph.Edge   `json:"dependents"`
+	BlastRadius []graph.Impact `json:"blast_radius"`
+	Orphaned    bool           `json:"orphaned"`
+}
+
+// getResourceGraph returns the resources within ?depth= hops (default 1) of
+// the resource.
+//
+//	GET /api/v1/resources/vm-1/graph?depth=2
+func getResourceGraph(c *gin.Context) {
+	depth := 1
+	if v := c.Query("depth"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > maxGraphDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be an integer from 1 to " + strconv.Itoa(maxGraphDepth)})
+			return
+		}
+		depth = d
+	}
+	id := c.Param("id")
+	g := graph.Build(currentResources(c))
+	view, ok := g.Neighborhood(id, depth)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "resource not found"})
+		return
+	}
+	c.JSON(http.StatusOK, resourceGraph{
+		View:        view,
+		DependsOn:   append([]graph.Edge{}, g.Outgoing(id)...),
+		Dependents:  append([]graph.Edge{}, g.Incoming(id)...),
+		BlastRadius: append([]graph.Impact{}, g.BlastRadius(id)...),
+		Orphaned:    g.Orphaned(id),
+	})
+}
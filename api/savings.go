@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// listFindings returns the tenant's tracked suggestions, optionally only those
+// with ?status=.
+func listFindings(c *gin.Context) {
+	status := c.Query("status")
+	findings := []savings.Finding{}
+	for _, f := range savings.Default().Findings() {
+		if tenant.Or(f.Tenant) == tenantOf(c) && (status == "" || f.Status == status) {
+			findings = append(findings, f)
+		}
+	}
+	c.JSON(http.StatusOK, findings)
+}
+
+// markFindingDone lets an owner report that a suggestion was acted on; its
+// realized savings are measured once the comparison window has passed.
+func markFindingDone(c *gin.Context) {
+	if !tenantFinding(c, c.Param("id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": savings.ErrNotFound.Error()})
+		return
+	}
+	f, err := savings.Default().MarkDone(c.Param("id"), sim.Now())
+	if errors.Is(err, savings.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	requestLogger(c).Info("finding marked done", zap.String("id", f.ID), zap.String("resource", f.ResourceID))
+	c.JSON(http.StatusOK, f)
+}
+
+// tenantFinding reports whether finding id belongs to the request's tenant.
+func tenantFinding(c *gin.Context, id string) bool {
+	for _, f := range savings.Default().Findings() {
+		if f.ID == id {
+			return tenant.Or(f.Tenant) == tenantOf(c)
+		}
+	}
+	return false
+}
+
+// getSavingsLedger aggregates estimated and realized savings by owner, rule and
+// month, filtered by the ?owner=, ?rule= and ?month= parameters. With
+// ?currency= each month is converted at the rate in effect at its end.
+func getSavingsLedger(c *gin.Context) {
+	code, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	filter := savings.LedgerFilter{Tenant: tenantOf(c), Owner: c.Query("owner"), Rule: c.Query("rule"), Month: c.Query("month")}
+	ledger := savings.Default().Ledger(filter)
+	now := sim.Now()
+	err := ledger.Convert(func(month string) (float64, error) {
+		at := now
+		if t, err := time.Parse("2006-01", month); err == nil && t.AddDate(0, 1, 0).Before(now) {
+			at = t.AddDate(0, 1, 0).Add(-time.Nanosecond)
+		}
+		return usdFactor(code, at)
+	}, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ledger)
+}
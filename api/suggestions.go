@@ -2,14 +2,15 @@ package api
 
 import (
 	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/models"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-var suggestionSink analyzer.SuggestionSink
+var suggestionSink *analyzer.TenantSinks
 var suggestionSinkType string
 
-func SetSuggestionSink(sink analyzer.SuggestionSink, sinkType string) {
+func SetSuggestionSink(sink *analyzer.TenantSinks, sinkType string) {
 	suggestionSink = sink
 	suggestionSinkType = sinkType
 }
@@ -19,20 +20,57 @@ func getSuggestions(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "suggestion sink not configured"})
 		return
 	}
-	suggestions := suggestionSink.GetSuggestions()
+	suggestions := suggestionSink.For(tenantOf(c)).GetSuggestions()
+	if hasResourceFilter(c) {
+		res, ok := filteredResources(c)
+		if !ok {
+			return
+		}
+		suggestions = suggestionsFor(suggestions, res)
+	}
+	if _, ok := c.GetQuery("currency"); ok {
+		code, ok := requestedCurrency(c)
+		if !ok {
+			return
+		}
+		converted, err := convertSuggestions(suggestions, code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, converted)
+		return
+	}
 	c.JSON(http.StatusOK, suggestions)
 }
 
+// suggestionsFor keeps the suggestions about one of res.
+func suggestionsFor(suggestions []analyzer.Suggestion, res []models.CloudResource) []analyzer.Suggestion {
+	matched := make(map[string]bool, len(res))
+	for _, r := range res {
+		matched[r.GetId()] = true
+	}
+	out := []analyzer.Suggestion{}
+	for _, s := range suggestions {
+		if matched[s.ResourceID] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// clearSuggestions clears the suggestions of the request's tenant only.
 func clearSuggestions(c *gin.Context) {
 	if suggestionSink == nil {
 		c.JSON(500, gin.H{"error": "suggestion sink not configured"})
 		return
 	}
-	err := suggestionSink.ClearSuggestions()
+	err := suggestionSink.For(tenantOf(c)).ClearSuggestions()
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	requestLogger(c).Info("suggestions cleared")
 	c.JSON(200, gin.H{"message": "Suggestions cleared"})
 }
 
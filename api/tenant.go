@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// tenantHeader names the tenant a request acts for; without it the request
+// acts for its caller's tenant.
+const tenantHeader = "X-Tenant-ID"
+
+const tenantKey = "tenant"
+
+// scopeTenant resolves the request's tenant, so that every handler only sees
+// that tenant's data. Callers act for their own tenant; only admins may name
+// another in the header. Tenants that are not configured are rejected.
+func scopeTenant(c *gin.Context) {
+	id := identityOf(c)
+	t := tenant.Or(id.Tenant)
+	if h := c.GetHeader(tenantHeader); h != "" && h != t {
+		if !id.Admin {
+			c.Error(errors.New(id.Subject + " may not act for tenant " + h))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not allowed to act for tenant " + h})
+			return
+		}
+		t = h
+	}
+	if !tenant.CurrentConfig().Known(t) {
+		c.Error(errors.New("unknown tenant " + t))
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unknown tenant"})
+		return
+	}
+	c.Set(tenantKey, t)
+	c.Next()
+}
+
+// tenantOf returns the tenant scopeTenant resolved for c.
+func tenantOf(c *gin.Context) string {
+	return tenant.Or(c.GetString(tenantKey))
+}
+
+// currentResources returns the resources of the request's tenant.
+func currentResources(c *gin.Context) []models.CloudResource {
+	resourceMutex.RLock()
+	defer resourceMutex.RUnlock()
+	return tenant.Filter(resources, tenantOf(c))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/auth"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// API keys of the test server's callers.
+const (
+	analyticsKey = "analytics-key"
+	webKey       = "web-key"
+	ghostKey     = "ghost-key"
+	adminKey     = "admin-key"
+)
+
+// newTestServer returns the API's router with two tenants, analytics and web,
+// each owning one VM, one suggestion and one open finding.
+func newTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger = zap.NewNop()
+
+	cfg, err := tenant.Parse([]byte("tenants:\n  analytics: ['111111111111']\n  web: ['222222222222']\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.SetConfig(cfg)
+	t.Cleanup(func() { tenant.SetConfig(tenant.Config{}) })
+
+	a, err := auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "analytics-ci", SHA256: auth.HashKey(analyticsKey), Tenant: "analytics"},
+		{Name: "web-ci", SHA256: auth.HashKey(webKey), Tenant: "web"},
+		{Name: "ghost-ci", SHA256: auth.HashKey(ghostKey), Tenant: "ghost"},
+		{Name: "ops", SHA256: auth.HashKey(adminKey), Admin: true},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetAuthenticator(a)
+	t.Cleanup(func() { SetAuthenticator(nil) })
+
+	resources = []models.CloudResource{
+		&models.VM{ID: "vm-analytics", Account: "111111111111"},
+		&models.VM{ID: "vm-web", Account: "222222222222"},
+	}
+	sinks := &analyzer.TenantSinks{New: func(string) analyzer.SuggestionSink { return &analyzer.InMemorySuggestionSink{} }}
+	SetSuggestionSink(sinks, "memory")
+	savings.Default().Restore(nil)
+	t.Cleanup(func() { savings.Default().Restore(nil) })
+	for _, tn := range []string{"analytics", "web"} {
+		s := analyzer.Suggestion{Tenant: tn, ResourceID: "vm-" + tn, ResourceType: "VM", Action: "Terminate", EstimatedSavingsUSD: 10}
+		sinks.AddSuggestion(s)
+		savings.Default().Observe(s)
+	}
+	return newRouter()
+}
+
+// call sends a request with the given API key (none when empty) and headers.
+func call(r *gin.Engine, method, path, key string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantHeader(t *testing.T) {
+	r := newTestServer(t)
+	cases := []struct {
+		name   string
+		key    string
+		header string
+		want   int
+	}{
+		{"own tenant", analyticsKey, "analytics", http.StatusOK},
+		{"another tenant", analyticsKey, "web", http.StatusForbidden},
+		{"unconfigured tenant of the caller", ghostKey, "", http.StatusForbidden},
+		{"admin acting for a tenant", adminKey, "web", http.StatusOK},
+		{"admin acting for an unknown tenant", adminKey, "ghost", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		var header []string
+		if c.header != "" {
+			header = []string{tenantHeader, c.header}
+		}
+		if w := call(r, "GET", "/api/v1/suggestions", c.key, header...); w.Code != c.want {
+			t.Errorf("%s: status %d, want %d", c.name, w.Code, c.want)
+		}
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	r := newTestServer(t)
+
+	var resources []map[string]interface{}
+	decode(t, call(r, "GET", "/api/v1/resources", analyticsKey).Body.Bytes(), &resources)
+	if len(resources) != 1 || resources[0]["ID"] != "vm-analytics" {
+		t.Errorf("analytics resources = %v", resources)
+	}
+	if w := call(r, "GET", "/api/v1/resources/vm-web", analyticsKey); w.Code != http.StatusNotFound {
+		t.Errorf("another tenant's resource: status %d, want 404", w.Code)
+	}
+
+	var suggestions []analyzer.Suggestion
+	decode(t, call(r, "GET", "/api/v1/suggestions", analyticsKey).Body.Bytes(), &suggestions)
+	if len(suggestions) != 1 || suggestions[0].ResourceID != "vm-analytics" {
+		t.Errorf("analytics suggestions = %+v", suggestions)
+	}
+
+	var findings []savings.Finding
+	decode(t, call(r, "GET", "/api/v1/savings", analyticsKey).Body.Bytes(), &findings)
+	if len(findings) != 1 || findings[0].ResourceID != "vm-analytics" {
+		t.Fatalf("analytics findings = %+v", findings)
+	}
+	var webFinding string
+	for _, f := range savings.Default().Findings() {
+		if f.Tenant == "web" {
+			webFinding = f.ID
+		}
+	}
+	if w := call(r, "POST", "/api/v1/savings/"+webFinding+"/done", analyticsKey); w.Code != http.StatusNotFound {
+		t.Errorf("marking another tenant's finding done: status %d, want 404", w.Code)
+	}
+	if w := call(r, "POST", "/api/v1/savings/"+findings[0].ID+"/done", analyticsKey); w.Code != http.StatusOK {
+		t.Errorf("marking own finding done: status %d", w.Code)
+	}
+
+	// Clearing only clears the caller's suggestions.
+	if w := call(r, "POST", "/api/v1/suggestions/clear", analyticsKey); w.Code != http.StatusOK {
+		t.Fatalf("clear: status %d", w.Code)
+	}
+	if got := suggestionSink.For("analytics").GetSuggestions(); len(got) != 0 {
+		t.Errorf("analytics suggestions after clear = %+v", got)
+	}
+	if got := suggestionSink.For("web").GetSuggestions(); len(got) != 1 {
+		t.Errorf("web suggestions after analytics cleared = %+v", got)
+	}
+	for _, f := range savings.Default().Findings() {
+		if f.Tenant == "web" && f.Status != savings.Open {
+			t.Errorf("web finding is %s after analytics acted", f.Status)
+		}
+	}
+}
+
+func decode(t *testing.T, b []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("decode %s: %v", b, err)
+	}
+}
@@ -1,7 +1,13 @@
 package analyzer
 
 import (
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/chanducheryala/cloud-resource/internal/history"
 	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -17,6 +23,9 @@ type Suggestion struct {
 	Action              string                 `json:"action"`
 	Details             map[string]interface{} `json:"details,omitempty"`
 	DocsLink            string                 `json:"docs_link,omitempty"`
+	// Tenant owns the resource; sinks that keep tenants apart file the
+	// suggestion under it.
+	Tenant              string                 `json:"tenant,omitempty"`
 }
 
 type SuggestionSink interface {
@@ -31,6 +40,9 @@ type InMemorySuggestionSink struct {
 }
 
 func (s *InMemorySuggestionSink) AddSuggestion(sug Suggestion) {
+	if sug.Timestamp.IsZero() {
+		sug.Timestamp = sim.Now()
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.suggestions = append(s.suggestions, sug)
@@ -42,6 +54,13 @@ func (s *InMemorySuggestionSink) GetSuggestions() []Suggestion {
 	return append([]Suggestion(nil), s.suggestions...)
 }
 
+// SetSuggestions replaces the sink's contents, e.g. when restoring a snapshot.
+func (s *InMemorySuggestionSink) SetSuggestions(suggestions []Suggestion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suggestions = append([]Suggestion{}, suggestions...)
+}
+
 func (s *InMemorySuggestionSink) ClearSuggestions() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -50,522 +69,618 @@ func (s *InMemorySuggestionSink) ClearSuggestions() error {
 }
 
 func AnalyzeResource(usage float64, resource models.CloudResource, sink SuggestionSink) {
-	go func() {
-		switch r := resource.(type) {
-		case *models.Lambda:
-			totalInvocations := r.Invocations
-			errors := r.Errors
-			errorRate := 0.0
-			if totalInvocations > 0 {
-				errorRate = float64(errors) / float64(totalInvocations)
-			}
-			if errorRate > 0.05 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"error_rate": errorRate,
-					"invocations": totalInvocations,
-					"errors": errors,
-				}
-				details["business_impact"] = "High error rates may indicate wasted compute and lost business logic."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "Lambda",
-					Message:      "Lambda '" + r.GetId() + "' has a high error rate (>5%). Investigate and fix failing invocations.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Debug and fix errors",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/invocation-retries.html",
-				})
+	go Analyze(resource, sink)
+}
+
+// Analyze runs every check for resource on the calling goroutine, so suggestions
+// reach the sink in a stable order. Seeded simulations rely on this.
+func Analyze(resource models.CloudResource, sink SuggestionSink) {
+	AnalyzeWith(resource, sink, Env{Rules: CurrentRules(), Now: sim.Now(), History: history.Default()})
+}
+
+// Env is everything a check depends on besides the resource itself.
+type Env struct {
+	Rules Rules
+	// Now is the evaluation time, e.g. the recorded time when replaying.
+	Now time.Time
+	// History receives the resource's usage and cost metrics and backs
+	// rightsizing and forecasting. Nil disables recording and rightsizing.
+	History *history.Store
+	// Graph links resources through their references, such as a volume's VM.
+	// It backs the orphan checks and the blast radius of suggestions that
+	// remove a resource. Nil means references cannot be checked, so only
+	// resources that refer to nothing are treated as orphaned.
+	Graph *graph.Graph
+}
+
+// AnalyzeWith is Analyze with explicit thresholds, evaluation time and usage
+// history, e.g. to replay recorded usage against a candidate configuration at
+// the times it was recorded.
+func AnalyzeWith(resource models.CloudResource, sink SuggestionSink, env Env) {
+	rules, now := env.Rules, env.Now
+	if env.History != nil {
+		env.History.Record(resource.GetId(), now, resource.Metrics())
+	}
+	sink = tenantSink{SuggestionSink: sink, tenant: tenant.Of(resource)}
+	if env.Graph != nil {
+		if impacts := env.Graph.BlastRadius(resource.GetId()); len(impacts) > 0 {
+			sink = blastRadiusSink{SuggestionSink: sink, impacts: impacts}
+		}
+	}
+	checkTags(resource, sink, now)
+	checkOrphaned(resource, sink, env)
+	switch r := resource.(type) {
+	case *models.Lambda:
+		totalInvocations := r.Invocations
+		errors := r.Errors
+		errorRate := 0.0
+		if totalInvocations > 0 {
+			errorRate = float64(errors) / float64(totalInvocations)
+		}
+		if errorRate > rules.LambdaMaxErrorRate {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"error_rate": errorRate,
+				"invocations": totalInvocations,
+				"errors": errors,
 			}
-			if totalInvocations < 100 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"invocations": totalInvocations,
-				}
-				details["business_impact"] = "Idle Lambda functions can be removed to reduce clutter and potential attack surface."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "Lambda",
-					Message:      "Lambda '" + r.GetId() + "' has low invocation rates (<100/month). Consider removing or consolidating idle functions.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:     "Info",
-					Priority:     3,
-					Timestamp:    time.Now(),
-					Action:       "Review for removal",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/best-practices.html",
-				})
+			details["business_impact"] = "High error rates may indicate wasted compute and lost business logic."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "Lambda",
+				Message:      "Lambda '" + r.GetId() + "' has a high error rate (>" + num(rules.LambdaMaxErrorRate*100) + "%). Investigate and fix failing invocations.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Debug and fix errors",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/invocation-retries.html",
+			})
+		}
+		if totalInvocations < rules.LambdaMinInvocations {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"invocations": totalInvocations,
 			}
+			details["business_impact"] = "Idle Lambda functions can be removed to reduce clutter and potential attack surface."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "Lambda",
+				Message:      "Lambda '" + r.GetId() + "' has low invocation rates (<" + strconv.Itoa(rules.LambdaMinInvocations) + "/month). Consider removing or consolidating idle functions.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:     "Info",
+				Priority:     3,
+				Timestamp:    now,
+				Action:       "Review for removal",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/best-practices.html",
+			})
+		}
 
-			if r.CostPerMillion > 0.25 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"cost_per_million": r.CostPerMillion,
-				}
-				details["business_impact"] = "High Lambda costs may indicate inefficient code or configuration."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "Lambda",
-					Message:      "Lambda '" + r.GetId() + "' has a high cost per million invocations (>$0.25). Review function configuration and usage.",
-					EstimatedSavingsUSD: 5.0,
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Optimize configuration",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/configuration-memory.html",
-				})
+		if r.CostPerMillion > rules.LambdaMaxCostPerMillion {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"cost_per_million": r.CostPerMillion,
 			}
+			details["business_impact"] = "High Lambda costs may indicate inefficient code or configuration."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "Lambda",
+				Message:      "Lambda '" + r.GetId() + "' has a high cost per million invocations (>$" + num(rules.LambdaMaxCostPerMillion) + "). Review function configuration and usage.",
+				EstimatedSavingsUSD: 5.0,
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Optimize configuration",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/lambda/latest/dg/configuration-memory.html",
+			})
+		}
 
 
-		case *models.DynamoDB:
-			if r.ReadCapacity > 20 || r.WriteCapacity > 20 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"read_capacity": r.ReadCapacity,
-					"write_capacity": r.WriteCapacity,
-				}
-				details["business_impact"] = "Overprovisioned tables waste money on unused throughput."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "DynamoDB",
-					Message:      "DynamoDB table '" + r.GetId() + "' is overprovisioned (Read/Write Capacity > 20). Consider scaling down provisioned throughput.",
-					EstimatedSavingsUSD: r.CostPerHr * 24 * 30 * 0.5, 
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Scale down provisioned throughput",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ProvisionedThroughput.html",
-				})
+	case *models.DynamoDB:
+		if r.ReadCapacity > rules.DynamoDBMaxCapacity || r.WriteCapacity > rules.DynamoDBMaxCapacity {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"read_capacity": r.ReadCapacity,
+				"write_capacity": r.WriteCapacity,
 			}
-			if r.ItemCount > 1000000 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"item_count": r.ItemCount,
-				}
-				details["business_impact"] = "Large tables may contain stale or unnecessary data, increasing costs."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "DynamoDB",
-					Message:      "DynamoDB table '" + r.GetId() + "' is large (>1 million items). Review for archiving or partitioning.",
-					EstimatedSavingsUSD: r.CostPerHr * 24 * 30 * 0.2, // assume 20% savings possible
-					Severity:     "Info",
-					Priority:     3,
-					Timestamp:    time.Now(),
-					Action:       "Review for archiving/partitioning",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/best-practices.html",
-				})
+			details["business_impact"] = "Overprovisioned tables waste money on unused throughput."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "DynamoDB",
+				Message:      "DynamoDB table '" + r.GetId() + "' is overprovisioned (Read/Write Capacity > " + strconv.Itoa(rules.DynamoDBMaxCapacity) + "). Consider scaling down provisioned throughput.",
+				EstimatedSavingsUSD: r.CostPerHr * 24 * 30 * 0.5, 
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Scale down provisioned throughput",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ProvisionedThroughput.html",
+			})
+		}
+		if r.ItemCount > rules.DynamoDBMaxItems {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"item_count": r.ItemCount,
 			}
+			details["business_impact"] = "Large tables may contain stale or unnecessary data, increasing costs."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "DynamoDB",
+				Message:      "DynamoDB table '" + r.GetId() + "' is large (>" + num(float64(rules.DynamoDBMaxItems)/1e6) + " million items). Review for archiving or partitioning.",
+				EstimatedSavingsUSD: r.CostPerHr * 24 * 30 * 0.2, // assume 20% savings possible
+				Severity:     "Info",
+				Priority:     3,
+				Timestamp:    now,
+				Action:       "Review for archiving/partitioning",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/best-practices.html",
+			})
+		}
 
-			if r.CostPerHr > 0.25 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"cost_per_hr": r.CostPerHr,
-				}
-				details["business_impact"] = "High DynamoDB costs may indicate overprovisioning or inefficient access patterns."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "DynamoDB",
-					Message:      "DynamoDB table '" + r.GetId() + "' has a high cost per hour (>$0.25). Review usage and optimize table settings.",
-					EstimatedSavingsUSD: (r.CostPerHr - 0.10) * 24 * 30,
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Optimize table settings",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/HowItWorks.ReadWriteCapacityMode.html",
-				})
+		if r.CostPerHr > rules.DynamoDBMaxCostPerHr {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"cost_per_hr": r.CostPerHr,
 			}
+			details["business_impact"] = "High DynamoDB costs may indicate overprovisioning or inefficient access patterns."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "DynamoDB",
+				Message:      "DynamoDB table '" + r.GetId() + "' has a high cost per hour (>$" + num(rules.DynamoDBMaxCostPerHr) + "). Review usage and optimize table settings.",
+				EstimatedSavingsUSD: (r.CostPerHr - 0.10) * 24 * 30,
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Optimize table settings",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/HowItWorks.ReadWriteCapacityMode.html",
+			})
+		}
 
 
-		case *models.S3:
-			if r.UsedGB > 1000 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"used_gb": r.UsedGB,
-				}
-				details["business_impact"] = "Large S3 buckets may contain stale or unnecessary data, increasing costs."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "S3",
-					Message:      "S3 Bucket '" + r.GetId() + "' is large (>1000 GB). Review for data lifecycle and retention policies.",
-					EstimatedSavingsUSD: r.UsedGB * r.CostPerGB * 0.2, // assume 20% savings possible
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Review and clean up old data",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
-				})
+	case *models.S3:
+		if r.UsedGB > rules.S3MaxUsedGB {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"used_gb": r.UsedGB,
 			}
-			if r.ObjectCount > 1000000 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"object_count": r.ObjectCount,
-				}
-				details["business_impact"] = "Buckets with too many objects can increase management overhead and costs."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "S3",
-					Message:      "S3 Bucket '" + r.GetId() + "' has more than 1 million objects. Consider consolidation or archiving.",
-					EstimatedSavingsUSD: r.UsedGB * r.CostPerGB * 0.1, // assume 10% savings possible
-					Severity:     "Info",
-					Priority:     3,
-					Timestamp:    time.Now(),
-					Action:       "Consolidate or archive objects",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/optimizing-performance.html",
-				})
+			details["business_impact"] = "Large S3 buckets may contain stale or unnecessary data, increasing costs."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "S3",
+				Message:      "S3 Bucket '" + r.GetId() + "' is large (>" + num(rules.S3MaxUsedGB) + " GB). Review for data lifecycle and retention policies.",
+				EstimatedSavingsUSD: r.UsedGB * r.CostPerGB * 0.2, // assume 20% savings possible
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Review and clean up old data",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+			})
+		}
+		if r.ObjectCount > rules.S3MaxObjects {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"object_count": r.ObjectCount,
 			}
+			details["business_impact"] = "Buckets with too many objects can increase management overhead and costs."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "S3",
+				Message:      "S3 Bucket '" + r.GetId() + "' has more than " + num(float64(rules.S3MaxObjects)/1e6) + " million objects. Consider consolidation or archiving.",
+				EstimatedSavingsUSD: r.UsedGB * r.CostPerGB * 0.1, // assume 10% savings possible
+				Severity:     "Info",
+				Priority:     3,
+				Timestamp:    now,
+				Action:       "Consolidate or archive objects",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/optimizing-performance.html",
+			})
+		}
 
-			if r.CostPerGB > 0.03 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"cost_per_gb": r.CostPerGB,
-				}
-				details["business_impact"] = "High S3 cost per GB may indicate inefficient storage class selection."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "S3",
-					Message:      "S3 Bucket '" + r.GetId() + "' has a high cost per GB (>$0.03). Review storage class and region.",
-					EstimatedSavingsUSD: r.UsedGB * (r.CostPerGB - 0.023),
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Review storage class",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
-				})
+		if r.CostPerGB > rules.S3MaxCostPerGB {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"cost_per_gb": r.CostPerGB,
 			}
+			details["business_impact"] = "High S3 cost per GB may indicate inefficient storage class selection."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "S3",
+				Message:      "S3 Bucket '" + r.GetId() + "' has a high cost per GB (>$" + num(rules.S3MaxCostPerGB) + "). Review storage class and region.",
+				EstimatedSavingsUSD: storageClassSavings(r.CostPerGB, r.UsedGB, "standard", regionOrDefault(r.Region)),
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Review storage class",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+			})
+		}
 
 
-		case *models.ELB:
-			if r.RequestCount < 1000 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"request_count": r.RequestCount,
-				}
-				details["business_impact"] = "Underutilized ELBs incur ongoing costs with minimal value."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "ELB",
-					Message:      "ELB '" + r.GetId() + "' is underutilized (<1000 requests). Consider downsizing or removal.",
-					EstimatedSavingsUSD: r.CostPerHour * 24 * 30,
-					Severity:     "Info",
-					Priority:     3,
-					Timestamp:    time.Now(),
-					Action:       "Review for downsizing/removal",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/load-balancer-troubleshooting.html",
-				})
+	case *models.ELB:
+		if r.RequestCount < rules.ELBMinRequests {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"request_count": r.RequestCount,
 			}
+			details["business_impact"] = "Underutilized ELBs incur ongoing costs with minimal value."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "ELB",
+				Message:      "ELB '" + r.GetId() + "' is underutilized (<" + strconv.Itoa(rules.ELBMinRequests) + " requests). Consider downsizing or removal.",
+				EstimatedSavingsUSD: r.CostPerHour * 24 * 30,
+				Severity:     "Info",
+				Priority:     3,
+				Timestamp:    now,
+				Action:       "Review for downsizing/removal",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/load-balancer-troubleshooting.html",
+			})
+		}
 
-			if r.HealthyHosts < 2 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"healthy_hosts": r.HealthyHosts,
-				}
-				details["business_impact"] = "Unhealthy ELBs may cause downtime or lost revenue."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "ELB",
-					Message:      "ELB '" + r.GetId() + "' has fewer than 2 healthy hosts. Investigate target group health.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Investigate health",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/target-group-health-checks.html",
-				})
-			}
-			costPerRequest := 0.0
-			if r.RequestCount > 0 {
-				costPerRequest = r.CostPerHour / float64(r.RequestCount)
+		if r.HealthyHosts < rules.ELBMinHealthyHosts {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"healthy_hosts": r.HealthyHosts,
 			}
-			if costPerRequest > 0.00005 {
-				details := map[string]interface{}{
-					"owner": r.Owner,
-					"cost_per_request": costPerRequest,
-				}
-				details["business_impact"] = "High ELB cost per request may indicate over-provisioning or low traffic."
-				sink.AddSuggestion(Suggestion{
-					ResourceID:   r.GetId(),
-					ResourceType: "ELB",
-					Message:      "ELB '" + r.GetId() + "' has a high cost per request (>$0.00005). Review configuration and traffic patterns.",
-					EstimatedSavingsUSD: r.CostPerHour * 24 * 30,
-					Severity:     "Warning",
-					Priority:     2,
-					Timestamp:    time.Now(),
-					Action:       "Optimize configuration",
-					Details:      details,
-					DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/load-balancer-cost-optimization.html",
-				})
+			details["business_impact"] = "Unhealthy ELBs may cause downtime or lost revenue."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "ELB",
+				Message:      "ELB '" + r.GetId() + "' has fewer than " + strconv.Itoa(rules.ELBMinHealthyHosts) + " healthy hosts. Investigate target group health.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Investigate health",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/target-group-health-checks.html",
+			})
+		}
+		costPerRequest := 0.0
+		if r.RequestCount > 0 {
+			costPerRequest = r.CostPerHour / float64(r.RequestCount)
+		}
+		if costPerRequest > rules.ELBMaxCostPerRequest {
+			details := map[string]interface{}{
+				"owner": r.Owner,
+				"cost_per_request": costPerRequest,
 			}
+			details["business_impact"] = "High ELB cost per request may indicate over-provisioning or low traffic."
+			sink.AddSuggestion(Suggestion{
+				ResourceID:   r.GetId(),
+				ResourceType: "ELB",
+				Message:      "ELB '" + r.GetId() + "' has a high cost per request (>$" + num(rules.ELBMaxCostPerRequest) + "). Review configuration and traffic patterns.",
+				EstimatedSavingsUSD: r.CostPerHour * 24 * 30,
+				Severity:     "Warning",
+				Priority:     2,
+				Timestamp:    now,
+				Action:       "Optimize configuration",
+				Details:      details,
+				DocsLink:     "https://docs.aws.amazon.com/elasticloadbalancing/latest/userguide/load-balancer-cost-optimization.html",
+			})
+		}
 
 
-		case *models.VM:
-			if r.PreviousCostPerHour > 0 && r.CostPerHour > r.PreviousCostPerHour*1.5 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.GetId(),
-					ResourceType:        "VM",
-					Message:             "Cost spike detected for VM '" + r.GetId() + "'. Hourly cost increased by more than 50%. Investigate recent changes or usage.",
-					EstimatedSavingsUSD: (r.CostPerHour - r.PreviousCostPerHour) * 24 * 30,
-					Severity:            "Critical",
-					Priority:            1,
-					Timestamp:           time.Now(),
-					Action:              "Investigate cost anomaly",
-					Details: map[string]interface{}{
-						"previous_cost_per_hour": r.PreviousCostPerHour,
-						"current_cost_per_hour": r.CostPerHour,
-						"owner": r.Owner,
-						"business_impact": "Sudden cost increase; investigate to prevent unexpected spend.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
-				})
-			}
-			if r.GetUsage() < 10.0 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.GetId(),
-					ResourceType:        "VM",
-					Message:             "VM '" + r.GetId() + "' is underutilized (CPU < 10%) for 14 days. Consider resizing or terminating to eliminate waste.",
-					EstimatedSavingsUSD: 45.00,
-					Severity:            "Critical",
-					Timestamp:           time.Now(),
-					Action:              "Resize or terminate",
-					Details: map[string]interface{}{
-						"region":           "us-east-1",
-						"owner":            r.Owner,
-						"current_type":     "t3.large",
-						"recommended_type": "t3.small",
-						"business_impact":  "No recent activity; freeing this VM will save significant costs.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-resize.html",
-				})
-			}
-			if r.LastActive > 0 && time.Now().Unix()-r.LastActive > 30*24*3600 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.GetId(),
-					ResourceType:        "VM",
-					Message:             "VM '" + r.GetId() + "' has not been active for 30+ days. Consider terminating to eliminate waste.",
-					EstimatedSavingsUSD: r.CostPerHour * 24 * 30, // monthly
-					Severity:            "Critical",
-					Timestamp:           time.Now(),
-					Action:              "Terminate",
-					Details: map[string]interface{}{
-						"owner":           r.Owner,
-						"last_active":     r.LastActive,
-						"business_impact": "Resource idle for over a month; terminating will save $/month.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/stop-start-instance.html",
-				})
-			}
-			if r.GetUsage() > 90.0 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.GetId(),
-					ResourceType:        "VM",
-					Message:             "VM '" + r.GetId() + "' is over-provisioned. Consider rightsizing to reduce spend.",
-					EstimatedSavingsUSD: 60.00,
-					Severity:            "Info",
-					Priority:            3,
-					Timestamp:           time.Now(),
-					Action:              "Resize down",
-					Details: map[string]interface{}{
-						"current_type":     "t3.xlarge",
-						"recommended_type": "t3.large",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-resize.html",
-				})
-			}
-			if r.CostPerHour > 0.5 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.GetId(),
-					ResourceType:        "VM",
-					Message:             "VM '" + r.GetId() + "' has a high hourly cost. Consider moving to a reserved or spot instance.",
-					EstimatedSavingsUSD: 100.00,
-					Severity:            "Warning",
-					Priority:            2,
-					Timestamp:           time.Now(),
-					Action:              "Switch pricing model",
-					Details: map[string]interface{}{
-						"current_type": "expensive-type",
-						"hourly_cost":  r.CostPerHour,
-					},
-					DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-on-demand-reserved-instances.html",
-				})
-			}
-		case *models.Storage:
-			if r.PreviousCostPerGB > 0 && r.CostPerGB > r.PreviousCostPerGB*1.5 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Storage",
-					Message:             "Cost spike detected for Storage '" + r.ID + "'. Cost per GB increased by more than 50%. Investigate recent changes or storage class.",
-					EstimatedSavingsUSD: (r.CostPerGB - r.PreviousCostPerGB) * r.UsedGB,
-					Severity:            "Critical",
-					Priority:            1,
-					Timestamp:           time.Now(),
-					Action:              "Investigate storage cost anomaly",
-					Details: map[string]interface{}{
-						"previous_cost_per_gb": r.PreviousCostPerGB,
-						"current_cost_per_gb": r.CostPerGB,
-						"owner": r.Owner,
-						"business_impact": "Sudden storage cost increase; investigate to prevent unexpected spend.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
-				})
-			}
-			if r.UsedGB < 1.0 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Storage",
-					Message:             "Storage '" + r.ID + "' is idle and can be moved to a lower-cost storage class to eliminate waste.",
-					EstimatedSavingsUSD: 10.00,
-					Severity:            "Warning",
-					Priority:            2,
-					Timestamp:           time.Now(),
-					Action:              "Move to infrequent access tier",
-					Details: map[string]interface{}{
-						"region":        "us-east-1",
-						"storage_class": "standard",
-						"owner":         r.Owner,
-						"business_impact": "Idle storage can be archived or deleted to save costs.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
-				})
-			}
-			if r.UsedGB > 900.0 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Storage",
-					Message:             "Storage '" + r.ID + "' is nearing capacity. Review and clean up unused data to avoid unnecessary expansion costs.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:            "Critical",
-					Timestamp:           time.Now(),
-					Action:              "Cleanup or optimize",
-					Details: map[string]interface{}{
-						"used_gb": r.UsedGB,
-						"owner":   r.Owner,
-						"business_impact": "Storage nearing capacity; cleaning up prevents additional spend.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/quotas.html",
-				})
-			}
-			if time.Now().Unix() - r.LastAccessed > 90 * 24 * 3600 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Storage",
-					Message:             "Storage '" + r.ID + "' has not been accessed for 90+ days. Consider archiving or deleting.",
-					EstimatedSavingsUSD: 20.00,
-					Severity:            "Info",
-					Priority:            3,
-					Timestamp:           time.Now(),
-					Action:              "Archive or delete",
-					Details: map[string]interface{}{
-						"last_accessed": r.LastAccessed,
-						"owner":         r.Owner,
-						"business_impact": "No access in 90+ days; archiving can save costs.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
-				})
-			}
-			if r.CostPerGB > 0.10 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Storage",
-					Message:             "Storage '" + r.ID + "' has a high cost per GB. Consider moving to a lower-cost storage class.",
-					EstimatedSavingsUSD: 15.00,
-					Severity:            "Warning",
-					Priority:            2,
-					Timestamp:           time.Now(),
-					Action:              "Change storage class",
-					Details: map[string]interface{}{
-						"cost_per_gb": r.CostPerGB,
-						"owner":       r.Owner,
-						"business_impact": "High storage cost; move to lower-cost class for efficiency.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
-				})
-			}
-		case *models.Database:
-			if r.PreviousCostPerHr > 0 && r.CostPerHr > r.PreviousCostPerHr*1.5 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Database",
-					Message:             "Cost spike detected for Database '" + r.ID + "'. Hourly cost increased by more than 50%. Investigate recent changes or usage.",
-					EstimatedSavingsUSD: (r.CostPerHr - r.PreviousCostPerHr) * 24 * 30,
-					Severity:            "Critical",
-					Priority:            1,
-					Timestamp:           time.Now(),
-					Action:              "Investigate database cost anomaly",
-					Details: map[string]interface{}{
-						"previous_cost_per_hr": r.PreviousCostPerHr,
-						"current_cost_per_hr": r.CostPerHr,
-						"owner": r.Owner,
-						"business_impact": "Sudden database cost increase; investigate to prevent unexpected spend.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
-				})
+	case *models.VM:
+		if r.PreviousCostPerHour > 0 && r.CostPerHour > r.PreviousCostPerHour*rules.CostSpikeRatio {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "Cost spike detected for VM '" + r.GetId() + "'. Hourly cost increased by more than " + num((rules.CostSpikeRatio-1)*100) + "%. Investigate recent changes or usage.",
+				EstimatedSavingsUSD: (r.CostPerHour - r.PreviousCostPerHour) * 24 * 30,
+				Severity:            "Critical",
+				Priority:            1,
+				Timestamp:           now,
+				Action:              "Investigate cost anomaly",
+				Details: map[string]interface{}{
+					"previous_cost_per_hour": r.PreviousCostPerHour,
+					"current_cost_per_hour": r.CostPerHour,
+					"owner": r.Owner,
+					"business_impact": "Sudden cost increase; investigate to prevent unexpected spend.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
+			})
+		}
+		// Rightsizing runs once: an underused VM gets it in "Resize or
+		// terminate", any other over-provisioned VM in "Resize down".
+		rs, resizable := recommendSize(env, r.ID, "ec2", "", r.InstanceType, r.Region, r.VCPU, r.MemoryGiB)
+		underused := r.GetUsage() < rules.VMMinCPU
+		if underused {
+			details := map[string]interface{}{
+				"region":          r.Region,
+				"owner":           r.Owner,
+				"current_type":    r.InstanceType,
+				"business_impact": "No recent activity; freeing this VM will save significant costs.",
 			}
-			if r.Connections < 5 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Database",
-					Message:             "Database '" + r.ID + "' is over-provisioned. Consider downsizing to reduce waste.",
-					EstimatedSavingsUSD: 25.00,
-					Severity:            "Info",
-					Priority:            3,
-					Timestamp:           time.Now(),
-					Action:              "Downsize instance",
-					Details: map[string]interface{}{
-						"engine":           "Postgres",
-						"current_size":     "db.m5.large",
-						"recommended_size": "db.t3.medium",
-						"owner":            r.Owner,
-						"business_impact":  "Over-provisioned DB; downsizing will reduce waste and save costs.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/Overview.DBInstanceClass.html",
-				})
+			// Without a smaller type that fits, the saving is terminating it.
+			savings := r.CostPerHour * 24 * 30
+			if resizable {
+				savings = rs.MonthlySavings
+				details["recommended_type"] = rs.Recommended.Type
 			}
-			if r.Connections > 150 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Database",
-					Message:             "Database '" + r.ID + "' has a high number of connections. Consider scaling up or load balancing.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:            "Critical",
-					Timestamp:           time.Now(),
-					Action:              "Scale up or load balance",
-					Details: map[string]interface{}{
-						"connections": r.Connections,
-						"owner":       r.Owner,
-						"business_impact": "High connection count; scaling or balancing can prevent outages and improve business continuity.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_WorkingWithConnections.html",
-				})
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "VM '" + r.GetId() + "' is underutilized (CPU < " + num(rules.VMMinCPU) + "%) for 14 days. Consider resizing or terminating to eliminate waste.",
+				EstimatedSavingsUSD: savings,
+				Severity:            "Critical",
+				Timestamp:           now,
+				Action:              "Resize or terminate",
+				Details:             details,
+				DocsLink:            "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-resize.html",
+			})
+		}
+		if r.LastActive > 0 && now.Unix()-r.LastActive > int64(rules.VMIdleDays)*24*3600 {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "VM '" + r.GetId() + "' has not been active for " + strconv.Itoa(rules.VMIdleDays) + "+ days. Consider terminating to eliminate waste.",
+				EstimatedSavingsUSD: r.CostPerHour * 24 * 30, // monthly
+				Severity:            "Critical",
+				Timestamp:           now,
+				Action:              "Terminate",
+				Details: map[string]interface{}{
+					"owner":           r.Owner,
+					"last_active":     r.LastActive,
+					"business_impact": "Resource idle for over a month; terminating will save $/month.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/stop-start-instance.html",
+			})
+		}
+		if resizable && !underused {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "VM '" + r.GetId() + "' is over-provisioned. Its p" + num(rules.RightsizingPercentile) + " usage fits " + rs.Recommended.Type + ". Consider rightsizing to reduce spend.",
+				EstimatedSavingsUSD: rs.MonthlySavings,
+				Severity:            "Info",
+				Priority:            3,
+				Timestamp:           now,
+				Action:              "Resize down",
+				Details:             rs.details(r.Owner),
+				DocsLink:            "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-resize.html",
+			})
+		}
+		if r.CostPerHour > rules.VMMaxCostPerHour {
+			current, reservedSavings := commitmentSavings("ec2", "", r.InstanceType, r.Region, r.CostPerHour, pricing.Reserved)
+			_, spotSavings := commitmentSavings("ec2", "", r.InstanceType, r.Region, r.CostPerHour, pricing.Spot)
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "VM '" + r.GetId() + "' has a high hourly cost. Consider moving to a reserved or spot instance.",
+				EstimatedSavingsUSD: reservedSavings,
+				Severity:            "Warning",
+				Priority:            2,
+				Timestamp:           now,
+				Action:              "Switch pricing model",
+				Details: map[string]interface{}{
+					"current_type":         current.Type,
+					"region":               r.Region,
+					"hourly_cost":          r.CostPerHour,
+					"reserved_savings_usd": reservedSavings,
+					"spot_savings_usd":     spotSavings,
+				},
+				DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-on-demand-reserved-instances.html",
+			})
+		}
+		if sa, ok := assessSpot(env, r); ok && sa.Score >= rules.SpotMinScore && sa.MonthlySavings > 0 {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.GetId(),
+				ResourceType:        "VM",
+				Message:             "VM '" + r.GetId() + "' looks suitable for spot capacity (score " + num(sa.Score) + "/100, " + sa.Outlook.Interruption + " monthly interruptions). Consider moving it to spot instances.",
+				EstimatedSavingsUSD: sa.MonthlySavings,
+				Severity:            "Info",
+				Priority:            3,
+				Timestamp:           now,
+				Action:              "Move to spot",
+				Details:             sa.details(r),
+				DocsLink:            "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/using-spot-instances.html",
+			})
+		}
+	case *models.Storage:
+		if r.PreviousCostPerGB > 0 && r.CostPerGB > r.PreviousCostPerGB*rules.CostSpikeRatio {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Storage",
+				Message:             "Cost spike detected for Storage '" + r.ID + "'. Cost per GB increased by more than " + num((rules.CostSpikeRatio-1)*100) + "%. Investigate recent changes or storage class.",
+				EstimatedSavingsUSD: (r.CostPerGB - r.PreviousCostPerGB) * r.UsedGB,
+				Severity:            "Critical",
+				Priority:            1,
+				Timestamp:           now,
+				Action:              "Investigate storage cost anomaly",
+				Details: map[string]interface{}{
+					"previous_cost_per_gb": r.PreviousCostPerGB,
+					"current_cost_per_gb": r.CostPerGB,
+					"owner": r.Owner,
+					"business_impact": "Sudden storage cost increase; investigate to prevent unexpected spend.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
+			})
+		}
+		if r.UsedGB < rules.StorageMinUsedGB {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Storage",
+				Message:             "Storage '" + r.ID + "' is idle and can be moved to a lower-cost storage class to eliminate waste.",
+				EstimatedSavingsUSD: storageClassSavings(r.CostPerGB, r.UsedGB, "standard_ia", regionOrDefault(r.Region)),
+				Severity:            "Warning",
+				Priority:            2,
+				Timestamp:           now,
+				Action:              "Move to infrequent access tier",
+				Details: map[string]interface{}{
+					"region":        regionOrDefault(r.Region),
+					"storage_class": "standard",
+					"owner":         r.Owner,
+					"business_impact": "Idle storage can be archived or deleted to save costs.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+			})
+		}
+		if r.UsedGB > rules.StorageMaxUsedGB {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Storage",
+				Message:             "Storage '" + r.ID + "' is nearing capacity. Review and clean up unused data to avoid unnecessary expansion costs.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:            "Critical",
+				Timestamp:           now,
+				Action:              "Cleanup or optimize",
+				Details: map[string]interface{}{
+					"used_gb": r.UsedGB,
+					"owner":   r.Owner,
+					"business_impact": "Storage nearing capacity; cleaning up prevents additional spend.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/quotas.html",
+			})
+		}
+		if now.Unix() - r.LastAccessed > int64(rules.StorageIdleDays) * 24 * 3600 {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Storage",
+				Message:             "Storage '" + r.ID + "' has not been accessed for " + strconv.Itoa(rules.StorageIdleDays) + "+ days. Consider archiving or deleting.",
+				EstimatedSavingsUSD: storageClassSavings(r.CostPerGB, r.UsedGB, "glacier", regionOrDefault(r.Region)),
+				Severity:            "Info",
+				Priority:            3,
+				Timestamp:           now,
+				Action:              "Archive or delete",
+				Details: map[string]interface{}{
+					"last_accessed": r.LastAccessed,
+					"owner":         r.Owner,
+					"business_impact": "No access in 90+ days; archiving can save costs.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lifecycle-mgmt.html",
+			})
+		}
+		if r.CostPerGB > rules.StorageMaxCostPerGB {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Storage",
+				Message:             "Storage '" + r.ID + "' has a high cost per GB. Consider moving to a lower-cost storage class.",
+				EstimatedSavingsUSD: storageClassSavings(r.CostPerGB, r.UsedGB, "standard_ia", regionOrDefault(r.Region)),
+				Severity:            "Warning",
+				Priority:            2,
+				Timestamp:           now,
+				Action:              "Change storage class",
+				Details: map[string]interface{}{
+					"cost_per_gb": r.CostPerGB,
+					"owner":       r.Owner,
+					"business_impact": "High storage cost; move to lower-cost class for efficiency.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-class-intro.html",
+			})
+		}
+	case *models.EKSNodeGroup:
+		analyzeNodeGroup(r, sink, env)
+	case *models.EBSVolume:
+		analyzeVolume(r, sink, env)
+	case *models.EBSSnapshot:
+		analyzeSnapshot(r, sink, env)
+	case *models.ElasticIP:
+		analyzeElasticIP(r, sink, env)
+	case *models.NATGateway:
+		analyzeNATGateway(r, sink, env)
+	case *models.GCEInstance:
+		analyzeGCEInstance(r, sink, env)
+	case *models.GCSBucket:
+		analyzeGCSBucket(r, sink, env)
+	case *models.CloudSQLInstance:
+		analyzeCloudSQL(r, sink, env)
+	case *models.BigQueryDataset:
+		analyzeBigQuery(r, sink, env)
+	case *models.AzureVM:
+		analyzeAzureVM(r, sink, env)
+	case *models.AzureBlobContainer:
+		analyzeBlobContainer(r, sink, env)
+	case *models.AzureSQLDatabase:
+		analyzeAzureSQL(r, sink, env)
+	case *models.CosmosDBAccount:
+		analyzeCosmosDB(r, sink, env)
+	case *models.Database:
+		if r.PreviousCostPerHr > 0 && r.CostPerHr > r.PreviousCostPerHr*rules.CostSpikeRatio {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Database",
+				Message:             "Cost spike detected for Database '" + r.ID + "'. Hourly cost increased by more than " + num((rules.CostSpikeRatio-1)*100) + "%. Investigate recent changes or usage.",
+				EstimatedSavingsUSD: (r.CostPerHr - r.PreviousCostPerHr) * 24 * 30,
+				Severity:            "Critical",
+				Priority:            1,
+				Timestamp:           now,
+				Action:              "Investigate database cost anomaly",
+				Details: map[string]interface{}{
+					"previous_cost_per_hr": r.PreviousCostPerHr,
+					"current_cost_per_hr": r.CostPerHr,
+					"owner": r.Owner,
+					"business_impact": "Sudden database cost increase; investigate to prevent unexpected spend.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
+			})
+		}
+		if r.Connections < rules.DatabaseMinConnections {
+			details := map[string]interface{}{
+				"engine":          r.Engine,
+				"region":          r.Region,
+				"current_size":    r.InstanceClass,
+				"owner":           r.Owner,
+				"business_impact": "Over-provisioned DB; downsizing will reduce waste and save costs.",
 			}
-			if r.CPUUsage > 70.0 {
-				sink.AddSuggestion(Suggestion{
-					ResourceID:          r.ID,
-					ResourceType:        "Database",
-					Message:             "Database '" + r.ID + "' has high CPU usage. Consider query optimization or upgrading instance.",
-					EstimatedSavingsUSD: 0.0,
-					Severity:            "Warning",
-					Priority:            2,
-					Timestamp:           time.Now(),
-					Action:              "Optimize or upgrade",
-					Details: map[string]interface{}{
-						"cpu_usage": r.CPUUsage,
-						"owner":     r.Owner,
-						"business_impact": "High DB CPU usage; optimizing or upgrading can improve performance and user experience.",
-					},
-					DocsLink: "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/MonitoringOverview.html",
-				})
+			savings := 0.0
+			if rs, ok := recommendSize(env, r.ID, "rds", r.Engine, r.InstanceClass, r.Region, r.VCPU, r.MemoryGiB); ok {
+				savings = rs.MonthlySavings
+				details["recommended_size"] = rs.Recommended.Type
 			}
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Database",
+				Message:             "Database '" + r.ID + "' is over-provisioned. Consider downsizing to reduce waste.",
+				EstimatedSavingsUSD: savings,
+				Severity:            "Info",
+				Priority:            3,
+				Timestamp:           now,
+				Action:              "Downsize instance",
+				Details:             details,
+				DocsLink:            "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/Overview.DBInstanceClass.html",
+			})
+		}
+		if r.Connections > rules.DatabaseMaxConnections {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Database",
+				Message:             "Database '" + r.ID + "' has a high number of connections. Consider scaling up or load balancing.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:            "Critical",
+				Timestamp:           now,
+				Action:              "Scale up or load balance",
+				Details: map[string]interface{}{
+					"connections": r.Connections,
+					"owner":       r.Owner,
+					"business_impact": "High connection count; scaling or balancing can prevent outages and improve business continuity.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_WorkingWithConnections.html",
+			})
+		}
+		if r.CPUUsage > rules.DatabaseMaxCPU {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          r.ID,
+				ResourceType:        "Database",
+				Message:             "Database '" + r.ID + "' has high CPU usage. Consider query optimization or upgrading instance.",
+				EstimatedSavingsUSD: 0.0,
+				Severity:            "Warning",
+				Priority:            2,
+				Timestamp:           now,
+				Action:              "Optimize or upgrade",
+				Details: map[string]interface{}{
+					"cpu_usage": r.CPUUsage,
+					"owner":     r.Owner,
+					"business_impact": "High DB CPU usage; optimizing or upgrading can improve performance and user experience.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/MonitoringOverview.html",
+			})
 		}
-	}()
+	}
 }
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// azureHotTiers are the Blob Storage tiers worth leaving once data goes cold.
+var azureHotTiers = map[string]bool{"hot": true, "cool": true}
+
+// cosmosMinRUs is the least throughput a Cosmos DB container can provision.
+const cosmosMinRUs = 400
+
+func analyzeAzureVM(vm *models.AzureVM, sink SuggestionSink, env Env) {
+	checkVM(instance{
+		id: vm.ID, typ: "AzureVM", label: "Azure VM",
+		owner: vm.Owner, region: vm.Region, scope: vm.ResourceGroup, scopeKey: "resource_group",
+		size: vm.Size, vcpu: vm.VCPU, cpu: vm.CPUUsage, costPerHour: vm.CostPerHour, lastActive: vm.LastActive,
+	}, sink, env,
+		"https://learn.microsoft.com/azure/virtual-machines/resize-vm",
+		"https://learn.microsoft.com/azure/advisor/advisor-cost-recommendations")
+}
+
+func analyzeBlobContainer(b *models.AzureBlobContainer, sink SuggestionSink, env Env) {
+	checkColdData(bucket{
+		id: b.ID, typ: "AzureBlobContainer", label: "Blob container",
+		owner: b.Owner, region: b.Region, scope: b.ResourceGroup, scopeKey: "resource_group",
+		class: b.AccessTier, usedGB: b.UsedGB, costPerGBMonth: b.CostPerGBMonth, lastAccessed: b.LastAccessed,
+	}, azureHotTiers, "cold", sink, env, "https://learn.microsoft.com/azure/storage/blobs/access-tiers-overview")
+}
+
+func analyzeAzureSQL(db *models.AzureSQLDatabase, sink SuggestionSink, env Env) {
+	checkSQL(instance{
+		id: db.ID, typ: "AzureSQLDatabase", label: "Azure SQL database",
+		owner: db.Owner, region: db.Region, scope: db.ResourceGroup, scopeKey: "resource_group",
+		size: db.SKU, vcpu: db.VCores, cpu: db.CPUUsage, connections: db.Connections, costPerHour: db.CostPerHour, lastActive: db.LastActive,
+	}, sink, env,
+		"https://learn.microsoft.com/azure/azure-sql/database/scale-resources",
+		"https://learn.microsoft.com/azure/azure-sql/database/monitor-tune-overview")
+}
+
+// analyzeCosmosDB flags accounts that use less than CosmosMinRUUtilization
+// percent of the throughput they provision, recommending consumption plus
+// RightsizingHeadroom rounded up to 100 RU/s.
+func analyzeCosmosDB(c *models.CosmosDBAccount, sink SuggestionSink, env Env) {
+	rules := env.Rules
+	if c.ProvisionedRUs <= cosmosMinRUs || c.RUUtilization() >= rules.CosmosMinRUUtilization {
+		return
+	}
+	recommended := math.Max(cosmosMinRUs, roundUp(c.ConsumedRUs*(1+rules.RightsizingHeadroom), 100))
+	if recommended >= c.ProvisionedRUs {
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          c.ID,
+		ResourceType:        "CosmosDBAccount",
+		Message:             "Cosmos DB account '" + c.ID + "' uses " + num(math.Round(c.RUUtilization())) + "% of its " + num(c.ProvisionedRUs) + " RU/s. Consider provisioning " + num(recommended) + " RU/s or switching to autoscale.",
+		EstimatedSavingsUSD: (c.ProvisionedRUs - recommended) / 100 * c.CostPer100RUHour * 24 * 30,
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           env.Now,
+		Action:              "Reduce provisioned throughput",
+		Details: map[string]interface{}{
+			"owner":           c.Owner,
+			"region":          c.Region,
+			"resource_group":  c.ResourceGroup,
+			"provisioned_rus": c.ProvisionedRUs,
+			"consumed_rus":    c.ConsumedRUs,
+			"recommended_rus": recommended,
+			"business_impact": "Provisioned throughput is billed whether or not it is used.",
+		},
+		DocsLink: "https://learn.microsoft.com/azure/cosmos-db/optimize-cost-throughput",
+	})
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// gcsHotClasses are the GCS classes worth leaving once data goes cold.
+var gcsHotClasses = map[string]bool{"standard": true, "nearline": true}
+
+func analyzeGCEInstance(i *models.GCEInstance, sink SuggestionSink, env Env) {
+	checkVM(instance{
+		id: i.ID, typ: "GCEInstance", label: "Compute Engine instance",
+		owner: i.Owner, region: i.Region, scope: i.Project, scopeKey: "project",
+		size: i.MachineType, vcpu: i.VCPU, cpu: i.CPUUsage, costPerHour: i.CostPerHour, lastActive: i.LastActive,
+	}, sink, env,
+		"https://cloud.google.com/compute/docs/instances/apply-machine-type-recommendations-for-instances",
+		"https://cloud.google.com/compute/docs/instances/viewing-and-applying-idle-vm-recommendations")
+}
+
+func analyzeGCSBucket(b *models.GCSBucket, sink SuggestionSink, env Env) {
+	checkColdData(bucket{
+		id: b.ID, typ: "GCSBucket", label: "Cloud Storage bucket",
+		owner: b.Owner, region: b.Region, scope: b.Project, scopeKey: "project",
+		class: b.StorageClass, usedGB: b.UsedGB, costPerGBMonth: b.CostPerGBMonth, lastAccessed: b.LastAccessed,
+	}, gcsHotClasses, "coldline", sink, env, "https://cloud.google.com/storage/docs/storage-classes")
+}
+
+func analyzeCloudSQL(i *models.CloudSQLInstance, sink SuggestionSink, env Env) {
+	checkSQL(instance{
+		id: i.ID, typ: "CloudSQLInstance", label: "Cloud SQL instance",
+		owner: i.Owner, region: i.Region, scope: i.Project, scopeKey: "project",
+		size: i.Tier, vcpu: i.VCPU, cpu: i.CPUUsage, connections: i.Connections, costPerHour: i.CostPerHour, lastActive: i.LastActive,
+	}, sink, env,
+		"https://cloud.google.com/sql/docs/postgres/instance-settings",
+		"https://cloud.google.com/sql/docs/postgres/optimize-cpu-usage")
+}
+
+// analyzeBigQuery flags datasets whose on-demand scans cost more than
+// BigQueryMaxScanCostPerDay, and datasets nobody has queried for
+// StorageIdleDays.
+func analyzeBigQuery(d *models.BigQueryDataset, sink SuggestionSink, env Env) {
+	rules, now := env.Rules, env.Now
+	if perDay := d.TBScannedPerDay * d.CostPerTBScanned; perDay > rules.BigQueryMaxScanCostPerDay {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          d.ID,
+			ResourceType:        "BigQueryDataset",
+			Message:             "BigQuery dataset '" + d.ID + "' scans $" + num(perDay) + " of data per day, over the $" + num(rules.BigQueryMaxScanCostPerDay) + " limit. Consider partitioning or clustering its tables and selecting fewer columns.",
+			EstimatedSavingsUSD: (perDay - rules.BigQueryMaxScanCostPerDay) * 30,
+			Severity:            "Warning",
+			Priority:            2,
+			Timestamp:           now,
+			Action:              "Reduce bytes scanned",
+			Details: map[string]interface{}{
+				"owner":              d.Owner,
+				"project":            d.Project,
+				"tb_scanned_per_day": d.TBScannedPerDay,
+				"query_cost_monthly": d.QueryCost(),
+				"business_impact":    "Full-table scans are billed per byte read; pruning them cuts query spend directly.",
+			},
+			DocsLink: "https://cloud.google.com/bigquery/docs/best-practices-costs",
+		})
+	}
+	if idle, ok := since(now, d.LastQueried); ok && idle > time.Duration(rules.StorageIdleDays)*24*time.Hour {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          d.ID,
+			ResourceType:        "BigQueryDataset",
+			Message:             "BigQuery dataset '" + d.ID + "' has not been queried for " + strconv.Itoa(rules.StorageIdleDays) + "+ days. Consider exporting it to Cloud Storage or deleting it.",
+			EstimatedSavingsUSD: d.StorageCost(),
+			Severity:            "Info",
+			Priority:            3,
+			Timestamp:           now,
+			Action:              "Archive or delete",
+			Details: map[string]interface{}{
+				"owner":           d.Owner,
+				"project":         d.Project,
+				"stored_gb":       d.StoredGB,
+				"last_queried":    d.LastQueried,
+				"business_impact": "Unqueried datasets keep accruing storage charges.",
+			},
+			DocsLink: "https://cloud.google.com/bigquery/docs/best-practices-storage",
+		})
+	}
+}
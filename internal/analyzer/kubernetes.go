@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// analyzeNodeGroup checks an EKS node group for idle capacity, nodes its pods'
+// requests do not need, and pods that request far more than they use.
+func analyzeNodeGroup(ng *models.EKSNodeGroup, sink SuggestionSink, env Env) {
+	rules, now := env.Rules, env.Now
+	perNode := ng.CostPerNodeHour * 24 * 30
+
+	if ng.Pods == 0 {
+		// A group that has never run a pod has been idle since it was created.
+		last := ng.LastActive
+		if last == 0 {
+			last = ng.CreatedAt
+		}
+		if last > 0 && now.Unix()-last > int64(rules.K8sIdleHours)*3600 && ng.NodeCount > ng.MinNodes {
+			sink.AddSuggestion(Suggestion{
+				ResourceID:          ng.ID,
+				ResourceType:        "EKSNodeGroup",
+				Message:             "Node group '" + ng.ID + "' has run no pods for " + strconv.Itoa(rules.K8sIdleHours) + "+ hours. Consider scaling it down to its minimum of " + strconv.Itoa(ng.MinNodes) + " nodes or deleting it.",
+				EstimatedSavingsUSD: float64(ng.NodeCount-ng.MinNodes) * perNode,
+				Severity:            "Critical",
+				Timestamp:           now,
+				Action:              "Scale down idle node group",
+				Details: map[string]interface{}{
+					"cluster":         ng.ClusterID,
+					"owner":           ng.Owner,
+					"nodes":           ng.NodeCount,
+					"min_nodes":       ng.MinNodes,
+					"last_active":     ng.LastActive,
+					"business_impact": "Idle worker nodes are billed in full; scaling down eliminates the waste.",
+				},
+				DocsLink: "https://docs.aws.amazon.com/eks/latest/userguide/managed-node-groups.html",
+			})
+		}
+		return
+	}
+
+	needed := nodesNeeded(ng, ng.RequestedCPU, ng.RequestedMemoryGiB, rules.K8sBinPackingTarget)
+	if ng.NodeCount > needed {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          ng.ID,
+			ResourceType:        "EKSNodeGroup",
+			Message:             "Node group '" + ng.ID + "' runs " + strconv.Itoa(ng.NodeCount) + " nodes but its pods' requests fit on " + strconv.Itoa(needed) + " at " + num(rules.K8sBinPackingTarget) + "% utilization. Consider consolidating nodes.",
+			EstimatedSavingsUSD: float64(ng.NodeCount-needed) * perNode,
+			Severity:            "Warning",
+			Priority:            2,
+			Timestamp:           now,
+			Action:              "Consolidate nodes",
+			Details: map[string]interface{}{
+				"cluster":              ng.ClusterID,
+				"owner":                ng.Owner,
+				"instance_type":        ng.InstanceType,
+				"nodes":                ng.NodeCount,
+				"nodes_needed":         needed,
+				"pods":                 ng.Pods,
+				"pod_density":          ng.PodDensity(),
+				"cpu_requested_pct":    ng.Metrics()[models.MetricCPURequested],
+				"memory_requested_pct": ng.Metrics()[models.MetricMemoryRequested],
+				"business_impact":      "Poorly packed nodes leave paid capacity unscheduled; consolidating frees whole nodes.",
+			},
+			DocsLink: "https://docs.aws.amazon.com/eks/latest/userguide/autoscaling.html",
+		})
+	}
+
+	if env.History == nil {
+		return
+	}
+	cpu, n := env.History.Percentile(ng.ID, models.MetricCPURequestUtilization, rules.RightsizingPercentile)
+	mem, m := env.History.Percentile(ng.ID, models.MetricMemoryRequestUtilization, rules.RightsizingPercentile)
+	if min(n, m) < rules.K8sMinSamples || (cpu >= rules.K8sMaxRequestUtilization && mem >= rules.K8sMaxRequestUtilization) {
+		return
+	}
+	// Requests sized to observed usage plus headroom, never above today's.
+	headroom := 1 + rules.RightsizingHeadroom
+	cpuRequest := math.Min(ng.RequestedCPU, ng.RequestedCPU*cpu/100*headroom)
+	memRequest := math.Min(ng.RequestedMemoryGiB, ng.RequestedMemoryGiB*mem/100*headroom)
+	// Only count nodes beyond those consolidation already frees.
+	freed := max(min(ng.NodeCount, needed)-nodesNeeded(ng, cpuRequest, memRequest, rules.K8sBinPackingTarget), 0)
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          ng.ID,
+		ResourceType:        "EKSNodeGroup",
+		Message:             "Pods in node group '" + ng.ID + "' use " + num(math.Round(math.Min(cpu, mem))) + "% of what they request (p" + num(rules.RightsizingPercentile) + "). Consider lowering their CPU and memory requests.",
+		EstimatedSavingsUSD: float64(freed) * perNode,
+		Severity:            "Info",
+		Priority:            3,
+		Timestamp:           now,
+		Action:              "Reduce pod requests",
+		Details: map[string]interface{}{
+			"cluster":                    ng.ClusterID,
+			"owner":                      ng.Owner,
+			"cpu_request_utilization":    cpu,
+			"memory_request_utilization": mem,
+			"requested_cpu":              ng.RequestedCPU,
+			"recommended_cpu":            cpuRequest,
+			"requested_memory_gib":       ng.RequestedMemoryGiB,
+			"recommended_memory_gib":     memRequest,
+			"nodes_freed":                freed,
+			"samples":                    min(n, m),
+			"business_impact":            "Over-requested pods reserve capacity they never use, forcing extra nodes.",
+		},
+		DocsLink: "https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/",
+	})
+}
+
+// nodesNeeded is the number of ng's nodes that fit cpu and memory requests
+// with each node filled to target percent, and its pods within the per-node
+// limit, but never fewer than the group's minimum or one.
+func nodesNeeded(ng *models.EKSNodeGroup, cpu, memGiB, target float64) int {
+	need := func(total, perNode float64) int {
+		if perNode <= 0 {
+			return 0
+		}
+		return int(math.Ceil(total/perNode - 1e-9))
+	}
+	n := max(need(cpu, ng.AllocatableCPU*target/100), need(memGiB, ng.AllocatableMemoryGiB*target/100))
+	if ng.MaxPodsPerNode > 0 {
+		n = max(n, need(float64(ng.Pods), float64(ng.MaxPodsPerNode)))
+	}
+	return max(n, ng.MinNodes, 1)
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func nodeGroupActions(ng *models.EKSNodeGroup, env Env, samples int, step func(i int)) map[string]Suggestion {
+	start := env.Now
+	var sink *InMemorySuggestionSink
+	for i := 0; i < samples; i++ {
+		if step != nil {
+			step(i)
+		}
+		env.Now = start.Add(time.Duration(i) * time.Minute)
+		sink = &InMemorySuggestionSink{}
+		AnalyzeWith(ng, sink, env)
+	}
+	out := map[string]Suggestion{}
+	for _, s := range sink.GetSuggestions() {
+		if s.Action != "Fix tags" {
+			out[s.Action] = s
+		}
+	}
+	return out
+}
+
+func TestNodeGroupChecks(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	rules := DefaultRules()
+
+	// 8 vCPU of requests on six 4-vCPU nodes fit on three at 80%, and pods use
+	// a quarter of what they request.
+	busy := &models.EKSNodeGroup{
+		ID: "ng-busy", NodeCount: 6, MinNodes: 2, AllocatableCPU: 4, AllocatableMemoryGiB: 16,
+		RequestedCPU: 8, RequestedMemoryGiB: 16, Pods: 40, MaxPodsPerNode: 58, CostPerNodeHour: 0.2,
+	}
+	got := nodeGroupActions(busy, Env{Rules: rules, Now: now, History: history.NewStore(0)}, rules.K8sMinSamples, func(int) {
+		busy.UsedCPU, busy.UsedMemoryGiB = 2, 4
+	})
+	consolidate, ok := got["Consolidate nodes"]
+	if !ok {
+		t.Fatalf("no consolidation suggestion: %v", got)
+	}
+	if consolidate.Details["nodes_needed"] != 3 || math.Abs(consolidate.EstimatedSavingsUSD-3*0.2*24*30) > 1e-9 {
+		t.Errorf("consolidation = %v nodes, $%v", consolidate.Details["nodes_needed"], consolidate.EstimatedSavingsUSD)
+	}
+	requests, ok := got["Reduce pod requests"]
+	if !ok {
+		t.Fatalf("no over-request suggestion: %v", got)
+	}
+	// Requests of 2.4 vCPU and 4.8 GiB fit on the minimum of two nodes, one
+	// fewer than consolidation alone.
+	if requests.Details["nodes_freed"] != 1 {
+		t.Errorf("nodes_freed = %v, want 1", requests.Details["nodes_freed"])
+	}
+
+	// Without history the over-request check cannot run.
+	if got := nodeGroupActions(busy, Env{Rules: rules, Now: now}, 1, nil); len(got) != 1 {
+		t.Errorf("got %v without history, want only consolidation", got)
+	}
+
+	idle := &models.EKSNodeGroup{
+		ID: "ng-idle", NodeCount: 3, MinNodes: 1, AllocatableCPU: 2, AllocatableMemoryGiB: 8, CostPerNodeHour: 0.1,
+		LastActive: now.Add(-48 * time.Hour).Unix(),
+	}
+	got = nodeGroupActions(idle, Env{Rules: rules, Now: now}, 1, nil)
+	if s, ok := got["Scale down idle node group"]; !ok || math.Abs(s.EstimatedSavingsUSD-2*0.1*24*30) > 1e-9 {
+		t.Errorf("idle suggestion = %+v", got)
+	}
+	idle.LastActive = now.Add(-time.Hour).Unix()
+	if got := nodeGroupActions(idle, Env{Rules: rules, Now: now}, 1, nil); len(got) != 0 {
+		t.Errorf("recently active group got %v", got)
+	}
+
+	// A group that never ran a pod is idle from its creation.
+	idle.LastActive, idle.CreatedAt = 0, now.Add(-48*time.Hour).Unix()
+	if _, ok := nodeGroupActions(idle, Env{Rules: rules, Now: now}, 1, nil)["Scale down idle node group"]; !ok {
+		t.Error("group that never ran a pod was not flagged")
+	}
+	idle.CreatedAt = now.Add(-time.Hour).Unix()
+	if got := nodeGroupActions(idle, Env{Rules: rules, Now: now}, 1, nil); len(got) != 0 {
+		t.Errorf("newly created group got %v", got)
+	}
+}
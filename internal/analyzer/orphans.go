@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// missing reports whether id refers to a resource known not to exist. Every
+// reference resolves when there is no graph.
+func (env Env) missing(id string) bool {
+	return env.Graph != nil && env.Graph.Missing(id)
+}
+
+// since returns how long ago the Unix time t was, or false when t is unknown.
+func since(now time.Time, t int64) (time.Duration, bool) {
+	if t == 0 {
+		return 0, false
+	}
+	return now.Sub(time.Unix(t, 0)), true
+}
+
+// analyzeVolume flags volumes that have been detached for a while, or whose VM
+// no longer exists.
+func analyzeVolume(v *models.EBSVolume, sink SuggestionSink, env Env) {
+	rules, now := env.Rules, env.Now
+	var reason string
+	switch {
+	case env.missing(v.AttachedTo):
+		reason = "is attached to VM '" + v.AttachedTo + "', which no longer exists"
+	case v.AttachedTo == "":
+		last := v.LastAttached
+		if last == 0 {
+			last = v.CreatedAt
+		}
+		if d, ok := since(now, last); !ok || d < time.Duration(rules.EBSUnattachedDays)*24*time.Hour {
+			return
+		}
+		reason = "has been unattached for " + strconv.Itoa(rules.EBSUnattachedDays) + "+ days"
+	default:
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          v.ID,
+		ResourceType:        "EBSVolume",
+		Message:             "EBS volume '" + v.ID + "' " + reason + ". Consider snapshotting and deleting it.",
+		EstimatedSavingsUSD: v.GetMonthlyCost(),
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           now,
+		Action:              "Delete unattached volume",
+		Details: map[string]interface{}{
+			"owner":           v.Owner,
+			"region":          v.Region,
+			"volume_type":     v.VolumeType,
+			"size_gb":         v.SizeGB,
+			"attached_to":     v.AttachedTo,
+			"last_attached":   v.LastAttached,
+			"business_impact": "Unattached volumes are billed for their full size while serving nothing.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/ebs/latest/userguide/ebs-deleting-volume.html",
+	})
+}
+
+// analyzeSnapshot flags snapshots whose source volume is gone, once they are
+// old enough that they are unlikely to be a deliberate recent backup.
+func analyzeSnapshot(s *models.EBSSnapshot, sink SuggestionSink, env Env) {
+	rules, now := env.Rules, env.Now
+	if s.VolumeID != "" && !env.missing(s.VolumeID) {
+		return
+	}
+	if age, ok := since(now, s.CreatedAt); ok && age < time.Duration(rules.EBSSnapshotMinAgeDays)*24*time.Hour {
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          s.ID,
+		ResourceType:        "EBSSnapshot",
+		Message:             "EBS snapshot '" + s.ID + "' is orphaned: its source volume no longer exists. Consider deleting it or moving it to archive storage.",
+		EstimatedSavingsUSD: s.GetMonthlyCost(),
+		Severity:            "Info",
+		Priority:            3,
+		Timestamp:           now,
+		Action:              "Delete orphaned snapshot",
+		Details: map[string]interface{}{
+			"owner":           s.Owner,
+			"region":          s.Region,
+			"volume_id":       s.VolumeID,
+			"size_gb":         s.SizeGB,
+			"created_at":      s.CreatedAt,
+			"business_impact": "Snapshots of deleted volumes accumulate storage charges long after they stop being useful.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/ebs/latest/userguide/ebs-deleting-snapshot.html",
+	})
+}
+
+// analyzeElasticIP flags addresses that are not associated with anything that
+// exists.
+func analyzeElasticIP(e *models.ElasticIP, sink SuggestionSink, env Env) {
+	reason := "is not associated with any resource"
+	switch {
+	case env.missing(e.AssociatedWith):
+		reason = "is associated with '" + e.AssociatedWith + "', which no longer exists"
+	case e.AssociatedWith != "":
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          e.ID,
+		ResourceType:        "ElasticIP",
+		Message:             "Elastic IP '" + e.ID + "' " + reason + ". Consider releasing it.",
+		EstimatedSavingsUSD: e.GetMonthlyCost(),
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           env.Now,
+		Action:              "Release Elastic IP",
+		Details: map[string]interface{}{
+			"owner":           e.Owner,
+			"region":          e.Region,
+			"public_ip":       e.PublicIP,
+			"associated_with": e.AssociatedWith,
+			"business_impact": "Idle public addresses are billed hourly for nothing.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/elastic-ip-addresses-eip.html",
+	})
+}
+
+// analyzeNATGateway flags gateways whose traffic does not justify their hourly
+// charge. Processing charges follow the traffic wherever it goes, so only the
+// hourly charge is counted as savings.
+func analyzeNATGateway(n *models.NATGateway, sink SuggestionSink, env Env) {
+	rules := env.Rules
+	if n.GBPerHour >= rules.NATMinGBPerHour {
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          n.ID,
+		ResourceType:        "NATGateway",
+		Message:             "NAT gateway '" + n.ID + "' processes under " + num(rules.NATMinGBPerHour) + " GB per hour. Consider sharing a gateway across subnets or using VPC endpoints.",
+		EstimatedSavingsUSD: n.CostPerHour * 24 * 30,
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           env.Now,
+		Action:              "Remove low-traffic NAT gateway",
+		Details: map[string]interface{}{
+			"owner":           n.Owner,
+			"region":          n.Region,
+			"gb_per_hour":     n.GBPerHour,
+			"hourly_cost":     n.CostPerHour,
+			"business_impact": "The hourly charge of a barely used gateway outweighs the traffic it carries.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/vpc/latest/userguide/nat-gateway-pricing.html",
+	})
+}
+
+// dedicatedOrphanChecks are the types whose own checks already flag them when
+// their references dangle.
+var dedicatedOrphanChecks = map[string]bool{"EBSVolume": true, "EBSSnapshot": true, "ElasticIP": true}
+
+// checkOrphaned flags any other resource whose every reference points to a
+// resource that no longer exists, such as a node group of a deleted cluster
+// or a load balancer whose targets are all gone.
+func checkOrphaned(r models.CloudResource, sink SuggestionSink, env Env) {
+	if env.Graph == nil || dedicatedOrphanChecks[r.GetType()] || !env.Graph.Orphaned(r.GetId()) {
+		return
+	}
+	var missing []string
+	for _, e := range env.Graph.Outgoing(r.GetId()) {
+		missing = append(missing, e.To)
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          r.GetId(),
+		ResourceType:        r.GetType(),
+		Message:             r.GetType() + " '" + r.GetId() + "' only refers to resources that no longer exist. Consider removing it.",
+		EstimatedSavingsUSD: r.GetMonthlyCost(),
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           env.Now,
+		Action:              "Remove orphaned resource",
+		Details: map[string]interface{}{
+			"owner":           r.GetOwner(),
+			"missing":         missing,
+			"business_impact": "Resources left behind by deleted dependencies keep billing without serving anything.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/resource-explorer/latest/userguide/welcome.html",
+	})
+}
+
+// removalVerbs maps the actions that remove a resource to how the blast
+// radius describes them.
+var removalVerbs = map[string]string{
+	"Terminate":                      "terminating",
+	"Resize or terminate":            "terminating",
+	"Archive or delete":              "deleting",
+	"Review for removal":             "removing",
+	"Review for downsizing/removal":  "removing",
+	"Delete unattached volume":       "deleting",
+	"Delete orphaned snapshot":       "deleting",
+	"Release Elastic IP":             "releasing",
+	"Remove low-traffic NAT gateway": "removing",
+	"Scale down idle node group":     "scaling down",
+	"Remove orphaned resource":       "removing",
+}
+
+// blastRadiusSink annotates suggestions that remove a resource with what
+// depends on it, e.g. "terminating vm-1 removes a healthy host from elb-1".
+type blastRadiusSink struct {
+	SuggestionSink
+	impacts []graph.Impact
+}
+
+func (s blastRadiusSink) AddSuggestion(sug Suggestion) {
+	if verb, ok := removalVerbs[sug.Action]; ok {
+		details := make(map[string]interface{}, len(sug.Details)+1)
+		for k, v := range sug.Details {
+			details[k] = v
+		}
+		radius := make([]string, 0, len(s.impacts))
+		for _, imp := range s.impacts {
+			radius = append(radius, verb+" "+sug.ResourceID+" "+imp.Effect)
+		}
+		details["blast_radius"] = radius
+		sug.Details = details
+	}
+	s.SuggestionSink.AddSuggestion(sug)
+}
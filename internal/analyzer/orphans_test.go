@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestOrphanChecks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) int64 { return now.AddDate(0, 0, -d).Unix() }
+	vm := &models.VM{ID: "vm-1"}
+	vol := &models.EBSVolume{ID: "vol-1", AttachedTo: "vm-1", SizeGB: 100, CostPerGBMonth: 0.08}
+
+	cases := []struct {
+		name   string
+		res    models.CloudResource
+		deps   *graph.Graph
+		action string
+		saving float64
+	}{
+		{"attached volume", vol, graph.Build([]models.CloudResource{vm, vol}), "", 0},
+		{"volume of a deleted VM", vol, graph.Build([]models.CloudResource{vol}), "Delete unattached volume", 8},
+		{"recently detached volume", &models.EBSVolume{ID: "vol-2", SizeGB: 10, CostPerGBMonth: 0.1, LastAttached: daysAgo(2)}, nil, "", 0},
+		{"long detached volume", &models.EBSVolume{ID: "vol-2", SizeGB: 10, CostPerGBMonth: 0.1, LastAttached: daysAgo(8)}, nil, "Delete unattached volume", 1},
+		{"snapshot of a live volume", &models.EBSSnapshot{ID: "snap-1", VolumeID: "vol-1", CreatedAt: daysAgo(90)}, graph.Build([]models.CloudResource{vol}), "", 0},
+		{"snapshot without inventory", &models.EBSSnapshot{ID: "snap-1", VolumeID: "vol-9", CreatedAt: daysAgo(90)}, nil, "", 0},
+		{"orphaned snapshot", &models.EBSSnapshot{ID: "snap-2", VolumeID: "vol-9", SizeGB: 200, CostPerGBMonth: 0.05, CreatedAt: daysAgo(90)}, graph.Build([]models.CloudResource{vol}), "Delete orphaned snapshot", 10},
+		{"recent orphaned snapshot", &models.EBSSnapshot{ID: "snap-3", SizeGB: 200, CostPerGBMonth: 0.05, CreatedAt: daysAgo(3)}, nil, "", 0},
+		{"associated address", &models.ElasticIP{ID: "eip-1", AssociatedWith: "vm-1", CostPerHour: 0.005}, graph.Build([]models.CloudResource{vm}), "", 0},
+		{"unassociated address", &models.ElasticIP{ID: "eip-2", CostPerHour: 0.005}, nil, "Release Elastic IP", 3.6},
+		{"busy NAT gateway", &models.NATGateway{ID: "nat-1", GBPerHour: 20, CostPerHour: 0.045, CostPerGB: 0.045}, nil, "", 0},
+		{"idle NAT gateway", &models.NATGateway{ID: "nat-2", GBPerHour: 0.1, CostPerHour: 0.045, CostPerGB: 0.045}, nil, "Remove low-traffic NAT gateway", 32.4},
+	}
+	for _, c := range cases {
+		sink := &InMemorySuggestionSink{}
+		AnalyzeWith(c.res, sink, Env{Rules: DefaultRules(), Now: now, Graph: c.deps})
+		var got []Suggestion
+		for _, s := range sink.GetSuggestions() {
+			if s.Action != "Fix tags" {
+				got = append(got, s)
+			}
+		}
+		if c.action == "" {
+			if len(got) != 0 {
+				t.Errorf("%s: unexpected suggestions %+v", c.name, got)
+			}
+			continue
+		}
+		if len(got) != 1 || got[0].Action != c.action {
+			t.Errorf("%s: got %+v, want %q", c.name, got, c.action)
+			continue
+		}
+		if d := got[0].EstimatedSavingsUSD - c.saving; d > 1e-9 || d < -1e-9 {
+			t.Errorf("%s: savings = %v, want %v", c.name, got[0].EstimatedSavingsUSD, c.saving)
+		}
+	}
+}
+
+func TestOrphanedNodeGroupAndBlastRadius(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	vm := &models.VM{ID: "vm-1", CPUUsage: 1, CostPerHour: 0.1}
+	elb := &models.ELB{ID: "elb-1", Targets: []string{"vm-1"}}
+	vol := &models.EBSVolume{ID: "vol-1", AttachedTo: "vm-1"}
+	ng := &models.EKSNodeGroup{ID: "ng-1", ClusterID: "eks-gone", NodeCount: 2, CostPerNodeHour: 0.1}
+	deps := graph.Build([]models.CloudResource{vm, elb, vol, ng})
+	env := Env{Rules: DefaultRules(), Now: now, Graph: deps}
+
+	sink := &InMemorySuggestionSink{}
+	AnalyzeWith(vm, sink, env)
+	var annotated bool
+	for _, s := range sink.GetSuggestions() {
+		radius, ok := s.Details["blast_radius"].([]string)
+		if s.Action != "Resize or terminate" {
+			if ok {
+				t.Errorf("%q should not carry a blast radius", s.Action)
+			}
+			continue
+		}
+		annotated = true
+		want := []string{"terminating vm-1 removes a healthy host from elb-1", "terminating vm-1 leaves volume vol-1 unattached"}
+		if len(radius) != len(want) || radius[0] != want[0] || radius[1] != want[1] {
+			t.Errorf("blast_radius = %q, want %q", radius, want)
+		}
+	}
+	if !annotated {
+		t.Fatal("no terminate suggestion for an underutilized VM")
+	}
+
+	sink = &InMemorySuggestionSink{}
+	AnalyzeWith(ng, sink, env)
+	var orphaned bool
+	for _, s := range sink.GetSuggestions() {
+		if s.Action == "Remove orphaned resource" {
+			orphaned = true
+			if d := s.EstimatedSavingsUSD - 0.2*24*30; d > 1e-9 || d < -1e-9 {
+				t.Errorf("savings = %v", s.EstimatedSavingsUSD)
+			}
+		}
+	}
+	if !orphaned {
+		t.Errorf("node group of a deleted cluster not flagged: %+v", sink.GetSuggestions())
+	}
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+)
+
+var (
+	catalogMu     sync.RWMutex
+	activeCatalog = pricing.Default()
+)
+
+// SetCatalog replaces the price table used to estimate savings.
+func SetCatalog(c *pricing.Catalog) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	activeCatalog = c
+}
+
+func CurrentCatalog() *pricing.Catalog {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return activeCatalog
+}
+
+// regionOrDefault prices resources that do not record a region in the
+// catalog's default region.
+func regionOrDefault(region string) string {
+	if region == "" {
+		return pricing.DefaultRegion
+	}
+	return region
+}
+
+// storageClassSavings is the monthly saving of moving usedGB billed at
+// costPerGB to class in region.
+func storageClassSavings(costPerGB, usedGB float64, class, region string) float64 {
+	target, ok := CurrentCatalog().StorageClass(class, region)
+	if !ok {
+		return 0
+	}
+	return math.Max(0, (costPerGB-target.PerGBMonth)*usedGB)
+}
+
+// commitmentSavings estimates the monthly saving of moving hourly on-demand
+// spend to option (reserved or spot). The discount is taken from the catalog
+// entry for typ, or from the SKU with the closest on-demand price when typ is
+// not in the catalog.
+func commitmentSavings(service, engine, typ, region string, hourly float64, option string) (pricing.InstancePrice, float64) {
+	c := CurrentCatalog()
+	p, ok := c.Instance(service, engine, typ, region)
+	if !ok {
+		p, ok = c.ClosestInstance(service, engine, region, hourly)
+	}
+	if !ok || p.Hourly(option) == 0 {
+		return p, 0
+	}
+	discount := 1 - p.Hourly(option)/p.OnDemandHourly
+	return p, math.Max(0, hourly*discount*pricing.HoursPerMonth)
+}
@@ -0,0 +1,165 @@
+package analyzer
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// instance is what the provider-neutral compute checks need to know about a
+// VM or managed database on GCP or Azure.
+type instance struct {
+	id, typ, label string
+	owner, region  string
+	// scope is the project or resource group, keyed by scopeKey in Details.
+	scope, scopeKey string
+	size            string
+	vcpu            int
+	cpu             float64
+	connections     int
+	costPerHour     float64
+	lastActive      int64
+}
+
+func (in instance) details(extra map[string]interface{}) map[string]interface{} {
+	d := map[string]interface{}{
+		"owner":       in.owner,
+		"region":      in.region,
+		in.scopeKey:   in.scope,
+		"size":        in.size,
+		"cpu_usage":   in.cpu,
+		"hourly_cost": in.costPerHour,
+	}
+	for k, v := range extra {
+		d[k] = v
+	}
+	return d
+}
+
+// checkVM flags a GCP or Azure VM that is underutilized or has been idle for
+// VMIdleDays. Without a provider price table the saving of resizing is taken
+// to be the whole instance, as for EC2 types missing from the catalog.
+func checkVM(in instance, sink SuggestionSink, env Env, resizeDocs, idleDocs string) {
+	rules, now := env.Rules, env.Now
+	monthly := in.costPerHour * 24 * 30
+	if in.cpu < rules.VMMinCPU {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          in.id,
+			ResourceType:        in.typ,
+			Message:             in.label + " '" + in.id + "' is underutilized (CPU < " + num(rules.VMMinCPU) + "%). Consider resizing or terminating to eliminate waste.",
+			EstimatedSavingsUSD: monthly,
+			Severity:            "Critical",
+			Timestamp:           now,
+			Action:              "Resize or terminate",
+			Details: in.details(map[string]interface{}{
+				"business_impact": "Little CPU in use; a smaller size or removing it will save costs.",
+			}),
+			DocsLink: resizeDocs,
+		})
+	}
+	if in.lastActive > 0 && now.Unix()-in.lastActive > int64(rules.VMIdleDays)*24*3600 {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          in.id,
+			ResourceType:        in.typ,
+			Message:             in.label + " '" + in.id + "' has not been active for " + strconv.Itoa(rules.VMIdleDays) + "+ days. Consider terminating to eliminate waste.",
+			EstimatedSavingsUSD: monthly,
+			Severity:            "Critical",
+			Timestamp:           now,
+			Action:              "Terminate",
+			Details: in.details(map[string]interface{}{
+				"last_active":     in.lastActive,
+				"business_impact": "Resource idle for over a month; terminating will save $/month.",
+			}),
+			DocsLink: idleDocs,
+		})
+	}
+}
+
+// checkSQL flags a managed GCP or Azure database with too few connections to
+// justify its size, or CPU high enough to need attention. Sizes double in
+// vCPU from one step to the next, so one size down is taken to halve the cost.
+func checkSQL(in instance, sink SuggestionSink, env Env, sizingDocs, cpuDocs string) {
+	rules, now := env.Rules, env.Now
+	if in.connections < rules.DatabaseMinConnections && in.vcpu > 1 {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          in.id,
+			ResourceType:        in.typ,
+			Message:             in.label + " '" + in.id + "' has fewer than " + strconv.Itoa(rules.DatabaseMinConnections) + " connections. Consider downsizing to reduce waste.",
+			EstimatedSavingsUSD: in.costPerHour / 2 * 24 * 30,
+			Severity:            "Info",
+			Priority:            3,
+			Timestamp:           now,
+			Action:              "Downsize instance",
+			Details: in.details(map[string]interface{}{
+				"connections":     in.connections,
+				"vcpu":            in.vcpu,
+				"business_impact": "Over-provisioned DB; downsizing will reduce waste and save costs.",
+			}),
+			DocsLink: sizingDocs,
+		})
+	}
+	if in.cpu > rules.DatabaseMaxCPU {
+		sink.AddSuggestion(Suggestion{
+			ResourceID:          in.id,
+			ResourceType:        in.typ,
+			Message:             in.label + " '" + in.id + "' has high CPU usage. Consider query optimization or upgrading instance.",
+			EstimatedSavingsUSD: 0.0,
+			Severity:            "Warning",
+			Priority:            2,
+			Timestamp:           now,
+			Action:              "Optimize or upgrade",
+			Details: in.details(map[string]interface{}{
+				"business_impact": "High DB CPU usage; optimizing or upgrading can improve performance and user experience.",
+			}),
+			DocsLink: cpuDocs,
+		})
+	}
+}
+
+// bucket is what the cold data check needs to know about a GCS bucket or an
+// Azure Blob container.
+type bucket struct {
+	id, typ, label  string
+	owner, region   string
+	scope, scopeKey string
+	class           string
+	usedGB          float64
+	costPerGBMonth  float64
+	lastAccessed    int64
+}
+
+// checkColdData flags a bucket in a hot class (one of hot) that has not been
+// accessed for StorageIdleDays, priced against moving it to the cold class.
+func checkColdData(b bucket, hot map[string]bool, cold string, sink SuggestionSink, env Env, docs string) {
+	rules, now := env.Rules, env.Now
+	idle, ok := since(now, b.lastAccessed)
+	if !ok || !hot[b.class] || idle <= time.Duration(rules.StorageIdleDays)*24*time.Hour {
+		return
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:          b.id,
+		ResourceType:        b.typ,
+		Message:             b.label + " '" + b.id + "' has not been accessed for " + strconv.Itoa(rules.StorageIdleDays) + "+ days but is stored as " + b.class + ". Consider moving it to " + cold + ".",
+		EstimatedSavingsUSD: storageClassSavings(b.costPerGBMonth, b.usedGB, cold, b.region),
+		Severity:            "Warning",
+		Priority:            2,
+		Timestamp:           now,
+		Action:              "Change storage class",
+		Details: map[string]interface{}{
+			"owner":           b.owner,
+			"region":          b.region,
+			b.scopeKey:        b.scope,
+			"storage_class":   b.class,
+			"target_class":    cold,
+			"used_gb":         b.usedGB,
+			"last_accessed":   b.lastAccessed,
+			"business_impact": "Cold data billed at hot-class rates; a colder class costs a fraction per GB.",
+		},
+		DocsLink: docs,
+	})
+}
+
+// roundUp rounds v up to a multiple of step.
+func roundUp(v, step float64) float64 {
+	return math.Ceil(v/step-1e-9) * step
+}
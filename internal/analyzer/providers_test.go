@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestProviderChecks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) int64 { return now.AddDate(0, 0, -d).Unix() }
+
+	cases := []struct {
+		name   string
+		res    models.CloudResource
+		action string
+		saving float64
+	}{
+		{"busy GCE instance", &models.GCEInstance{ID: "gce-1", CPUUsage: 50, CostPerHour: 0.1, LastActive: daysAgo(1)}, "", 0},
+		{"underused GCE instance", &models.GCEInstance{ID: "gce-2", CPUUsage: 2, CostPerHour: 0.1}, "Resize or terminate", 72},
+		{"idle Azure VM", &models.AzureVM{ID: "azvm-1", CPUUsage: 50, CostPerHour: 0.1, LastActive: daysAgo(40)}, "Terminate", 72},
+		{"cold standard bucket", &models.GCSBucket{ID: "gcs-1", Region: "us-central1", StorageClass: "standard", UsedGB: 1000, CostPerGBMonth: 0.02, LastAccessed: daysAgo(120)}, "Change storage class", 16},
+		{"cold coldline bucket", &models.GCSBucket{ID: "gcs-2", Region: "us-central1", StorageClass: "coldline", UsedGB: 1000, CostPerGBMonth: 0.004, LastAccessed: daysAgo(120)}, "", 0},
+		{"warm hot container", &models.AzureBlobContainer{ID: "blob-1", Region: "eastus", AccessTier: "hot", UsedGB: 1000, CostPerGBMonth: 0.0184, LastAccessed: daysAgo(1)}, "", 0},
+		{"cold hot container", &models.AzureBlobContainer{ID: "blob-2", Region: "eastus", AccessTier: "hot", UsedGB: 1000, CostPerGBMonth: 0.0184, LastAccessed: daysAgo(120)}, "Change storage class", 14.8},
+		{"quiet Cloud SQL instance", &models.CloudSQLInstance{ID: "sql-1", VCPU: 4, CPUUsage: 30, Connections: 2, CostPerHour: 0.4}, "Downsize instance", 144},
+		{"busy Azure SQL database", &models.AzureSQLDatabase{ID: "azsql-1", VCores: 4, CPUUsage: 90, Connections: 50, CostPerHour: 0.5}, "Optimize or upgrade", 0},
+		{"expensive scans", &models.BigQueryDataset{ID: "bq-1", TBScannedPerDay: 8, CostPerTBScanned: 6.25, LastQueried: daysAgo(0)}, "Reduce bytes scanned", 750},
+		{"unqueried dataset", &models.BigQueryDataset{ID: "bq-2", StoredGB: 1000, StorageCostPerGBMonth: 0.02, CostPerTBScanned: 6.25, LastQueried: daysAgo(100)}, "Archive or delete", 20},
+		// 600 RU/s plus 20% headroom rounds up to 800.
+		{"over-provisioned Cosmos DB", &models.CosmosDBAccount{ID: "cosmos-1", ProvisionedRUs: 4000, ConsumedRUs: 600, CostPer100RUHour: 0.008}, "Reduce provisioned throughput", 184.32},
+		{"well used Cosmos DB", &models.CosmosDBAccount{ID: "cosmos-2", ProvisionedRUs: 1000, ConsumedRUs: 800, CostPer100RUHour: 0.008}, "", 0},
+		// Storage is priced in its own region, and in the default one when it
+		// records none.
+		{"cold storage", &models.Storage{ID: "st-1", UsedGB: 500, CostPerGB: 0.023, LastAccessed: daysAgo(120)}, "Archive or delete", 9.7},
+		{"cold storage in an unpriced region", &models.Storage{ID: "st-2", Region: "ap-south-1", UsedGB: 500, CostPerGB: 0.023, LastAccessed: daysAgo(120)}, "Archive or delete", 0},
+		{"minimum Cosmos DB", &models.CosmosDBAccount{ID: "cosmos-3", ProvisionedRUs: 400, ConsumedRUs: 10, CostPer100RUHour: 0.008}, "", 0},
+	}
+	for _, c := range cases {
+		sink := &InMemorySuggestionSink{}
+		AnalyzeWith(c.res, sink, Env{Rules: DefaultRules(), Now: now})
+		var got []Suggestion
+		for _, s := range sink.GetSuggestions() {
+			if s.Action != "Fix tags" {
+				got = append(got, s)
+			}
+		}
+		if c.action == "" {
+			if len(got) != 0 {
+				t.Errorf("%s: unexpected suggestions %+v", c.name, got)
+			}
+			continue
+		}
+		if len(got) != 1 || got[0].Action != c.action {
+			t.Errorf("%s: got %+v, want %q", c.name, got, c.action)
+			continue
+		}
+		if d := got[0].EstimatedSavingsUSD - c.saving; d > 1e-9 || d < -1e-9 {
+			t.Errorf("%s: savings = %v, want %v", c.name, got[0].EstimatedSavingsUSD, c.saving)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/chanducheryala/cloud-resource/internal/sim"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -20,6 +21,9 @@ func NewRedisSuggestionSink(client *redis.Client, key string) *RedisSuggestionSi
 
 func (r *RedisSuggestionSink) AddSuggestion(sug Suggestion) {
 	ctx := context.Background()
+	if sug.Timestamp.IsZero() {
+		sug.Timestamp = sim.Now()
+	}
 	b, _ := json.Marshal(sug)
 
 	exists, _ := r.Client.Exists(ctx, r.Key).Result()
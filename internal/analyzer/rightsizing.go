@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+)
+
+// Rightsizing is a recommendation to move a resource to a smaller SKU.
+type Rightsizing struct {
+	Current        pricing.InstancePrice
+	Recommended    pricing.InstancePrice
+	CPUPercentile  float64
+	MemPercentile  float64
+	Samples        int
+	NeededVCPU     float64
+	NeededMemory   float64
+	MonthlySavings float64
+}
+
+// recommendSize returns the cheapest SKU of the same service and engine in
+// region that covers the observed CPU and memory percentiles of id plus the
+// configured headroom, if one is cheaper than typ. vcpu and memGiB are the
+// resource's current size; when zero, the catalog's figures for typ are used.
+func recommendSize(env Env, id, service, engine, typ, region string, vcpu int, memGiB float64) (Rightsizing, bool) {
+	if env.History == nil {
+		return Rightsizing{}, false
+	}
+	rules := env.Rules
+	c := CurrentCatalog()
+	current, ok := c.Instance(service, engine, typ, region)
+	if !ok || current.OnDemandHourly == 0 {
+		return Rightsizing{}, false
+	}
+	if vcpu == 0 {
+		vcpu = current.VCPU
+	}
+	if memGiB == 0 {
+		memGiB = current.MemoryGiB
+	}
+
+	cpu, n := env.History.Percentile(id, models.MetricCPU, rules.RightsizingPercentile)
+	mem, m := env.History.Percentile(id, models.MetricMemory, rules.RightsizingPercentile)
+	if n < rules.RightsizingMinSamples || m < rules.RightsizingMinSamples {
+		return Rightsizing{}, false
+	}
+	rs := Rightsizing{
+		Current:       current,
+		CPUPercentile: cpu,
+		MemPercentile: mem,
+		Samples:       n,
+		NeededVCPU:    float64(vcpu) * cpu / 100 * (1 + rules.RightsizingHeadroom),
+		NeededMemory:  memGiB * mem / 100 * (1 + rules.RightsizingHeadroom),
+	}
+
+	found := false
+	for _, p := range c.Instances {
+		if p.Service != service || p.Engine != engine || p.Region != region || p.OnDemandHourly == 0 {
+			continue
+		}
+		if float64(p.VCPU) < rs.NeededVCPU || p.MemoryGiB < rs.NeededMemory || p.OnDemandHourly >= current.OnDemandHourly {
+			continue
+		}
+		// Ties go to the lexically smaller type so the choice is stable.
+		if !found || p.OnDemandHourly < rs.Recommended.OnDemandHourly ||
+			(p.OnDemandHourly == rs.Recommended.OnDemandHourly && p.Type < rs.Recommended.Type) {
+			rs.Recommended = p
+			found = true
+		}
+	}
+	if !found {
+		return Rightsizing{}, false
+	}
+	rs.MonthlySavings, _ = pricing.MonthlySavings(current, pricing.OnDemand, rs.Recommended, pricing.OnDemand)
+	return rs, true
+}
+
+func (rs Rightsizing) details(owner string) map[string]interface{} {
+	return map[string]interface{}{
+		"owner":             owner,
+		"region":            rs.Current.Region,
+		"current_type":      rs.Current.Type,
+		"recommended_type":  rs.Recommended.Type,
+		"cpu_percentile":    round2(rs.CPUPercentile),
+		"mem_percentile":    round2(rs.MemPercentile),
+		"needed_vcpu":       round2(rs.NeededVCPU),
+		"needed_memory_gib": round2(rs.NeededMemory),
+		"samples":           rs.Samples,
+		"business_impact":   "Observed peak usage fits a smaller instance; rightsizing cuts spend without losing headroom.",
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
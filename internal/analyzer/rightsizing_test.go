@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestRightsizingPicksCheapestFittingInstance(t *testing.T) {
+	vm := &models.VM{ID: "vm-x", InstanceType: "m5.xlarge", VCPU: 4, MemoryGiB: 16, Region: "us-east-1", CostPerHour: 0.192, Owner: "team-a"}
+	env := Env{Rules: DefaultRules(), History: history.NewStore(0)}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	resizes := func(sink *InMemorySuggestionSink) []Suggestion {
+		var out []Suggestion
+		for _, s := range sink.GetSuggestions() {
+			if s.Action == "Resize down" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+
+	// 30% of 4 vCPU and 40% of 16 GiB, plus 20% headroom, need 1.44 vCPU and
+	// 7.68 GiB: t3.large is the cheapest SKU with 2 vCPU and 8 GiB.
+	var sink *InMemorySuggestionSink
+	for i := 1; i <= env.Rules.RightsizingMinSamples; i++ {
+		if sink != nil && len(resizes(sink)) != 0 {
+			t.Fatalf("recommended a resize after %d samples", i-1)
+		}
+		vm.CPUUsage, vm.MemoryUsage = 30, 40
+		env.Now = start.Add(time.Duration(i) * time.Minute)
+		sink = &InMemorySuggestionSink{}
+		AnalyzeWith(vm, sink, env)
+	}
+	got := resizes(sink)
+	if len(got) != 1 {
+		t.Fatalf("got %d resize suggestions, want 1", len(got))
+	}
+	if typ := got[0].Details["recommended_type"]; typ != "t3.large" {
+		t.Errorf("recommended_type = %v, want t3.large", typ)
+	}
+	if want := (0.192 - 0.0832) * 24 * 30; math.Abs(got[0].EstimatedSavingsUSD-want) > 1e-9 {
+		t.Errorf("savings = %v, want %v", got[0].EstimatedSavingsUSD, want)
+	}
+
+	// A busy instance has nothing cheaper that fits.
+	busy := &models.VM{ID: "vm-busy", InstanceType: "m5.xlarge", VCPU: 4, MemoryGiB: 16, Region: "us-east-1", CostPerHour: 0.192}
+	for i := 0; i < 20; i++ {
+		busy.CPUUsage, busy.MemoryUsage = 85, 40
+		sink = &InMemorySuggestionSink{}
+		AnalyzeWith(busy, sink, env)
+	}
+	if got := resizes(sink); len(got) != 0 {
+		t.Errorf("busy instance got resize suggestion: %+v", got)
+	}
+}
+
+func TestUnderusedVMGetsOneResize(t *testing.T) {
+	vm := &models.VM{ID: "vm-idle", InstanceType: "m5.2xlarge", VCPU: 8, MemoryGiB: 32, Region: "us-east-1", CostPerHour: 0.384, Owner: "team-a"}
+	env := Env{Rules: DefaultRules(), History: history.NewStore(0)}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sink *InMemorySuggestionSink
+	for i := 0; i < 200; i++ {
+		vm.CPUUsage, vm.MemoryUsage = 2, 5
+		env.Now = start.Add(time.Duration(i) * time.Hour)
+		sink = &InMemorySuggestionSink{}
+		AnalyzeWith(vm, sink, env)
+	}
+
+	var resizes []Suggestion
+	for _, s := range sink.GetSuggestions() {
+		if s.Action == "Resize down" || s.Action == "Resize or terminate" {
+			resizes = append(resizes, s)
+		}
+	}
+	if len(resizes) != 1 {
+		t.Fatalf("got %d resize suggestions, want 1: %+v", len(resizes), resizes)
+	}
+	if resizes[0].Action != "Resize or terminate" || resizes[0].Details["recommended_type"] == nil {
+		t.Errorf("resize suggestion = %+v, want Resize or terminate with a recommended type", resizes[0])
+	}
+}
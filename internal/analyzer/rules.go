@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules holds the thresholds used by the checks in Analyze. A rules file only
+// needs to list the values it changes; everything else keeps its default.
+type Rules struct {
+	LambdaMaxErrorRate      float64 `yaml:"lambda_max_error_rate" json:"lambda_max_error_rate"`
+	LambdaMinInvocations    int     `yaml:"lambda_min_invocations" json:"lambda_min_invocations"`
+	LambdaMaxCostPerMillion float64 `yaml:"lambda_max_cost_per_million" json:"lambda_max_cost_per_million"`
+
+	DynamoDBMaxCapacity  int     `yaml:"dynamodb_max_capacity" json:"dynamodb_max_capacity"`
+	DynamoDBMaxItems     int     `yaml:"dynamodb_max_items" json:"dynamodb_max_items"`
+	DynamoDBMaxCostPerHr float64 `yaml:"dynamodb_max_cost_per_hr" json:"dynamodb_max_cost_per_hr"`
+
+	S3MaxUsedGB    float64 `yaml:"s3_max_used_gb" json:"s3_max_used_gb"`
+	S3MaxObjects   int     `yaml:"s3_max_objects" json:"s3_max_objects"`
+	S3MaxCostPerGB float64 `yaml:"s3_max_cost_per_gb" json:"s3_max_cost_per_gb"`
+
+	ELBMinRequests       int     `yaml:"elb_min_requests" json:"elb_min_requests"`
+	ELBMinHealthyHosts   int     `yaml:"elb_min_healthy_hosts" json:"elb_min_healthy_hosts"`
+	ELBMaxCostPerRequest float64 `yaml:"elb_max_cost_per_request" json:"elb_max_cost_per_request"`
+
+	// CostSpikeRatio is how much the current cost may exceed the previous cost
+	// before a spike is reported (1.5 = +50%). Shared by VM, Storage and Database.
+	CostSpikeRatio float64 `yaml:"cost_spike_ratio" json:"cost_spike_ratio"`
+
+	VMMinCPU         float64 `yaml:"vm_min_cpu" json:"vm_min_cpu"`
+	VMIdleDays       int     `yaml:"vm_idle_days" json:"vm_idle_days"`
+	VMMaxCostPerHour float64 `yaml:"vm_max_cost_per_hour" json:"vm_max_cost_per_hour"`
+
+	StorageMinUsedGB    float64 `yaml:"storage_min_used_gb" json:"storage_min_used_gb"`
+	StorageMaxUsedGB    float64 `yaml:"storage_max_used_gb" json:"storage_max_used_gb"`
+	StorageIdleDays     int     `yaml:"storage_idle_days" json:"storage_idle_days"`
+	StorageMaxCostPerGB float64 `yaml:"storage_max_cost_per_gb" json:"storage_max_cost_per_gb"`
+
+	DatabaseMinConnections int     `yaml:"database_min_connections" json:"database_min_connections"`
+	DatabaseMaxConnections int     `yaml:"database_max_connections" json:"database_max_connections"`
+	DatabaseMaxCPU         float64 `yaml:"database_max_cpu" json:"database_max_cpu"`
+
+	// Rightsizing recommends the cheapest catalog SKU whose vCPU and memory
+	// cover the RightsizingPercentile of observed usage plus RightsizingHeadroom
+	// (0.2 = 20% spare), once at least RightsizingMinSamples have been seen.
+	RightsizingPercentile float64 `yaml:"rightsizing_percentile" json:"rightsizing_percentile"`
+	RightsizingHeadroom   float64 `yaml:"rightsizing_headroom" json:"rightsizing_headroom"`
+	RightsizingMinSamples int     `yaml:"rightsizing_min_samples" json:"rightsizing_min_samples"`
+
+	// Spot suitability: a VM is suggested for spot when its score (0-100) is
+	// at least SpotMinScore and its instance type's monthly interruption rate
+	// does not exceed SpotMaxInterruptionRate. Usage volatility only counts
+	// once SpotMinSamples CPU samples have been recorded.
+	SpotMinScore            float64 `yaml:"spot_min_score" json:"spot_min_score"`
+	SpotMaxInterruptionRate float64 `yaml:"spot_max_interruption_rate" json:"spot_max_interruption_rate"`
+	SpotMinSamples          int     `yaml:"spot_min_samples" json:"spot_min_samples"`
+
+	// Kubernetes node groups: workloads are over-requested when the
+	// RightsizingPercentile of their CPU or memory usage, as a percentage of
+	// what they request, is below K8sMaxRequestUtilization once K8sMinSamples
+	// have been recorded. A group without pods for K8sIdleHours is idle, and a
+	// group is poorly bin-packed when its requests fit on fewer nodes filled to
+	// K8sBinPackingTarget percent of allocatable capacity.
+	K8sMaxRequestUtilization float64 `yaml:"k8s_max_request_utilization" json:"k8s_max_request_utilization"`
+	K8sMinSamples            int     `yaml:"k8s_min_samples" json:"k8s_min_samples"`
+	K8sIdleHours             int     `yaml:"k8s_idle_hours" json:"k8s_idle_hours"`
+	K8sBinPackingTarget      float64 `yaml:"k8s_bin_packing_target" json:"k8s_bin_packing_target"`
+
+	// Orphans: volumes detached for EBSUnattachedDays and snapshots at least
+	// EBSSnapshotMinAgeDays old whose volume no longer exists. NAT gateways
+	// processing less than NATMinGBPerHour are not worth their hourly charge.
+	EBSUnattachedDays     int     `yaml:"ebs_unattached_days" json:"ebs_unattached_days"`
+	EBSSnapshotMinAgeDays int     `yaml:"ebs_snapshot_min_age_days" json:"ebs_snapshot_min_age_days"`
+	NATMinGBPerHour       float64 `yaml:"nat_min_gb_per_hour" json:"nat_min_gb_per_hour"`
+
+	// GCP and Azure compute, SQL and object storage reuse the VM, Database and
+	// Storage thresholds above. BigQuery datasets whose scans cost more than
+	// BigQueryMaxScanCostPerDay are flagged, as are Cosmos DB accounts using
+	// less than CosmosMinRUUtilization percent of their provisioned RU/s.
+	BigQueryMaxScanCostPerDay float64 `yaml:"bigquery_max_scan_cost_per_day" json:"bigquery_max_scan_cost_per_day"`
+	CosmosMinRUUtilization    float64 `yaml:"cosmos_min_ru_utilization" json:"cosmos_min_ru_utilization"`
+}
+
+// DefaultRules returns the built-in thresholds.
+func DefaultRules() Rules {
+	return Rules{
+		LambdaMaxErrorRate:      0.05,
+		LambdaMinInvocations:    100,
+		LambdaMaxCostPerMillion: 0.25,
+
+		DynamoDBMaxCapacity:  20,
+		DynamoDBMaxItems:     1000000,
+		DynamoDBMaxCostPerHr: 0.25,
+
+		S3MaxUsedGB:    1000,
+		S3MaxObjects:   1000000,
+		S3MaxCostPerGB: 0.03,
+
+		ELBMinRequests:       1000,
+		ELBMinHealthyHosts:   2,
+		ELBMaxCostPerRequest: 0.00005,
+
+		CostSpikeRatio: 1.5,
+
+		VMMinCPU:         10.0,
+		VMIdleDays:       30,
+		VMMaxCostPerHour: 0.5,
+
+		StorageMinUsedGB:    1.0,
+		StorageMaxUsedGB:    900.0,
+		StorageIdleDays:     90,
+		StorageMaxCostPerGB: 0.10,
+
+		DatabaseMinConnections: 5,
+		DatabaseMaxConnections: 150,
+		DatabaseMaxCPU:         70.0,
+
+		RightsizingPercentile: 95,
+		RightsizingHeadroom:   0.2,
+		RightsizingMinSamples: 10,
+
+		SpotMinScore:            60,
+		SpotMaxInterruptionRate: 0.15,
+		SpotMinSamples:          10,
+
+		K8sMaxRequestUtilization: 50,
+		K8sMinSamples:            10,
+		K8sIdleHours:             24,
+		K8sBinPackingTarget:      80,
+
+		EBSUnattachedDays:     7,
+		EBSSnapshotMinAgeDays: 30,
+		NATMinGBPerHour:       1,
+
+		BigQueryMaxScanCostPerDay: 25,
+		CosmosMinRUUtilization:    30,
+	}
+}
+
+// LoadRules reads a YAML (or JSON) rules file on top of DefaultRules.
+func LoadRules(path string) (Rules, error) {
+	rules := DefaultRules()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return rules, fmt.Errorf("parse rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+var (
+	rulesMu     sync.RWMutex
+	activeRules = DefaultRules()
+)
+
+// SetRules replaces the thresholds used by Analyze and AnalyzeResource.
+func SetRules(r Rules) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	activeRules = r
+}
+
+func CurrentRules() Rules {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return activeRules
+}
+
+// num renders a threshold for a suggestion message without trailing zeros or
+// float noise (0.07*100 prints as 7, not 7.000000000000001).
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
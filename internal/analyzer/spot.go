@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+	"github.com/chanducheryala/cloud-resource/internal/spot"
+)
+
+// Tag keys or values that mark a VM as safe to interrupt, and ones that rule it out.
+var (
+	spotFriendlyTags = map[string]bool{"stateless": true, "batch": true, "ci": true}
+	spotHostileTags  = map[string]bool{"stateful": true}
+)
+
+// Weights of the three parts of the spot score; they add up to 100.
+const (
+	spotTagWeight          = 40
+	spotVolatilityWeight   = 30
+	spotInterruptionWeight = 30
+)
+
+// SpotAssessment is how well a VM would tolerate running on spot capacity.
+type SpotAssessment struct {
+	Score            float64
+	WorkloadTags     []string
+	CPUVolatility    float64
+	Samples          int
+	Outlook          spot.Entry
+	InterruptionRate float64
+	OnDemandHourly   float64
+	SpotHourly       float64
+	MonthlySavings   float64
+}
+
+// assessSpot scores vm for spot suitability. Tags that mark the workload as
+// stateless, batch or CI give up to 40 points, CPU volatility (coefficient of
+// variation of the recorded samples) up to 30, and headroom under the
+// tolerated interruption rate up to 30. It returns false when the VM is tagged
+// stateful, its type is not in the spot dataset, or interruptions are more
+// frequent than the rules tolerate.
+func assessSpot(env Env, vm *models.VM) (SpotAssessment, bool) {
+	rules := env.Rules
+	var a SpotAssessment
+	for k, v := range vm.Tags {
+		k, v = strings.ToLower(k), strings.ToLower(v)
+		if spotHostileTags[k] || spotHostileTags[v] {
+			return a, false
+		}
+		if spotFriendlyTags[v] {
+			a.WorkloadTags = append(a.WorkloadTags, v)
+		} else if spotFriendlyTags[k] {
+			a.WorkloadTags = append(a.WorkloadTags, k)
+		}
+	}
+	sort.Strings(a.WorkloadTags)
+
+	outlook, ok := spot.CurrentDataset().Lookup(vm.Region, vm.InstanceType)
+	if !ok || rules.SpotMaxInterruptionRate <= 0 {
+		return a, false
+	}
+	a.Outlook = outlook
+	a.InterruptionRate = outlook.InterruptionRate()
+	if a.InterruptionRate > rules.SpotMaxInterruptionRate {
+		return a, false
+	}
+
+	a.OnDemandHourly = vm.CostPerHour
+	if p, ok := CurrentCatalog().Instance("ec2", "", vm.InstanceType, vm.Region); ok {
+		if p.OnDemandHourly > 0 {
+			a.OnDemandHourly = p.OnDemandHourly
+		}
+		a.SpotHourly = p.SpotHourly
+	}
+	if a.SpotHourly == 0 {
+		a.SpotHourly = a.OnDemandHourly * (1 - outlook.SavingsPercent/100)
+	}
+	a.MonthlySavings = math.Max(0, (a.OnDemandHourly-a.SpotHourly)*pricing.HoursPerMonth)
+
+	if len(a.WorkloadTags) > 0 {
+		a.Score += spotTagWeight
+	}
+	if env.History != nil {
+		cpu := env.History.Values(vm.ID, models.MetricCPU)
+		a.Samples = len(cpu)
+		if a.Samples >= rules.SpotMinSamples {
+			a.CPUVolatility = coefficientOfVariation(cpu)
+			a.Score += spotVolatilityWeight * math.Min(a.CPUVolatility, 1)
+		}
+	}
+	a.Score += spotInterruptionWeight * (1 - a.InterruptionRate/rules.SpotMaxInterruptionRate)
+	a.Score = round2(a.Score)
+	return a, true
+}
+
+func (a SpotAssessment) details(vm *models.VM) map[string]interface{} {
+	return map[string]interface{}{
+		"spot_score":         a.Score,
+		"interruption_rate":  a.InterruptionRate,
+		"interruption_range": a.Outlook.Interruption,
+		"cpu_volatility":     round2(a.CPUVolatility),
+		"samples":            a.Samples,
+		"workload_tags":      a.WorkloadTags,
+		"on_demand_hourly":   a.OnDemandHourly,
+		"spot_hourly":        math.Round(a.SpotHourly*1e4) / 1e4,
+		"region":             vm.Region,
+		"current_type":       vm.InstanceType,
+		"owner":              vm.Owner,
+		"business_impact":    "Interruption-tolerant workload; spot capacity cuts compute spend at the cost of occasional restarts.",
+	}
+}
+
+// coefficientOfVariation is the standard deviation of values over their mean,
+// or 0 when the mean is 0.
+func coefficientOfVariation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq/float64(len(values))) / mean
+}
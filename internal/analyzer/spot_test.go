@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestSpotSuitability(t *testing.T) {
+	env := Env{Rules: DefaultRules(), History: history.NewStore(0)}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	spotSuggestions := func(vm *models.VM, cpu func(i int) float64) []Suggestion {
+		var sink *InMemorySuggestionSink
+		for i := 0; i < env.Rules.SpotMinSamples; i++ {
+			vm.CPUUsage, vm.MemoryUsage = cpu(i), 50
+			env.Now = start.Add(time.Duration(i) * time.Minute)
+			sink = &InMemorySuggestionSink{}
+			AnalyzeWith(vm, sink, env)
+		}
+		var out []Suggestion
+		for _, s := range sink.GetSuggestions() {
+			if s.Action == "Move to spot" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	bursty := func(i int) float64 {
+		if i%2 == 0 {
+			return 90
+		}
+		return 15
+	}
+	steady := func(int) float64 { return 50 }
+
+	// A batch VM with bursty usage on a type that is rarely interrupted.
+	batch := &models.VM{ID: "vm-batch", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096, Owner: "team-a", Tags: map[string]string{"workload": "batch"}}
+	got := spotSuggestions(batch, bursty)
+	if len(got) != 1 {
+		t.Fatalf("got %d spot suggestions for a batch VM, want 1", len(got))
+	}
+	d := got[0].Details
+	if d["interruption_range"] != "5-10%" || d["interruption_rate"] != 0.10 {
+		t.Errorf("interruption = %v / %v, want 5-10%% / 0.1", d["interruption_range"], d["interruption_rate"])
+	}
+	if score := d["spot_score"].(float64); score < env.Rules.SpotMinScore || score > 100 {
+		t.Errorf("spot_score = %v", score)
+	}
+	if got[0].EstimatedSavingsUSD <= 0 {
+		t.Errorf("savings = %v, want > 0", got[0].EstimatedSavingsUSD)
+	}
+
+	// Untagged, steady usage: only interruption headroom counts, below the bar.
+	web := &models.VM{ID: "vm-web", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096}
+	if got := spotSuggestions(web, steady); len(got) != 0 {
+		t.Errorf("steady untagged VM got spot suggestion: %+v", got)
+	}
+
+	// Stateful workloads are never suggested, whatever else they score.
+	db := &models.VM{ID: "vm-db", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096, Tags: map[string]string{"role": "stateful", "ci": "true"}}
+	if got := spotSuggestions(db, bursty); len(got) != 0 {
+		t.Errorf("stateful VM got spot suggestion: %+v", got)
+	}
+
+	// Interruptions above the tolerated rate rule a type out.
+	env.Rules.SpotMaxInterruptionRate = 0.05
+	if got := spotSuggestions(&models.VM{ID: "vm-batch-2", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096, Tags: map[string]string{"workload": "batch"}}, bursty); len(got) != 0 {
+		t.Errorf("VM above tolerated interruption rate got spot suggestion: %+v", got)
+	}
+}
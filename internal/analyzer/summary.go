@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// RuleSummary aggregates the suggestions raised by one check, identified by
+// resource type and action. A check fires again on every analysis, so
+// EstimatedSavingsUSD counts each resource once, using its latest estimate.
+type RuleSummary struct {
+	ResourceType        string    `json:"resource_type"`
+	Action              string    `json:"action"`
+	Count               int       `json:"count"`
+	Resources           []string  `json:"resources"`
+	EstimatedSavingsUSD float64   `json:"estimated_savings_usd"`
+	FirstAt             time.Time `json:"first_at"`
+	FirstAfter          string    `json:"first_after"`
+	LastAt              time.Time `json:"last_at"`
+}
+
+// SummarizeRules groups suggestions by check. FirstAfter is measured from start.
+func SummarizeRules(suggestions []Suggestion, start time.Time) []RuleSummary {
+	type key struct{ resourceType, action string }
+	byRule := make(map[key]*RuleSummary)
+	latest := make(map[key]map[string]Suggestion)
+	for _, s := range suggestions {
+		k := key{s.ResourceType, s.Action}
+		rs, ok := byRule[k]
+		if !ok {
+			rs = &RuleSummary{ResourceType: s.ResourceType, Action: s.Action, FirstAt: s.Timestamp, LastAt: s.Timestamp}
+			byRule[k] = rs
+			latest[k] = make(map[string]Suggestion)
+		}
+		rs.Count++
+		if s.Timestamp.Before(rs.FirstAt) {
+			rs.FirstAt = s.Timestamp
+		}
+		if s.Timestamp.After(rs.LastAt) {
+			rs.LastAt = s.Timestamp
+		}
+		prev, ok := latest[k][s.ResourceID]
+		if !ok {
+			rs.Resources = append(rs.Resources, s.ResourceID)
+		}
+		if !ok || !s.Timestamp.Before(prev.Timestamp) {
+			latest[k][s.ResourceID] = s
+		}
+	}
+
+	rules := make([]RuleSummary, 0, len(byRule))
+	for k, rs := range byRule {
+		sort.Strings(rs.Resources)
+		for _, id := range rs.Resources {
+			rs.EstimatedSavingsUSD += latest[k][id].EstimatedSavingsUSD
+		}
+		rs.FirstAfter = rs.FirstAt.Sub(start).String()
+		rules = append(rules, *rs)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].ResourceType != rules[j].ResourceType {
+			return rules[i].ResourceType < rules[j].ResourceType
+		}
+		return rules[i].Action < rules[j].Action
+	})
+	return rules
+}
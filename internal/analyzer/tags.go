@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/tagpolicy"
+)
+
+// checkTags raises one suggestion listing every tag of resource that the
+// current tag policy reports as missing or invalid.
+func checkTags(resource models.CloudResource, sink SuggestionSink, now time.Time) {
+	violations := tagpolicy.CurrentPolicy().Check(resource)
+	if len(violations) == 0 {
+		return
+	}
+	var missing, invalid []string
+	for _, v := range violations {
+		if v.Reason == tagpolicy.Missing {
+			missing = append(missing, v.Tag)
+		} else {
+			invalid = append(invalid, v.Tag+"="+v.Value)
+		}
+	}
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "is missing required tags: "+strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		problems = append(problems, "has invalid tag values: "+strings.Join(invalid, ", "))
+	}
+	sink.AddSuggestion(Suggestion{
+		ResourceID:   resource.GetId(),
+		ResourceType: resource.GetType(),
+		Message:      resource.GetType() + " '" + resource.GetId() + "' " + strings.Join(problems, " and ") + ". Fix its tags so its spend can be attributed.",
+		Severity:     "Warning",
+		Priority:     2,
+		Timestamp:    now,
+		Action:       "Fix tags",
+		Details: map[string]interface{}{
+			"owner":           resource.GetOwner(),
+			"violations":      violations,
+			"tags":            resource.GetTags(),
+			"business_impact": "Untagged or mis-tagged spend cannot be charged back or governed by tag-based budgets.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/tag-editor/latest/userguide/tagging.html",
+	})
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+// tenantSink files suggestions that do not name a tenant under the tenant of
+// the resource being analyzed.
+type tenantSink struct {
+	SuggestionSink
+	tenant string
+}
+
+func (s tenantSink) AddSuggestion(sug Suggestion) {
+	if sug.Tenant == "" {
+		sug.Tenant = s.tenant
+	}
+	s.SuggestionSink.AddSuggestion(sug)
+}
+
+// TenantSinks keeps each tenant's suggestions in a sink of its own, created by
+// New on first use, so reading or clearing one tenant's suggestions never
+// touches another's. Suggestions without a tenant belong to tenant.Default.
+type TenantSinks struct {
+	New func(tenantID string) SuggestionSink
+
+	mu    sync.Mutex
+	sinks map[string]SuggestionSink
+}
+
+// For returns the sink holding tenantID's suggestions.
+func (t *TenantSinks) For(tenantID string) SuggestionSink {
+	tenantID = tenant.Or(tenantID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sinks == nil {
+		t.sinks = make(map[string]SuggestionSink)
+	}
+	s, ok := t.sinks[tenantID]
+	if !ok {
+		s = t.New(tenantID)
+		t.sinks[tenantID] = s
+	}
+	return s
+}
+
+func (t *TenantSinks) AddSuggestion(sug Suggestion) {
+	sug.Tenant = tenant.Or(sug.Tenant)
+	t.For(sug.Tenant).AddSuggestion(sug)
+}
+
+// GetSuggestions returns every tenant's suggestions, for service-wide uses
+// such as snapshots.
+func (t *TenantSinks) GetSuggestions() []Suggestion {
+	var out []Suggestion
+	for _, id := range t.tenants() {
+		out = append(out, t.For(id).GetSuggestions()...)
+	}
+	return out
+}
+
+// ClearSuggestions clears every tenant's suggestions.
+func (t *TenantSinks) ClearSuggestions() error {
+	for _, id := range t.tenants() {
+		if err := t.For(id).ClearSuggestions(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetSuggestions replaces every tenant's suggestions, e.g. when restoring a
+// snapshot. Tenants whose sink cannot be set, such as Redis, keep theirs.
+func (t *TenantSinks) SetSuggestions(suggestions []Suggestion) {
+	byTenant := make(map[string][]Suggestion)
+	for _, id := range t.tenants() {
+		byTenant[id] = nil
+	}
+	for _, s := range suggestions {
+		id := tenant.Or(s.Tenant)
+		byTenant[id] = append(byTenant[id], s)
+	}
+	for id, list := range byTenant {
+		if s, ok := t.For(id).(interface{ SetSuggestions([]Suggestion) }); ok {
+			s.SetSuggestions(list)
+		}
+	}
+}
+
+// tenants returns the tenants the configuration lists plus any that already
+// have a sink.
+func (t *TenantSinks) tenants() []string {
+	ids := tenant.CurrentConfig().IDs()
+	t.mu.Lock()
+	for id := range t.sinks {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	sort.Strings(ids)
+	out := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
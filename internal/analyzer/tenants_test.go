@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+func TestTenantSinks(t *testing.T) {
+	cfg, err := tenant.Parse([]byte(`tenants: {analytics: [analytics-prod]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.SetConfig(cfg)
+	defer tenant.SetConfig(tenant.Config{})
+
+	sinks := &TenantSinks{New: func(string) SuggestionSink { return &InMemorySuggestionSink{} }}
+	env := Env{Rules: DefaultRules(), Now: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}
+	AnalyzeWith(&models.GCEInstance{ID: "gce-1", Project: "analytics-prod", CPUUsage: 2, CostPerHour: 0.1}, sinks, env)
+	AnalyzeWith(&models.VM{ID: "vm-1", Account: "111122223333", InstanceType: "t3.medium", CPUUsage: 2, CostPerHour: 0.05}, sinks, env)
+
+	analytics := sinks.For("analytics").GetSuggestions()
+	if len(analytics) == 0 {
+		t.Fatal("no analytics suggestions")
+	}
+	for _, s := range analytics {
+		if s.ResourceID != "gce-1" || s.Tenant != "analytics" {
+			t.Errorf("analytics sink holds %s for tenant %q", s.ResourceID, s.Tenant)
+		}
+	}
+	if err := sinks.For(tenant.Default).ClearSuggestions(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sinks.For(tenant.Default).GetSuggestions(); len(got) != 0 {
+		t.Errorf("default suggestions after clear: %+v", got)
+	}
+	if got := sinks.For("analytics").GetSuggestions(); len(got) != len(analytics) {
+		t.Errorf("clearing the default tenant left %d of %d analytics suggestions", len(got), len(analytics))
+	}
+}
@@ -0,0 +1,153 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+var start = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// wobble is a small deterministic variation so the series has some spread.
+func wobble(i int) float64 {
+	return []float64{0, 0.002, -0.001, 0.001, -0.002}[i%5]
+}
+
+func TestEvaluateEWMA(t *testing.T) {
+	var series []Point
+	for i := 0; i < 30; i++ {
+		series = append(series, Point{Time: start.Add(time.Duration(i) * time.Minute), Value: 0.1 + wobble(i)})
+	}
+	s := DefaultConfig().Default
+
+	normal := append(append([]Point(nil), series...), Point{Time: start.Add(30 * time.Minute), Value: 0.101})
+	if r, ok := Evaluate(normal, s); !ok || r.Anomalous || r.Method != EWMA {
+		t.Errorf("ordinary point: %+v, ok=%v", r, ok)
+	}
+
+	spike := append(append([]Point(nil), series...), Point{Time: start.Add(30 * time.Minute), Value: 0.18})
+	r, ok := Evaluate(spike, s)
+	if !ok || !r.Anomalous {
+		t.Fatalf("spike not flagged: %+v", r)
+	}
+	if r.ZScore < s.ZThreshold || r.Confidence < 0.99 {
+		t.Errorf("z=%v confidence=%v", r.ZScore, r.Confidence)
+	}
+
+	if _, ok := Evaluate(spike[:5], s); ok {
+		t.Error("evaluated a series shorter than min_samples")
+	}
+}
+
+func TestEvaluateSeasonal(t *testing.T) {
+	// Three days of hourly spend that triples during office hours.
+	daily := func(h int) float64 {
+		if h >= 9 && h < 17 {
+			return 0.3
+		}
+		return 0.1
+	}
+	var series []Point
+	for i := 0; i < 72; i++ {
+		tm := start.Add(time.Duration(i) * time.Hour)
+		series = append(series, Point{Time: tm, Value: daily(tm.Hour()) + wobble(i)})
+	}
+	s := DefaultConfig().Default
+
+	noon := start.Add(72*time.Hour + 12*time.Hour)
+	if r, ok := Evaluate(append(series, Point{Time: noon, Value: 0.3}), s); !ok || r.Anomalous || r.Method != Seasonal {
+		t.Errorf("office-hours spend flagged: %+v", r)
+	}
+	night := start.Add(72*time.Hour + 2*time.Hour)
+	if r, ok := Evaluate(append(series, Point{Time: night, Value: 0.3}), s); !ok || !r.Anomalous {
+		t.Errorf("night-time spend at office-hours level not flagged: %+v", r)
+	}
+}
+
+func TestConfigFor(t *testing.T) {
+	c := DefaultConfig()
+	c.Owners = map[string]Sensitivity{"quiet": {ZThreshold: 8}}
+	got := c.For("quiet")
+	if got.ZThreshold != 8 || got.MinDeviation != c.Default.MinDeviation || got.MinSamples != c.Default.MinSamples {
+		t.Errorf("For(quiet) = %+v", got)
+	}
+	if c.For("someone else") != c.Default {
+		t.Error("owner without override did not get the default")
+	}
+}
+
+func TestDetectorOwnerAnomaly(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	hist := history.NewStore(0)
+	vm := &models.VM{ID: "vm-a", Owner: "team"}
+	db := &models.Database{ID: "db-a", Owner: "team"}
+	other := &models.VM{ID: "vm-b", Owner: "quiet"}
+	resources := []models.CloudResource{vm, db, other}
+	for i := 0; i <= 20; i++ {
+		tm := start.Add(time.Duration(i) * time.Minute)
+		vmCost, otherCost := 0.1+wobble(i), 0.1+wobble(i)
+		if i == 20 {
+			vmCost, otherCost = 0.25, 0.25
+		}
+		hist.Record("vm-a", tm, map[string]float64{forecast.HourlyCostMetric: vmCost})
+		hist.Record("db-a", tm, map[string]float64{forecast.HourlyCostMetric: 0.2 + wobble(i+2)})
+		hist.Record("vm-b", tm, map[string]float64{forecast.HourlyCostMetric: otherCost})
+	}
+
+	cfg := DefaultConfig()
+	cfg.Owners = map[string]Sensitivity{"quiet": {ZThreshold: 1000}}
+	SetConfig(cfg)
+
+	sink := &analyzer.InMemorySuggestionSink{}
+	d := &Detector{History: hist, Sink: sink}
+	anomalies := d.Check(resources, start.Add(20*time.Minute))
+
+	var keys []string
+	var owner *Anomaly
+	for i, a := range anomalies {
+		keys = append(keys, a.Scope+":"+a.Key)
+		if a.Scope == ScopeOwner {
+			owner = &anomalies[i]
+		}
+	}
+	if len(anomalies) != 2 || owner == nil {
+		t.Fatalf("anomalies = %v, want resource:vm-a and owner:team", keys)
+	}
+	if len(owner.Contributors) != 1 || owner.Contributors[0].ResourceID != "vm-a" {
+		t.Errorf("contributors = %+v, want vm-a", owner.Contributors)
+	}
+
+	got := sink.GetSuggestions()
+	if len(got) != 2 || got[0].Action != "Review cost anomaly" || got[0].EstimatedSavingsUSD <= 0 {
+		t.Fatalf("suggestions = %+v", got)
+	}
+	d.Check(resources, start.Add(20*time.Minute))
+	if n := len(sink.GetSuggestions()); n != 2 {
+		t.Errorf("second check on the same point raised %d more suggestions", n-2)
+	}
+}
+
+func TestDetectorFiredStaysBounded(t *testing.T) {
+	d := &Detector{}
+	a := Anomaly{Tenant: "default", Scope: ScopeResource, Key: "vm-a"}
+	for i := 0; i < 100; i++ {
+		a.Result.Time = start.Add(time.Duration(i) * time.Minute)
+		if !d.markFired(a) {
+			t.Fatalf("point %d was not raised", i)
+		}
+		if d.markFired(a) {
+			t.Fatalf("point %d was raised twice", i)
+		}
+	}
+	a.Result.Time = start
+	if d.markFired(a) {
+		t.Error("an older point was raised again")
+	}
+	if len(d.fired) != 1 {
+		t.Errorf("fired holds %d keys for one series", len(d.fired))
+	}
+}
@@ -0,0 +1,125 @@
+package anomaly
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sensitivity controls when a cost point counts as anomalous. A point is
+// flagged when it is at least ZThreshold standard deviations from the expected
+// value and differs from it by at least MinDeviation (0.1 = 10%). Alpha is the
+// EWMA smoothing factor; MinSamples is the history a series needs before it
+// is checked.
+type Sensitivity struct {
+	ZThreshold   float64 `yaml:"z_threshold" json:"z_threshold"`
+	MinDeviation float64 `yaml:"min_deviation" json:"min_deviation"`
+	Alpha        float64 `yaml:"alpha" json:"alpha"`
+	MinSamples   int     `yaml:"min_samples" json:"min_samples"`
+}
+
+// Config holds the default sensitivity, per-owner overrides and the width of
+// the buckets cost samples are averaged into before detection.
+type Config struct {
+	Resolution time.Duration          `yaml:"resolution" json:"resolution"`
+	Default    Sensitivity            `yaml:"default" json:"default"`
+	Owners     map[string]Sensitivity `yaml:"owners" json:"owners"`
+}
+
+// DefaultConfig returns the built-in settings.
+func DefaultConfig() Config {
+	return Config{
+		Resolution: time.Minute,
+		Default: Sensitivity{
+			ZThreshold:   3,
+			MinDeviation: 0.1,
+			Alpha:        0.3,
+			MinSamples:   10,
+		},
+	}
+}
+
+// LoadConfig reads a YAML (or JSON) file on top of DefaultConfig. Owner
+// entries only need to list the values they change.
+func LoadConfig(path string) (Config, error) {
+	c := DefaultConfig()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("parse anomaly config %s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c Config) Validate() error {
+	if c.Resolution < 0 {
+		return fmt.Errorf("resolution must not be negative")
+	}
+	check := func(name string, s Sensitivity) error {
+		if s.ZThreshold < 0 || s.MinDeviation < 0 || s.MinSamples < 0 {
+			return fmt.Errorf("%s: thresholds must not be negative", name)
+		}
+		if s.Alpha < 0 || s.Alpha > 1 {
+			return fmt.Errorf("%s: alpha must be between 0 and 1", name)
+		}
+		return nil
+	}
+	if err := check("default", c.Default); err != nil {
+		return err
+	}
+	for owner, s := range c.Owners {
+		if err := check("owner "+owner, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// For returns the sensitivity for owner: its override, with unset fields
+// taken from the default.
+func (c Config) For(owner string) Sensitivity {
+	s := c.Default
+	o, ok := c.Owners[owner]
+	if !ok {
+		return s
+	}
+	if o.ZThreshold > 0 {
+		s.ZThreshold = o.ZThreshold
+	}
+	if o.MinDeviation > 0 {
+		s.MinDeviation = o.MinDeviation
+	}
+	if o.Alpha > 0 {
+		s.Alpha = o.Alpha
+	}
+	if o.MinSamples > 0 {
+		s.MinSamples = o.MinSamples
+	}
+	return s
+}
+
+var (
+	configMu     sync.RWMutex
+	activeConfig = DefaultConfig()
+)
+
+// SetConfig replaces the settings used by the anomaly detector.
+func SetConfig(c Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	activeConfig = c
+}
+
+func CurrentConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return activeConfig
+}
@@ -0,0 +1,176 @@
+// Package anomaly flags unusual spend in per-resource and per-owner cost
+// series using an EWMA z-score, after removing the hour-of-day pattern once
+// there is enough history to estimate it.
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+// Detection methods.
+const (
+	EWMA     = "ewma"
+	Seasonal = "seasonal"
+)
+
+// seasonHours is the period of the seasonal component; it needs two full
+// periods of history before it is used.
+const seasonHours = 24
+
+// minRelativeStd floors the standard deviation at 1% of the expected value so
+// a flat series does not produce an infinite score on its first change.
+const minRelativeStd = 0.01
+
+// Point is one bucket of a cost series, in USD per hour.
+type Point struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// Result is the check of a series' latest point against the model fitted to
+// the points before it.
+type Result struct {
+	Time       time.Time `json:"time"`
+	Expected   float64   `json:"expected"`
+	Actual     float64   `json:"actual"`
+	StdDev     float64   `json:"std_dev"`
+	ZScore     float64   `json:"z_score"`
+	Confidence float64   `json:"confidence"`
+	Method     string    `json:"method"`
+	Samples    int       `json:"samples"`
+	Anomalous  bool      `json:"anomalous"`
+}
+
+// Series returns id's hourly cost averaged into buckets of resolution, oldest
+// first. A zero resolution keeps every sample.
+func Series(store *history.Store, id string, resolution time.Duration) []Point {
+	samples := forecast.HourlyCost(store, id)
+	var points []Point
+	var count int
+	for _, s := range samples {
+		t := s.Time
+		if resolution > 0 {
+			t = t.Truncate(resolution)
+		}
+		if n := len(points); n > 0 && points[n-1].Time.Equal(t) {
+			count++
+			points[n-1].Value += (s.Value - points[n-1].Value) / float64(count)
+			continue
+		}
+		points = append(points, Point{Time: t, Value: s.Value})
+		count = 1
+	}
+	return points
+}
+
+// Sum adds series point by point. A series without a point at some time
+// contributes its latest earlier value, so resources sampled at slightly
+// different times still add up.
+func Sum(series ...[]Point) []Point {
+	seen := make(map[time.Time]bool)
+	var times []time.Time
+	for _, s := range series {
+		for _, p := range s {
+			if !seen[p.Time] {
+				seen[p.Time] = true
+				times = append(times, p.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	out := make([]Point, len(times))
+	next := make([]int, len(series))
+	for i, t := range times {
+		out[i].Time = t
+		for j, s := range series {
+			for next[j] < len(s) && !s[next[j]].Time.After(t) {
+				next[j]++
+			}
+			if next[j] > 0 {
+				out[i].Value += s[next[j]-1].Value
+			}
+		}
+	}
+	return out
+}
+
+// Evaluate checks the last point of series against the points before it. The
+// expected value is an EWMA of the earlier points plus, when they cover two
+// days, the hour-of-day component of a seasonal decomposition. It returns
+// false when there is not enough history.
+func Evaluate(series []Point, s Sensitivity) (Result, bool) {
+	n := len(series) - 1
+	if n < 1 || n < s.MinSamples {
+		return Result{}, false
+	}
+	past, last := series[:n], series[n]
+
+	var seasonal [seasonHours]float64
+	method := EWMA
+	if last.Time.Sub(past[0].Time) >= 2*seasonHours*time.Hour {
+		seasonal = hourOfDay(past)
+		method = Seasonal
+	}
+	alpha := s.Alpha
+	if alpha <= 0 {
+		alpha = DefaultConfig().Default.Alpha
+	}
+	var mean, variance float64
+	for i, p := range past {
+		r := p.Value - seasonal[p.Time.UTC().Hour()]
+		if i == 0 {
+			mean = r
+			continue
+		}
+		d := r - mean
+		mean += alpha * d
+		variance = (1 - alpha) * (variance + alpha*d*d)
+	}
+
+	res := Result{
+		Time:     last.Time,
+		Expected: math.Max(0, mean+seasonal[last.Time.UTC().Hour()]),
+		Actual:   last.Value,
+		StdDev:   math.Sqrt(variance),
+		Method:   method,
+		Samples:  n,
+	}
+	std := math.Max(res.StdDev, minRelativeStd*math.Abs(res.Expected))
+	if std == 0 {
+		// Flat zero spend so far; nothing to compare against.
+		return res, true
+	}
+	res.ZScore = (res.Actual - res.Expected) / std
+	res.Confidence = math.Erf(math.Abs(res.ZScore) / math.Sqrt2)
+	deviation := math.Abs(res.Actual - res.Expected)
+	res.Anomalous = math.Abs(res.ZScore) >= s.ZThreshold && deviation >= s.MinDeviation*math.Abs(res.Expected)
+	return res, true
+}
+
+// hourOfDay estimates the seasonal component: the mean of each hour of the day
+// minus the overall mean.
+func hourOfDay(points []Point) [seasonHours]float64 {
+	var sum [seasonHours]float64
+	var count [seasonHours]int
+	var total float64
+	for _, p := range points {
+		h := p.Time.UTC().Hour()
+		sum[h] += p.Value
+		count[h]++
+		total += p.Value
+	}
+	overall := total / float64(len(points))
+	var idx [seasonHours]float64
+	for h := range idx {
+		if count[h] > 0 {
+			idx[h] = sum[h]/float64(count[h]) - overall
+		}
+	}
+	return idx
+}
@@ -0,0 +1,227 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+// Scopes of a cost series.
+const (
+	ScopeResource = "resource"
+	ScopeOwner    = "owner"
+)
+
+// Anomaly is a flagged point of one resource's or one owner's cost series.
+// Contributors are the resources whose own deviation points the same way,
+// largest first.
+type Anomaly struct {
+	Tenant       string         `json:"tenant"`
+	Scope        string         `json:"scope"`
+	Key          string         `json:"key"`
+	ResourceType string         `json:"resource_type,omitempty"`
+	Owner        string         `json:"owner"`
+	Result       Result         `json:"result"`
+	Contributors []Contribution `json:"contributors"`
+}
+
+// Contribution is one resource's expected and actual hourly cost at the time
+// of an anomaly.
+type Contribution struct {
+	ResourceID string  `json:"resource_id"`
+	Expected   float64 `json:"expected"`
+	Actual     float64 `json:"actual"`
+	Delta      float64 `json:"delta"`
+}
+
+// Detect evaluates the cost series of every resource and of every owner with
+// more than one resource, and returns the anomalous ones. A single-resource
+// owner's series is the resource's own, so it is not reported twice. Owners
+// are grouped within each tenant, so no series mixes tenants.
+func Detect(resources []models.CloudResource, hist *history.Store, cfg Config) []Anomaly {
+	var anomalies []Anomaly
+	for _, id := range tenant.CurrentConfig().IDs() {
+		if rs := tenant.Filter(resources, id); len(rs) > 0 {
+			anomalies = append(anomalies, detect(id, rs, hist, cfg)...)
+		}
+	}
+	return anomalies
+}
+
+func detect(tenantID string, resources []models.CloudResource, hist *history.Store, cfg Config) []Anomaly {
+	type member struct {
+		res    models.CloudResource
+		series []Point
+		result Result
+		ok     bool
+	}
+	byOwner := make(map[string][]*member)
+	var owners []string
+	var anomalies []Anomaly
+	for _, r := range resources {
+		owner := r.GetOwner()
+		m := &member{res: r, series: Series(hist, r.GetId(), cfg.Resolution)}
+		m.result, m.ok = Evaluate(m.series, cfg.For(owner))
+		if m.ok && m.result.Anomalous {
+			anomalies = append(anomalies, Anomaly{
+				Tenant:       tenantID,
+				Scope:        ScopeResource,
+				Key:          r.GetId(),
+				ResourceType: r.GetType(),
+				Owner:        owner,
+				Result:       m.result,
+				Contributors: []Contribution{contribution(r.GetId(), m.result)},
+			})
+		}
+		if owner == "" {
+			continue
+		}
+		if _, ok := byOwner[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		byOwner[owner] = append(byOwner[owner], m)
+	}
+
+	sort.Strings(owners)
+	for _, owner := range owners {
+		members := byOwner[owner]
+		if len(members) < 2 {
+			continue
+		}
+		var series [][]Point
+		for _, m := range members {
+			series = append(series, m.series)
+		}
+		res, ok := Evaluate(Sum(series...), cfg.For(owner))
+		if !ok || !res.Anomalous {
+			continue
+		}
+		a := Anomaly{Tenant: tenantID, Scope: ScopeOwner, Key: owner, Owner: owner, Result: res, Contributors: []Contribution{}}
+		for _, m := range members {
+			c := contribution(m.res.GetId(), m.result)
+			if m.ok && c.Delta*(res.Actual-res.Expected) > 0 {
+				a.Contributors = append(a.Contributors, c)
+			}
+		}
+		sort.Slice(a.Contributors, func(i, j int) bool {
+			return math.Abs(a.Contributors[i].Delta) > math.Abs(a.Contributors[j].Delta)
+		})
+		anomalies = append(anomalies, a)
+	}
+	return anomalies
+}
+
+func contribution(id string, r Result) Contribution {
+	return Contribution{ResourceID: id, Expected: r.Expected, Actual: r.Actual, Delta: r.Actual - r.Expected}
+}
+
+// Detector runs Detect against the current configuration and raises one
+// suggestion per anomalous point.
+type Detector struct {
+	History *history.Store
+	Sink    analyzer.SuggestionSink
+
+	mu sync.Mutex
+	// fired holds the time of the last point raised per tenant, scope and
+	// key, so it grows with the number of series rather than with time.
+	fired map[string]time.Time
+}
+
+// Check detects anomalies at now and returns them.
+func (d *Detector) Check(resources []models.CloudResource, now time.Time) []Anomaly {
+	anomalies := Detect(resources, d.History, CurrentConfig())
+	for _, a := range anomalies {
+		if d.markFired(a) && d.Sink != nil {
+			d.Sink.AddSuggestion(suggestion(a, now))
+		}
+	}
+	return anomalies
+}
+
+// markFired reports whether a is a later point than the last one raised for
+// its series, and records it if so.
+func (d *Detector) markFired(a Anomaly) bool {
+	key := a.Tenant + "|" + a.Scope + "|" + a.Key
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.fired == nil {
+		d.fired = make(map[string]time.Time)
+	}
+	if last, ok := d.fired[key]; ok && !a.Result.Time.After(last) {
+		return false
+	}
+	d.fired[key] = a.Result.Time
+	return true
+}
+
+func suggestion(a Anomaly, now time.Time) analyzer.Suggestion {
+	r := a.Result
+	subject := a.ResourceType + " '" + a.Key + "'"
+	resourceType := a.ResourceType
+	if a.Scope == ScopeOwner {
+		subject = "owner '" + a.Key + "'"
+		resourceType = "Owner"
+	}
+
+	direction, savings := "spike", (r.Actual-r.Expected)*pricing.HoursPerMonth
+	severity, priority := "Warning", 2
+	if r.Actual < r.Expected {
+		direction, savings = "drop", 0
+		severity, priority = "Info", 3
+	} else if math.Abs(r.ZScore) >= 2*CurrentConfig().For(a.Owner).ZThreshold {
+		severity, priority = "Critical", 1
+	}
+	message := fmt.Sprintf("Cost %s for %s: $%.4f/hour against an expected $%.4f/hour (z=%.1f, %.1f%% confidence). Review recent changes.",
+		direction, subject, r.Actual, r.Expected, r.ZScore, 100*r.Confidence)
+
+	return analyzer.Suggestion{
+		ResourceID:          a.Key,
+		ResourceType:        resourceType,
+		Message:             message,
+		EstimatedSavingsUSD: savings,
+		Severity:            severity,
+		Priority:            priority,
+		Timestamp:           now,
+		Action:              "Review cost anomaly",
+		Tenant:              a.Tenant,
+		Details: map[string]interface{}{
+			"scope":                  a.Scope,
+			"owner":                  a.Owner,
+			"direction":              direction,
+			"expected_cost_per_hour": r.Expected,
+			"actual_cost_per_hour":   r.Actual,
+			"z_score":                r.ZScore,
+			"confidence":             r.Confidence,
+			"method":                 r.Method,
+			"samples":                r.Samples,
+			"contributing_resources": a.Contributors,
+			"business_impact":        "Spend departed from its usual pattern; confirm the change was intended.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/cost-anomaly-detection.html",
+	}
+}
+
+// Watch runs Check every interval until ctx is cancelled. resources is called
+// on each check so it sees the current resource list; now supplies the
+// evaluation time (sim.Now in the live service).
+func (d *Detector) Watch(ctx context.Context, interval time.Duration, resources func() []models.CloudResource, now func() time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Check(resources(), now())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package audit keeps a bounded trail of who changed what through the API,
+// and of requests that were refused for lack of valid credentials.
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSize is how many entries Default keeps.
+const DefaultSize = 1000
+
+// Entry is one audited request.
+type Entry struct {
+	// Time is wall-clock time, not simulated time.
+	Time       time.Time `json:"time"`
+	Subject    string    `json:"subject"`
+	AuthMethod string    `json:"auth_method"`
+	Tenant     string    `json:"tenant,omitempty"`
+	// Action is the HTTP method and route, e.g. "POST /api/v1/budgets/:id".
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Remote string `json:"remote"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Log holds the most recent entries, dropping the oldest beyond its size.
+type Log struct {
+	mu      sync.Mutex
+	size    int
+	entries []Entry
+}
+
+func NewLog(size int) *Log {
+	return &Log{size: size}
+}
+
+var defaultLog = NewLog(DefaultSize)
+
+// Default returns the log the API records to.
+func Default() *Log {
+	return defaultLog
+}
+
+func (l *Log) Record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	// Copy only once the slice has doubled, so that a full log does not copy
+	// every entry on every request.
+	if len(l.entries) > 2*l.size {
+		l.entries = append(make([]Entry, 0, 2*l.size), l.entries[len(l.entries)-l.size:]...)
+	}
+}
+
+// window returns the newest l.size entries, of up to twice as many held
+// between compactions.
+func (l *Log) window() []Entry {
+	if len(l.entries) > l.size {
+		return l.entries[len(l.entries)-l.size:]
+	}
+	return l.entries
+}
+
+// Entries returns the entries of tenant, or every entry when tenant is
+// empty, oldest first.
+func (l *Log) Entries(tenant string) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := []Entry{}
+	for _, e := range l.window() {
+		if tenant == "" || e.Tenant == tenant {
+			out = append(out, e)
+		}
+	}
+	return out
+}
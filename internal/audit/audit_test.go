@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	l := NewLog(2)
+	l.Record(Entry{Subject: "ci", Tenant: "analytics", Action: "POST /api/v1/budgets"})
+	l.Record(Entry{Subject: "alice", Tenant: "web", Action: "POST /api/v1/suggestions/clear"})
+	l.Record(Entry{Subject: "ci", Tenant: "analytics", Action: "DELETE /api/v1/budgets/:id"})
+
+	all := l.Entries("")
+	if len(all) != 2 || all[0].Subject != "alice" || all[1].Action != "DELETE /api/v1/budgets/:id" {
+		t.Errorf("entries = %+v, want the two most recent", all)
+	}
+	if got := l.Entries("analytics"); len(got) != 1 || got[0].Subject != "ci" {
+		t.Errorf("analytics entries = %+v", got)
+	}
+}
+
+func TestLogKeepsNewestAcrossCompactions(t *testing.T) {
+	l := NewLog(3)
+	for i := 0; i < 20; i++ {
+		l.Record(Entry{Subject: fmt.Sprint(i)})
+		all := l.Entries("")
+		want := i + 1
+		if want > 3 {
+			want = 3
+		}
+		if len(all) != want || all[len(all)-1].Subject != fmt.Sprint(i) || all[0].Subject != fmt.Sprint(i+1-want) {
+			t.Fatalf("after %d records entries = %+v", i+1, all)
+		}
+		if len(l.entries) > 6 {
+			t.Fatalf("after %d records the log holds %d entries", i+1, len(l.entries))
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package auth authenticates API callers by static API key or by JWT bearer
+// token, and carries the resulting Identity through request contexts.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gopkg.in/yaml.v3"
+)
+
+// Methods an Identity can have been authenticated by.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	// MethodNone marks requests let through because auth is not configured.
+	MethodNone = "none"
+)
+
+// Identity is the caller a request was authenticated as.
+type Identity struct {
+	// Subject names the caller: an API key's name or a token's "sub" claim.
+	Subject string `json:"subject"`
+	Method  string `json:"method"`
+	// Tenant is the tenant the caller acts for, tenant.Default when empty.
+	Tenant string `json:"tenant,omitempty"`
+	// Admin callers may act for any tenant and use the admin routes.
+	Admin bool `json:"admin,omitempty"`
+}
+
+// Anonymous is the identity of every request when auth is not configured.
+var Anonymous = Identity{Subject: "anonymous", Method: MethodNone, Admin: true}
+
+var (
+	// ErrNoCredentials means the request carried neither an API key nor a
+	// bearer token.
+	ErrNoCredentials = errors.New("missing API key or bearer token")
+	ErrInvalidKey    = errors.New("invalid API key")
+	// ErrUnavailable means the credentials could not be checked, e.g.
+	// because Redis is down.
+	ErrUnavailable = errors.New("credentials cannot be checked")
+)
+
+type contextKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the identity ctx carries, if any.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(Identity)
+	return id, ok
+}
+
+// APIKey is a static API key, stored only as the hex SHA-256 of the key.
+type APIKey struct {
+	Name   string `yaml:"name" json:"name"`
+	SHA256 string `yaml:"sha256" json:"sha256,omitempty"`
+	Tenant string `yaml:"tenant" json:"tenant,omitempty"`
+	Admin  bool   `yaml:"admin" json:"admin,omitempty"`
+}
+
+// Config enables API keys, JWTs or both. API keys are listed in the file,
+// kept in a Redis hash, or both.
+type Config struct {
+	APIKeys []APIKey `yaml:"api_keys"`
+	// RedisAPIKeys is a Redis hash mapping key hashes to JSON-encoded APIKeys.
+	RedisAPIKeys string    `yaml:"redis_api_keys"`
+	JWT          JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig validates bearer tokens against the keys in a local JWKS file.
+type JWTConfig struct {
+	JWKS     string `yaml:"jwks"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// TenantClaim names the claim holding the caller's tenant.
+	TenantClaim string `yaml:"tenant_claim"`
+	// AdminScope is the entry of the space-separated "scope" claim that
+	// makes the caller an admin.
+	AdminScope string `yaml:"admin_scope"`
+}
+
+// Load reads a YAML (or JSON) auth file. A relative JWKS path is taken
+// relative to the auth file.
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("parse auth config %s: %w", path, err)
+	}
+	if c.JWT.JWKS != "" && !filepath.IsAbs(c.JWT.JWKS) {
+		c.JWT.JWKS = filepath.Join(filepath.Dir(path), c.JWT.JWKS)
+	}
+	for _, k := range c.APIKeys {
+		if k.Name == "" {
+			return c, fmt.Errorf("%s: every API key needs a name", path)
+		}
+		if b, err := hex.DecodeString(k.SHA256); err != nil || len(b) != sha256.Size {
+			return c, fmt.Errorf("%s: API key %q: sha256 must be a hex SHA-256 digest", path, k.Name)
+		}
+	}
+	if len(c.APIKeys) == 0 && c.RedisAPIKeys == "" && c.JWT.JWKS == "" {
+		return c, fmt.Errorf("%s: configures neither API keys nor a JWKS", path)
+	}
+	return c, nil
+}
+
+// HashKey returns the hex SHA-256 of an API key, as stored in the config.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKey returns a random API key.
+func NewKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "crk_" + hex.EncodeToString(b), nil
+}
+
+// Authenticator checks the credentials of API requests.
+type Authenticator struct {
+	keys  map[string]APIKey
+	redis *redis.Client
+	// redisKeys is the Redis hash holding API keys, if any.
+	redisKeys string
+	jwt       *jwtVerifier
+	now       func() time.Time
+}
+
+// New returns an Authenticator for c. client is only used when c keeps API
+// keys in Redis.
+func New(c Config, client *redis.Client) (*Authenticator, error) {
+	a := &Authenticator{keys: make(map[string]APIKey), redis: client, redisKeys: c.RedisAPIKeys, now: time.Now}
+	for _, k := range c.APIKeys {
+		a.keys[strings.ToLower(k.SHA256)] = k
+	}
+	if c.RedisAPIKeys != "" && client == nil {
+		return nil, errors.New("redis_api_keys needs a Redis client")
+	}
+	if c.JWT.JWKS != "" {
+		v, err := newJWTVerifier(c.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = v
+	}
+	return a, nil
+}
+
+// Authenticate returns the identity of the caller of r, who presents either
+// an API key in the X-API-Key header or a JWT in an "Authorization: Bearer"
+// header.
+func (a *Authenticator) Authenticate(r *http.Request) (Identity, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.apiKey(r.Context(), key)
+	}
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return Identity{}, ErrNoCredentials
+	}
+	if a.jwt == nil {
+		return Identity{}, errors.New("bearer tokens are not accepted")
+	}
+	return a.jwt.verify(strings.TrimSpace(token), a.now())
+}
+
+func (a *Authenticator) apiKey(ctx context.Context, key string) (Identity, error) {
+	hash := HashKey(key)
+	k, ok := a.keys[hash]
+	if !ok && a.redisKeys != "" {
+		b, err := a.redis.HGet(ctx, a.redisKeys, hash).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return Identity{}, ErrInvalidKey
+		}
+		if err != nil {
+			return Identity{}, fmt.Errorf("%w: look up API key: %v", ErrUnavailable, err)
+		}
+		if err := json.Unmarshal(b, &k); err != nil || k.Name == "" {
+			return Identity{}, fmt.Errorf("%w: malformed API key entry %s in %s", ErrUnavailable, hash[:12], a.redisKeys)
+		}
+		ok = true
+	}
+	if !ok {
+		return Identity{}, ErrInvalidKey
+	}
+	return Identity{Subject: k.Name, Method: MethodAPIKey, Tenant: k.Tenant, Admin: k.Admin}, nil
+}
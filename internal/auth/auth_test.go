@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding
+
+// sign returns a JWT over claims signed by key with alg.
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	h, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	c, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(h) + "." + b64.EncodeToString(c)
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(signed))
+	case *rsa.PrivateKey:
+		d := sha256.Sum256([]byte(signed))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, d[:])
+	case *ecdsa.PrivateKey:
+		d := sha256.Sum256([]byte(signed))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, d[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64.EncodeToString(sig)
+}
+
+func TestAuthenticate(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig", "n": b64.EncodeToString(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))), "y": b64.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": b64.EncodeToString(edPub)},
+	}})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jwks.json"), jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := `
+api_keys:
+  - {name: ci, sha256: ` + HashKey("secret-key") + `, tenant: analytics}
+jwt:
+  jwks: jwks.json
+  issuer: https://idp.example.com
+  audience: cloud-resource
+  admin_scope: admin
+`
+	if err := os.WriteFile(filepath.Join(dir, "auth.yaml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(filepath.Join(dir, "auth.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "alice", "iss": "https://idp.example.com", "aud": []string{"cloud-resource"}, "exp": now.Add(time.Hour).Unix(), "tenant": "web"}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	expired := sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}))
+	tampered := sign(t, "ES256", "ec-1", ecKey, claims(nil))
+	parts := strings.Split(tampered, ".")
+	forged, _ := json.Marshal(claims(map[string]interface{}{"scope": "admin"}))
+	tampered = parts[0] + "." + b64.EncodeToString(forged) + "." + parts[2]
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		want   Identity
+		err    bool
+	}{
+		{"API key", "X-API-Key", "secret-key", Identity{Subject: "ci", Method: MethodAPIKey, Tenant: "analytics"}, false},
+		{"wrong API key", "X-API-Key", "guess", Identity{}, true},
+		{"no credentials", "", "", Identity{}, true},
+		{"RS256 token", "Authorization", "Bearer " + sign(t, "RS256", "rsa-1", rsaKey, claims(nil)), Identity{Subject: "alice", Method: MethodJWT, Tenant: "web"}, false},
+		{"ES256 admin token", "Authorization", "Bearer " + sign(t, "ES256", "ec-1", ecKey, claims(map[string]interface{}{"scope": "read admin"})), Identity{Subject: "alice", Method: MethodJWT, Tenant: "web", Admin: true}, false},
+		{"EdDSA token", "Authorization", "Bearer " + sign(t, "EdDSA", "ed-1", edKey, claims(nil)), Identity{Subject: "alice", Method: MethodJWT, Tenant: "web"}, false},
+		{"expired token", "Authorization", "Bearer " + expired, Identity{}, true},
+		{"wrong audience", "Authorization", "Bearer " + sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": "other"})), Identity{}, true},
+		{"wrong issuer", "Authorization", "Bearer " + sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})), Identity{}, true},
+		{"tampered claims", "Authorization", "Bearer " + tampered, Identity{}, true},
+		{"alg does not fit key", "Authorization", "Bearer " + sign(t, "EdDSA", "rsa-1", edKey, claims(nil)), Identity{}, true},
+		{"unsigned token", "Authorization", "Bearer " + b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte(`{"sub":"alice"}`)) + ".", Identity{}, true},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/api/v1/suggestions", nil)
+		if c.header != "" {
+			r.Header.Set(c.header, c.value)
+		}
+		got, err := a.Authenticate(r)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("%s: got %+v, %v", c.name, got, err)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	if _, err := a.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no credentials: %v, want ErrNoCredentials", err)
+	}
+	ctx := WithIdentity(r.Context(), Identity{Subject: "ci"})
+	if id, ok := FromContext(ctx); !ok || id.Subject != "ci" {
+		t.Errorf("FromContext = %+v, %v", id, ok)
+	}
+}
+
+func TestLoadRejectsPlaintextKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	if err := os.WriteFile(path, []byte(`api_keys: [{name: ci, sha256: secret-key}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("accepted an API key that is not a SHA-256 digest")
+	}
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far token lifetimes may disagree with the local clock.
+const clockSkew = time.Minute
+
+// jwk is one key of a JWKS file (RFC 7517). Only the fields of RSA, EC and
+// Ed25519 public keys are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey is a parsed JWKS key with the algorithms it may verify.
+type publicKey struct {
+	kid  string
+	alg  string
+	key  crypto.PublicKey
+	algs map[string]bool
+}
+
+type jwtVerifier struct {
+	cfg  JWTConfig
+	keys []publicKey
+}
+
+func newJWTVerifier(cfg JWTConfig) (*jwtVerifier, error) {
+	b, err := os.ReadFile(cfg.JWKS)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS %s: %w", cfg.JWKS, err)
+	}
+	v := &jwtVerifier{cfg: cfg}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pk, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS %s: key %d (%q): %w", cfg.JWKS, i, k.Kid, err)
+		}
+		v.keys = append(v.keys, pk)
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s holds no signing keys", cfg.JWKS)
+	}
+	if v.cfg.TenantClaim == "" {
+		v.cfg.TenantClaim = "tenant"
+	}
+	return v, nil
+}
+
+func (k jwk) parse() (publicKey, error) {
+	pk := publicKey{kid: k.Kid, alg: k.Alg}
+	switch k.Kty {
+	case "RSA":
+		n, err1 := b64Int(k.N)
+		e, err2 := b64Int(k.E)
+		if err := errors.Join(err1, err2); err != nil {
+			return pk, err
+		}
+		if n.BitLen() < 2048 || !e.IsInt64() {
+			return pk, errors.New("RSA keys must be at least 2048 bits")
+		}
+		pk.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		pk.algs = map[string]bool{"RS256": true, "RS384": true, "RS512": true}
+	case "EC":
+		curves := map[string]struct {
+			curve elliptic.Curve
+			alg   string
+		}{"P-256": {elliptic.P256(), "ES256"}, "P-384": {elliptic.P384(), "ES384"}, "P-521": {elliptic.P521(), "ES512"}}
+		c, ok := curves[k.Crv]
+		if !ok {
+			return pk, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err1 := b64Int(k.X)
+		y, err2 := b64Int(k.Y)
+		if err := errors.Join(err1, err2); err != nil {
+			return pk, err
+		}
+		if !c.curve.IsOnCurve(x, y) {
+			return pk, errors.New("point is not on the curve")
+		}
+		pk.key = &ecdsa.PublicKey{Curve: c.curve, X: x, Y: y}
+		pk.algs = map[string]bool{c.alg: true}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return pk, err
+		}
+		if k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return pk, fmt.Errorf("unsupported OKP key on curve %q", k.Crv)
+		}
+		pk.key = ed25519.PublicKey(x)
+		pk.algs = map[string]bool{"EdDSA": true}
+	default:
+		return pk, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	if pk.alg != "" && !pk.algs[pk.alg] {
+		return pk, fmt.Errorf("alg %q does not fit a %s key", pk.alg, k.Kty)
+	}
+	return pk, nil
+}
+
+func b64Int(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("malformed key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// claims are the registered claims checked on every token; the tenant and
+// scope claims are read from the raw claim set.
+type claims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// verify checks the signature and lifetime of token, and its issuer and
+// audience when configured, and returns the identity it asserts.
+func (v *jwtVerifier) verify(token string, now time.Time) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("malformed bearer token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.New("malformed token signature")
+	}
+	key, err := v.key(header.Kid, header.Alg)
+	if err != nil {
+		return Identity{}, err
+	}
+	if !verifySignature(key.key, header.Alg, []byte(parts[0]+"."+parts[1]), sig) {
+		return Identity{}, errors.New("invalid token signature")
+	}
+
+	var c claims
+	var raw map[string]interface{}
+	if err := errors.Join(decodeSegment(parts[1], &c), decodeSegment(parts[1], &raw)); err != nil {
+		return Identity{}, err
+	}
+	if c.ExpiresAt == nil {
+		return Identity{}, errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(int64(*c.ExpiresAt), 0).Add(clockSkew)) {
+		return Identity{}, errors.New("token has expired")
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(int64(*c.NotBefore), 0)) {
+		return Identity{}, errors.New("token is not valid yet")
+	}
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return Identity{}, errors.New("token has the wrong issuer")
+	}
+	if v.cfg.Audience != "" && !hasAudience(c.Audience, v.cfg.Audience) {
+		return Identity{}, errors.New("token has the wrong audience")
+	}
+	if c.Subject == "" {
+		return Identity{}, errors.New("token has no subject")
+	}
+
+	id := Identity{Subject: c.Subject, Method: MethodJWT}
+	if t, ok := raw[v.cfg.TenantClaim].(string); ok {
+		id.Tenant = t
+	}
+	if scope, ok := raw["scope"].(string); ok && v.cfg.AdminScope != "" {
+		for _, s := range strings.Fields(scope) {
+			id.Admin = id.Admin || s == v.cfg.AdminScope
+		}
+	}
+	return id, nil
+}
+
+// key returns the JWKS key that verifies alg under kid. Tokens without a kid
+// are only accepted when the JWKS holds a single key.
+func (v *jwtVerifier) key(kid, alg string) (publicKey, error) {
+	for _, k := range v.keys {
+		if (k.kid == kid || kid == "" && len(v.keys) == 1) && k.algs[alg] && (k.alg == "" || k.alg == alg) {
+			return k, nil
+		}
+	}
+	return publicKey{}, fmt.Errorf("no JWKS key verifies %s tokens with kid %q", alg, kid)
+}
+
+func verifySignature(key crypto.PublicKey, alg string, signed, sig []byte) bool {
+	hashes := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, signed, sig)
+	case *rsa.PublicKey:
+		h := hashes[alg[2:]]
+		return rsa.VerifyPKCS1v15(k, h, digest(h, signed), sig) == nil
+	case *ecdsa.PublicKey:
+		// JWS ECDSA signatures are r and s, each padded to the curve size.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k, digest(hashes[alg[2:]], signed), r, s)
+	}
+	return false
+}
+
+func digest(h crypto.Hash, b []byte) []byte {
+	d := h.New()
+	d.Write(b)
+	return d.Sum(nil)
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("malformed bearer token")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed bearer token")
+	}
+	return nil
+}
+
+// hasAudience reports whether aud, a string or a list of strings, names want.
+func hasAudience(aud json.RawMessage, want string) bool {
+	var one string
+	if json.Unmarshal(aud, &one) == nil {
+		return one == want
+	}
+	var many []string
+	if json.Unmarshal(aud, &many) == nil {
+		for _, a := range many {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+// Package budget tracks month-to-date and forecasted spend against monthly
+// budgets scoped by owner, resource type and tags, and raises suggestions when
+// spend crosses a budget's thresholds.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+var ErrNotFound = errors.New("budget not found")
+
+// Budget is a monthly spend limit. Empty scope fields match every resource, so
+// a budget with only Owner set covers everything that owner runs. Thresholds
+// are percentages of MonthlyLimitUSD. A budget only ever covers the resources
+// of its Tenant (tenant.Default when empty).
+type Budget struct {
+	ID                 string            `json:"id"`
+	Tenant             string            `json:"tenant,omitempty"`
+	Name               string            `json:"name"`
+	Owner              string            `json:"owner,omitempty"`
+	ResourceType       string            `json:"resource_type,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	MonthlyLimitUSD    float64           `json:"monthly_limit_usd"`
+	ActualThresholds   []float64         `json:"actual_thresholds"`
+	ForecastThresholds []float64         `json:"forecast_thresholds"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// Default alert thresholds.
+var (
+	DefaultActualThresholds   = []float64{50, 80, 100}
+	DefaultForecastThresholds = []float64{100}
+)
+
+// Validate fills in default thresholds and rejects budgets that cannot be
+// evaluated.
+func (b *Budget) Validate() error {
+	if b.MonthlyLimitUSD <= 0 {
+		return errors.New("monthly_limit_usd must be positive")
+	}
+	if b.ActualThresholds == nil {
+		b.ActualThresholds = append([]float64(nil), DefaultActualThresholds...)
+	}
+	if b.ForecastThresholds == nil {
+		b.ForecastThresholds = append([]float64(nil), DefaultForecastThresholds...)
+	}
+	for _, t := range append(append([]float64(nil), b.ActualThresholds...), b.ForecastThresholds...) {
+		if t <= 0 {
+			return fmt.Errorf("threshold %v must be a positive percentage", t)
+		}
+	}
+	sort.Float64s(b.ActualThresholds)
+	sort.Float64s(b.ForecastThresholds)
+	if b.Name == "" {
+		b.Name = b.defaultName()
+	}
+	return nil
+}
+
+func (b *Budget) defaultName() string {
+	switch {
+	case b.Owner != "" && b.ResourceType != "":
+		return b.Owner + " " + b.ResourceType
+	case b.Owner != "":
+		return b.Owner
+	case b.ResourceType != "":
+		return b.ResourceType
+	}
+	return "All resources"
+}
+
+// Matches reports whether r falls within the budget's scope.
+func (b *Budget) Matches(r models.CloudResource) bool {
+	if tenant.Of(r) != tenant.Or(b.Tenant) {
+		return false
+	}
+	if b.Owner != "" && r.GetOwner() != b.Owner {
+		return false
+	}
+	if b.ResourceType != "" && r.GetType() != b.ResourceType {
+		return false
+	}
+	return models.TagFilter(b.Tags).Matches(r)
+}
+
+// Store holds budgets in memory.
+type Store struct {
+	mu      sync.RWMutex
+	budgets map[string]Budget
+	nextID  int
+}
+
+func NewStore() *Store {
+	return &Store{budgets: make(map[string]Budget)}
+}
+
+var defaultStore = NewStore()
+
+// Default is the store served by the API and checked by the live monitor.
+func Default() *Store {
+	return defaultStore
+}
+
+// Create validates b, assigns it an ID and stores it.
+func (s *Store) Create(b Budget, now time.Time) (Budget, error) {
+	if err := b.Validate(); err != nil {
+		return Budget{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	b.ID = "budget-" + strconv.Itoa(s.nextID)
+	b.CreatedAt, b.UpdatedAt = now, now
+	s.budgets[b.ID] = b
+	return b, nil
+}
+
+func (s *Store) Get(id string) (Budget, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.budgets[id]
+	if !ok {
+		return Budget{}, ErrNotFound
+	}
+	return b, nil
+}
+
+// List returns every budget ordered by ID number.
+func (s *Store) List() []Budget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Budget, 0, len(s.budgets))
+	for _, b := range s.budgets {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return idNumber(out[i].ID) < idNumber(out[j].ID) })
+	return out
+}
+
+// Update replaces the budget with id, keeping its creation time.
+func (s *Store) Update(id string, b Budget, now time.Time) (Budget, error) {
+	if err := b.Validate(); err != nil {
+		return Budget{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.budgets[id]
+	if !ok {
+		return Budget{}, ErrNotFound
+	}
+	b.ID, b.CreatedAt, b.UpdatedAt = id, old.CreatedAt, now
+	s.budgets[id] = b
+	return b, nil
+}
+
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.budgets[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.budgets, id)
+	return nil
+}
+
+// Restore replaces every budget, e.g. with ones saved in a snapshot. New
+// budgets are numbered after the highest restored ID.
+func (s *Store) Restore(budgets []Budget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budgets = make(map[string]Budget, len(budgets))
+	s.nextID = 0
+	for _, b := range budgets {
+		s.budgets[b.ID] = b
+		if n := idNumber(b.ID); n > s.nextID {
+			s.nextID = n
+		}
+	}
+}
+
+func idNumber(id string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(id, "budget-"))
+	return n
+}
@@ -0,0 +1,86 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestMonitorRaisesEachThresholdOnce(t *testing.T) {
+	resources := []models.CloudResource{
+		&models.VM{ID: "vm-1", CostPerHour: 1, Owner: "Finance Team"},
+		&models.VM{ID: "vm-2", CostPerHour: 5, Owner: "Engineering"},
+	}
+	hist := history.NewStore(0)
+	monthStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h <= 200; h++ {
+		ts := monthStart.Add(time.Duration(h) * time.Hour)
+		for _, r := range resources {
+			hist.Record(r.GetId(), ts, r.Metrics())
+		}
+	}
+
+	store := NewStore()
+	// Finance spends $1/h: $200 by hour 200 and $744 over March.
+	b, err := store.Create(Budget{Owner: "Finance Team", MonthlyLimitUSD: 300}, monthStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &analyzer.InMemorySuggestionSink{}
+	m := &Monitor{Budgets: store, History: hist, Sink: sink}
+
+	now := monthStart.Add(200 * time.Hour)
+	statuses := m.Check(resources, now)
+	if len(statuses) != 1 || len(statuses[0].Resources) != 1 || statuses[0].Resources[0] != "vm-1" {
+		t.Fatalf("budget should cover only vm-1: %+v", statuses)
+	}
+	st := statuses[0]
+	if st.ActualUSD != 200 {
+		t.Errorf("actual = %v, want 200", st.ActualUSD)
+	}
+	if st.ForecastUSD != 744 {
+		t.Errorf("forecast = %v, want 744", st.ForecastUSD)
+	}
+
+	want := map[Threshold]bool{{Actual, 50}: true, {Forecast, 100}: true}
+	got := sink.GetSuggestions()
+	if len(got) != len(want) {
+		t.Fatalf("got %d alerts, want %d: %+v", len(got), len(want), got)
+	}
+	for _, s := range got {
+		th := Threshold{s.Details["threshold_kind"].(string), s.Details["threshold_percent"].(float64)}
+		if !want[th] || s.ResourceID != b.ID {
+			t.Errorf("unexpected alert %+v", s)
+		}
+	}
+
+	m.Check(resources, now.Add(time.Minute))
+	if n := len(sink.GetSuggestions()); n != 2 {
+		t.Errorf("thresholds fired again: %d alerts", n)
+	}
+
+	// A restarted monitor restored from a snapshot does not fire them again.
+	restored := &Monitor{Budgets: store, History: hist, Sink: sink}
+	restored.RestoreFired(m.Fired())
+	restored.Check(resources, now.Add(2*time.Minute))
+	if n := len(sink.GetSuggestions()); n != 2 {
+		t.Errorf("thresholds fired again after restore: %d alerts", n)
+	}
+}
+
+func TestMonthToDateOutlivesRawSamples(t *testing.T) {
+	vm := &models.VM{ID: "vm-1", CostPerHour: 1, Owner: "Finance Team"}
+	hist := history.NewStore(0)
+	monthStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Eight hours at one sample per second, longer than the raw samples last.
+	for i := 0; i < 8*3600; i++ {
+		hist.Record(vm.ID, monthStart.Add(time.Duration(i)*time.Second), vm.Metrics())
+	}
+	st := Evaluate(Budget{Owner: "Finance Team", MonthlyLimitUSD: 10}, []models.CloudResource{vm}, hist, monthStart.Add(8*time.Hour))
+	if st.ActualUSD < 7.99 || st.ActualUSD > 8.01 {
+		t.Errorf("month-to-date = %v, want 8", st.ActualUSD)
+	}
+}
@@ -0,0 +1,204 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+// Kinds of threshold.
+const (
+	Actual   = "actual"
+	Forecast = "forecast"
+)
+
+type Threshold struct {
+	Kind    string  `json:"kind"`
+	Percent float64 `json:"percent"`
+}
+
+// Status is a budget's position in the month containing the evaluation time.
+// ForecastUSD is month-to-date spend plus the linear projection of each
+// resource over the rest of the month.
+type Status struct {
+	Budget          Budget      `json:"budget"`
+	Month           string      `json:"month"`
+	Resources       []string    `json:"resources"`
+	ActualUSD       float64     `json:"actual_usd"`
+	ForecastUSD     float64     `json:"forecast_usd"`
+	ActualPercent   float64     `json:"actual_percent"`
+	ForecastPercent float64     `json:"forecast_percent"`
+	Crossed         []Threshold `json:"crossed"`
+}
+
+// Evaluate computes b's status at now from the cost history of the resources
+// in its scope. The history keeps hourly averages for longer than a month
+// (history.DefaultRetention), so month-to-date spend covers the whole month.
+func Evaluate(b Budget, resources []models.CloudResource, hist *history.Store, now time.Time) Status {
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	remaining := int(math.Ceil(monthStart.AddDate(0, 1, 0).Sub(now).Hours()))
+
+	st := Status{Budget: b, Month: monthStart.Format("2006-01"), Resources: []string{}, Crossed: []Threshold{}}
+	for _, r := range resources {
+		if !b.Matches(r) {
+			continue
+		}
+		id := r.GetId()
+		st.Resources = append(st.Resources, id)
+		actual := forecast.Actual(hist, id, monthStart, now)
+		st.ActualUSD += actual
+		st.ForecastUSD += actual + forecast.Projected(hist, id, now, remaining)
+	}
+	sort.Strings(st.Resources)
+	st.ActualPercent = 100 * st.ActualUSD / b.MonthlyLimitUSD
+	st.ForecastPercent = 100 * st.ForecastUSD / b.MonthlyLimitUSD
+	for _, t := range b.ActualThresholds {
+		if st.ActualPercent >= t {
+			st.Crossed = append(st.Crossed, Threshold{Kind: Actual, Percent: t})
+		}
+	}
+	for _, t := range b.ForecastThresholds {
+		if st.ForecastPercent >= t {
+			st.Crossed = append(st.Crossed, Threshold{Kind: Forecast, Percent: t})
+		}
+	}
+	return st
+}
+
+// Monitor evaluates every budget and raises one suggestion per threshold per
+// month. Editing a budget re-arms its thresholds. Fired and RestoreFired let
+// snapshots carry the thresholds that already fired across restarts.
+type Monitor struct {
+	Budgets *Store
+	History *history.Store
+	Sink    analyzer.SuggestionSink
+
+	mu    sync.Mutex
+	fired map[string]bool
+}
+
+// Check evaluates all budgets at now and returns their statuses.
+func (m *Monitor) Check(resources []models.CloudResource, now time.Time) []Status {
+	var statuses []Status
+	for _, b := range m.Budgets.List() {
+		st := Evaluate(b, resources, m.History, now)
+		statuses = append(statuses, st)
+		for _, t := range st.Crossed {
+			if m.markFired(st, t) && m.Sink != nil {
+				m.Sink.AddSuggestion(alert(st, t, now))
+			}
+		}
+	}
+	return statuses
+}
+
+// markFired records that t fired for st's budget and month, and reports whether
+// it had not fired before.
+func (m *Monitor) markFired(st Status, t Threshold) bool {
+	key := st.Budget.ID + "|" + st.Budget.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + st.Month + "|" + t.Kind + "|" + strconv.FormatFloat(t.Percent, 'f', -1, 64)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fired == nil {
+		m.fired = make(map[string]bool)
+	}
+	if m.fired[key] {
+		return false
+	}
+	m.fired[key] = true
+	return true
+}
+
+// Fired returns the keys of the thresholds that have fired, sorted.
+func (m *Monitor) Fired() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.fired))
+	for k := range m.fired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RestoreFired replaces the fired thresholds, e.g. with ones saved in a
+// snapshot, so that they do not fire again.
+func (m *Monitor) RestoreFired(keys []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fired = make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m.fired[k] = true
+	}
+}
+
+func alert(st Status, t Threshold, now time.Time) analyzer.Suggestion {
+	b := st.Budget
+	pct := strconv.FormatFloat(t.Percent, 'f', -1, 64)
+	var message, severity string
+	var priority int
+	if t.Kind == Forecast {
+		message = fmt.Sprintf("Budget '%s' is forecast to reach %s%% of its $%.2f monthly limit ($%.2f projected for %s). Review spend before the month closes.", b.Name, pct, b.MonthlyLimitUSD, st.ForecastUSD, st.Month)
+		severity, priority = "Warning", 2
+	} else {
+		message = fmt.Sprintf("Budget '%s' has reached %s%% of its $%.2f monthly limit ($%.2f spent in %s).", b.Name, pct, b.MonthlyLimitUSD, st.ActualUSD, st.Month)
+		switch {
+		case t.Percent >= 100:
+			severity, priority = "Critical", 1
+		case t.Percent >= 80:
+			severity, priority = "Warning", 2
+		default:
+			severity, priority = "Info", 3
+		}
+	}
+	return analyzer.Suggestion{
+		ResourceID:   b.ID,
+		ResourceType: "Budget",
+		Message:      message,
+		Severity:     severity,
+		Priority:     priority,
+		Timestamp:    now,
+		Action:       "Review budget",
+		Tenant:       tenant.Or(b.Tenant),
+		Details: map[string]interface{}{
+			"budget_name":       b.Name,
+			"owner":             b.Owner,
+			"resource_type":     b.ResourceType,
+			"month":             st.Month,
+			"threshold_kind":    t.Kind,
+			"threshold_percent": t.Percent,
+			"limit_usd":         b.MonthlyLimitUSD,
+			"actual_usd":        st.ActualUSD,
+			"forecast_usd":      st.ForecastUSD,
+			"resources":         st.Resources,
+			"business_impact":   "Spend is approaching or over the agreed monthly budget.",
+		},
+		DocsLink: "https://docs.aws.amazon.com/cost-management/latest/userguide/budgets-managing-costs.html",
+	}
+}
+
+// Watch runs Check every interval until ctx is cancelled. resources is called
+// on each check so it sees the current resource list; now supplies the
+// evaluation time (sim.Now in the live service).
+func (m *Monitor) Watch(ctx context.Context, interval time.Duration, resources func() []models.CloudResource, now func() time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(resources(), now())
+		}
+	}
+}
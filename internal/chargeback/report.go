@@ -0,0 +1,154 @@
+// Package chargeback attributes spend over a period to resource owners, sharing
+// the cost of resources used by several teams according to split rules.
+package chargeback
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// Unassigned collects the spend of resources without an owner.
+const Unassigned = "Unassigned"
+
+// Report amounts are in Currency; Build reports in USD. CoveredFrom is where
+// the recorded history the amounts are taken from begins: Start, or later when
+// the history does not reach back that far and earlier spend is missing.
+type Report struct {
+	Start       time.Time   `json:"start"`
+	End         time.Time   `json:"end"`
+	CoveredFrom time.Time   `json:"covered_from"`
+	Currency    string      `json:"currency"`
+	Total       float64     `json:"total"`
+	Owners      []OwnerLine `json:"owners"`
+}
+
+// OwnerLine is one owner's charge: Direct for resources it owns outright and
+// SharedCost for its share of split resources.
+type OwnerLine struct {
+	Owner       string       `json:"owner"`
+	Direct      float64      `json:"direct"`
+	SharedCost  float64      `json:"shared_cost"`
+	Total       float64      `json:"total"`
+	Allocations []Allocation `json:"allocations"`
+}
+
+type Allocation struct {
+	ResourceID   string  `json:"resource_id"`
+	ResourceType string  `json:"resource_type"`
+	Shared       bool    `json:"shared"`
+	SharePercent float64 `json:"share_percent"`
+	Cost         float64 `json:"cost"`
+}
+
+// Build charges the spend of each resource between start and end, taken from
+// its recorded cost history, to owners.
+func Build(resources []models.CloudResource, hist *history.Store, splits Splits, start, end time.Time) Report {
+	report := Report{Start: start, End: end, CoveredFrom: start, Currency: "USD", Owners: []OwnerLine{}}
+	if oldest := hist.Oldest(); oldest.After(start) {
+		report.CoveredFrom = oldest
+		if oldest.After(end) {
+			report.CoveredFrom = end
+		}
+	}
+	lines := make(map[string]*OwnerLine)
+	charge := func(owner string, a Allocation) {
+		if owner == "" {
+			owner = Unassigned
+		}
+		l, ok := lines[owner]
+		if !ok {
+			l = &OwnerLine{Owner: owner}
+			lines[owner] = l
+		}
+		if a.Shared {
+			l.SharedCost += a.Cost
+		} else {
+			l.Direct += a.Cost
+		}
+		l.Total += a.Cost
+		l.Allocations = append(l.Allocations, a)
+	}
+
+	for _, r := range resources {
+		cost := forecast.Actual(hist, r.GetId(), start, end)
+		report.Total += cost
+		base := Allocation{ResourceID: r.GetId(), ResourceType: r.GetType()}
+		split, ok := splits.For(r)
+		if !ok {
+			a := base
+			a.SharePercent, a.Cost = 100, cost
+			charge(r.GetOwner(), a)
+			continue
+		}
+		total := 0.0
+		for _, w := range split.Shares {
+			total += w
+		}
+		owners := make([]string, 0, len(split.Shares))
+		for owner := range split.Shares {
+			owners = append(owners, owner)
+		}
+		sort.Strings(owners)
+		for _, owner := range owners {
+			a := base
+			a.Shared = true
+			a.SharePercent = 100 * split.Shares[owner] / total
+			a.Cost = cost * split.Shares[owner] / total
+			charge(owner, a)
+		}
+	}
+
+	for _, l := range lines {
+		sort.Slice(l.Allocations, func(i, j int) bool { return l.Allocations[i].ResourceID < l.Allocations[j].ResourceID })
+		report.Owners = append(report.Owners, *l)
+	}
+	sort.Slice(report.Owners, func(i, j int) bool {
+		if report.Owners[i].Total != report.Owners[j].Total {
+			return report.Owners[i].Total > report.Owners[j].Total
+		}
+		return report.Owners[i].Owner < report.Owners[j].Owner
+	})
+	return report
+}
+
+// WriteCSV writes one row per owner and resource allocation.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"period_start", "period_end", "covered_from", "owner", "resource_id", "resource_type", "shared", "share_percent", "cost", "currency"})
+	start, end, covered := r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339), r.CoveredFrom.Format(time.RFC3339)
+	for _, l := range r.Owners {
+		for _, a := range l.Allocations {
+			_ = cw.Write([]string{
+				start, end, covered, l.Owner, a.ResourceID, a.ResourceType,
+				strconv.FormatBool(a.Shared),
+				strconv.FormatFloat(a.SharePercent, 'f', 2, 64),
+				strconv.FormatFloat(a.Cost, 'f', 4, 64),
+				r.Currency,
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Scale multiplies every amount in r by factor and labels it as currency.
+func (r *Report) Scale(factor float64, currency string) {
+	r.Currency = currency
+	r.Total *= factor
+	for i := range r.Owners {
+		l := &r.Owners[i]
+		l.Direct *= factor
+		l.SharedCost *= factor
+		l.Total *= factor
+		for j := range l.Allocations {
+			l.Allocations[j].Cost *= factor
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package chargeback
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestBuildSplitsSharedResources(t *testing.T) {
+	resources := []models.CloudResource{
+		&models.VM{ID: "vm-1", CostPerHour: 1, Owner: "Engineering"},
+		&models.ELB{ID: "elb-1", CostPerHour: 2, Owner: "WebOps"},
+		&models.DynamoDB{ID: "ddb-1", CostPerHr: 0.5},
+	}
+	hist := history.NewStore(0)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for h := 0; h < 10; h++ {
+		for _, r := range resources {
+			hist.Record(r.GetId(), start.Add(time.Duration(h)*time.Hour), r.Metrics())
+		}
+	}
+	splits := Splits{Splits: []Split{{Resource: "elb-1", Shares: map[string]float64{"Engineering": 3, "Finance Team": 1}}}}
+	if err := splits.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Build(resources, hist, splits, start, start.Add(10*time.Hour))
+	want := map[string]float64{"Engineering": 10 + 15, "Finance Team": 5, Unassigned: 5}
+	if len(report.Owners) != len(want) {
+		t.Fatalf("got owners %+v", report.Owners)
+	}
+	for _, l := range report.Owners {
+		if math.Abs(l.Total-want[l.Owner]) > 1e-9 {
+			t.Errorf("%s charged %v, want %v", l.Owner, l.Total, want[l.Owner])
+		}
+	}
+	if report.Owners[0].Owner != "Engineering" || report.Owners[0].SharedCost != 15 {
+		t.Errorf("Engineering should lead with $15 shared: %+v", report.Owners[0])
+	}
+	if report.Total != 35 {
+		t.Errorf("total = %v, want 35", report.Total)
+	}
+	if !report.CoveredFrom.Equal(start) {
+		t.Errorf("covered from %v, want %v", report.CoveredFrom, start)
+	}
+	// A period that starts before the recorded history reports where it starts.
+	if early := Build(resources, hist, splits, start.AddDate(0, 0, -1), start.Add(10*time.Hour)); !early.CoveredFrom.Equal(start) || early.Total != 35 {
+		t.Errorf("covered from %v with total %v, want %v and 35", early.CoveredFrom, early.Total, start)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Header plus vm-1, two elb-1 shares and ddb-1.
+	if len(rows) != 5 {
+		t.Errorf("got %d CSV rows, want 5", len(rows))
+	}
+}
@@ -0,0 +1,96 @@
+package chargeback
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Split allocates the cost of shared resources to several owners in proportion
+// to Shares (weights, e.g. percentages). It applies to the resource with ID
+// Resource or, when Resource is empty, to every resource of ResourceType.
+type Split struct {
+	Resource     string             `yaml:"resource,omitempty" json:"resource,omitempty"`
+	ResourceType string             `yaml:"resource_type,omitempty" json:"resource_type,omitempty"`
+	Shares       map[string]float64 `yaml:"shares" json:"shares"`
+}
+
+// Splits is a set of split rules. A rule naming a resource wins over a rule for
+// its type; resources without a rule are charged to their Owner.
+type Splits struct {
+	Splits []Split `yaml:"splits" json:"splits"`
+}
+
+// LoadSplits reads a YAML (or JSON) split rules file.
+func LoadSplits(path string) (Splits, error) {
+	var s Splits
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("parse splits %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s Splits) Validate() error {
+	for i, sp := range s.Splits {
+		if sp.Resource == "" && sp.ResourceType == "" {
+			return fmt.Errorf("split %d: set resource or resource_type", i+1)
+		}
+		total := 0.0
+		for owner, w := range sp.Shares {
+			if w < 0 {
+				return fmt.Errorf("split %d: negative share for %q", i+1, owner)
+			}
+			total += w
+		}
+		if total == 0 {
+			return fmt.Errorf("split %d: %w", i+1, errors.New("shares must not be empty or all zero"))
+		}
+	}
+	return nil
+}
+
+// For returns the split that applies to r, if any.
+func (s Splits) For(r models.CloudResource) (Split, bool) {
+	var byType *Split
+	for i, sp := range s.Splits {
+		if sp.Resource == r.GetId() {
+			return sp, true
+		}
+		if sp.Resource == "" && sp.ResourceType == r.GetType() && byType == nil {
+			byType = &s.Splits[i]
+		}
+	}
+	if byType != nil {
+		return *byType, true
+	}
+	return Split{}, false
+}
+
+var (
+	splitsMu     sync.RWMutex
+	activeSplits Splits
+)
+
+// SetSplits replaces the split rules used by the chargeback report.
+func SetSplits(s Splits) {
+	splitsMu.Lock()
+	defer splitsMu.Unlock()
+	activeSplits = s
+}
+
+func CurrentSplits() Splits {
+	splitsMu.RLock()
+	defer splitsMu.RUnlock()
+	return activeSplits
+}
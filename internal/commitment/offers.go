@@ -0,0 +1,121 @@
+package commitment
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Terms and payment options.
+const (
+	OneYear   = "1y"
+	ThreeYear = "3y"
+
+	NoUpfront      = "no_upfront"
+	PartialUpfront = "partial_upfront"
+	AllUpfront     = "all_upfront"
+)
+
+// TermHours is the length of a term in billable hours.
+func TermHours(term string) float64 {
+	if term == ThreeYear {
+		return 3 * 365 * 24
+	}
+	return 365 * 24
+}
+
+// Offer is a commitment price expressed as a discount off on-demand. Upfront
+// is the share of the total commitment paid at purchase.
+type Offer struct {
+	Service  string  `yaml:"service" json:"service"`
+	Term     string  `yaml:"term" json:"term"`
+	Payment  string  `yaml:"payment" json:"payment"`
+	Discount float64 `yaml:"discount" json:"discount"`
+	Upfront  float64 `yaml:"upfront" json:"upfront"`
+}
+
+// Offers is the local commitment price table: reserved instance offers for ec2
+// and rds, and compute savings plan rates for ec2 and lambda.
+type Offers struct {
+	Reserved     []Offer `yaml:"reserved" json:"reserved"`
+	SavingsPlans []Offer `yaml:"savings_plans" json:"savings_plans"`
+}
+
+//go:embed default_offers.yaml
+var defaultOffers []byte
+
+// DefaultOffers returns the table bundled with the binary.
+func DefaultOffers() *Offers {
+	o, err := ParseOffers(defaultOffers)
+	if err != nil {
+		panic("commitment: invalid bundled offers: " + err.Error())
+	}
+	return o
+}
+
+// LoadOffers reads a commitment price table. JSON files are accepted as well
+// as YAML.
+func LoadOffers(path string) (*Offers, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	o, err := ParseOffers(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse commitment offers %s: %w", path, err)
+	}
+	return o, nil
+}
+
+func ParseOffers(b []byte) (*Offers, error) {
+	var o Offers
+	if err := yaml.Unmarshal(b, &o); err != nil {
+		return nil, err
+	}
+	for _, list := range [][]Offer{o.Reserved, o.SavingsPlans} {
+		for i, offer := range list {
+			if offer.Term != OneYear && offer.Term != ThreeYear {
+				return nil, fmt.Errorf("offer %d: term must be 1y or 3y", i+1)
+			}
+			if offer.Payment != NoUpfront && offer.Payment != PartialUpfront && offer.Payment != AllUpfront {
+				return nil, fmt.Errorf("offer %d: unknown payment %q", i+1, offer.Payment)
+			}
+			if offer.Discount <= 0 || offer.Discount >= 1 || offer.Upfront < 0 || offer.Upfront > 1 {
+				return nil, fmt.Errorf("offer %d: discount must be in (0, 1) and upfront in [0, 1]", i+1)
+			}
+		}
+	}
+	return &o, nil
+}
+
+// savingsPlanDiscount returns the savings plan discount for service under term
+// and payment.
+func (o *Offers) savingsPlanDiscount(service, term, payment string) (float64, bool) {
+	for _, offer := range o.SavingsPlans {
+		if offer.Service == service && offer.Term == term && offer.Payment == payment {
+			return offer.Discount, true
+		}
+	}
+	return 0, false
+}
+
+var (
+	offersMu     sync.RWMutex
+	activeOffers = DefaultOffers()
+)
+
+// SetOffers replaces the commitment price table used by the API.
+func SetOffers(o *Offers) {
+	offersMu.Lock()
+	defer offersMu.Unlock()
+	activeOffers = o
+}
+
+func CurrentOffers() *Offers {
+	offersMu.RLock()
+	defer offersMu.RUnlock()
+	return activeOffers
+}
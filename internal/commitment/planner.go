@@ -0,0 +1,386 @@
+// Package commitment recommends reserved instance and savings plan purchases
+// from the steady-state usage in the recorded history.
+package commitment
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+)
+
+// Options controls the planner. Zero values mean a 14-day lookback and the
+// 10th percentile of hourly usage as the steady state.
+type Options struct {
+	Now        time.Time
+	Lookback   time.Duration
+	Percentile float64
+}
+
+// Plan lists reserved instance recommendations per SKU and one compute savings
+// plan recommendation covering EC2 and Lambda. The two are alternatives for
+// EC2: buying both for the same usage would double-count savings.
+type Plan struct {
+	GeneratedAt   time.Time                  `json:"generated_at"`
+	LookbackHours int                        `json:"lookback_hours"`
+	Percentile    float64                    `json:"percentile"`
+	Currency      string                     `json:"currency"`
+	Reserved      []ReservedRecommendation   `json:"reserved"`
+	SavingsPlan   *SavingsPlanRecommendation `json:"savings_plan,omitempty"`
+}
+
+// Projection is the outcome of one term and payment option over the lookback
+// usage. Amounts cover the whole recommended quantity or commitment;
+// MonthlySavings is net of the amortized upfront fee. BreakEvenMonth is the
+// first month in which cumulative savings cover what was paid, or 0 if that
+// does not happen within the term. Coverage is the share of usage the
+// commitment covers and Utilization the share of the commitment used, both
+// in percent.
+type Projection struct {
+	Term            string  `json:"term"`
+	Payment         string  `json:"payment"`
+	Upfront         float64 `json:"upfront"`
+	RecurringHourly float64 `json:"recurring_hourly"`
+	EffectiveHourly float64 `json:"effective_hourly"`
+	MonthlySavings  float64 `json:"monthly_savings"`
+	TermSavings     float64 `json:"term_savings"`
+	BreakEvenMonth  int     `json:"break_even_month"`
+	Coverage        float64 `json:"coverage"`
+	Utilization     float64 `json:"utilization"`
+}
+
+type ReservedRecommendation struct {
+	Service        string       `json:"service"`
+	Engine         string       `json:"engine,omitempty"`
+	Type           string       `json:"type"`
+	Region         string       `json:"region"`
+	Resources      []string     `json:"resources"`
+	AverageRunning float64      `json:"average_running"`
+	Quantity       int          `json:"quantity"`
+	OnDemandHourly float64      `json:"on_demand_hourly"`
+	Options        []Projection `json:"options"`
+	// Best is the option with the highest monthly savings, e.g. "3y/all_upfront".
+	Best string `json:"best,omitempty"`
+}
+
+// SavingsPlanRecommendation commits to BaselineOnDemandHourly of on-demand
+// spend per hour; each option's RecurringHourly and Upfront are the discounted
+// commitment.
+type SavingsPlanRecommendation struct {
+	Resources              []string     `json:"resources"`
+	AverageOnDemandHourly  float64      `json:"average_on_demand_hourly"`
+	BaselineOnDemandHourly float64      `json:"baseline_on_demand_hourly"`
+	Options                []Projection `json:"options"`
+	Best                   string       `json:"best,omitempty"`
+}
+
+// usage is on-demand spend or running instances per hour of the lookback.
+type usage []float64
+
+type skuKey struct {
+	service, engine, typ, region string
+}
+
+// Build recommends commitments for resources from their cost history.
+func Build(resources []models.CloudResource, hist *history.Store, catalog *pricing.Catalog, offers *Offers, opts Options) Plan {
+	if opts.Lookback <= 0 {
+		opts.Lookback = 14 * 24 * time.Hour
+	}
+	if opts.Percentile <= 0 {
+		opts.Percentile = 10
+	}
+	plan := Plan{GeneratedAt: opts.Now, Percentile: opts.Percentile, Currency: "USD", Reserved: []ReservedRecommendation{}}
+
+	series := make(map[string][]history.Sample)
+	start := opts.Now.Add(-opts.Lookback)
+	earliest := opts.Now
+	for _, r := range resources {
+		s := forecast.HourlyCost(hist, r.GetId())
+		var inWindow []history.Sample
+		for _, sample := range s {
+			if !sample.Time.Before(start) && !sample.Time.After(opts.Now) {
+				inWindow = append(inWindow, sample)
+			}
+		}
+		if len(inWindow) == 0 {
+			continue
+		}
+		series[r.GetId()] = inWindow
+		if inWindow[0].Time.Before(earliest) {
+			earliest = inWindow[0].Time
+		}
+	}
+	origin := earliest.Truncate(time.Hour)
+	hours := int(opts.Now.Sub(origin)/time.Hour) + 1
+	plan.LookbackHours = hours
+	if len(series) == 0 {
+		return plan
+	}
+	bucket := func(t time.Time) int { return int(t.Sub(origin) / time.Hour) }
+
+	running := make(map[skuKey]usage)
+	members := make(map[skuKey][]string)
+	observed := make(map[skuKey]float64)
+	spend := make(usage, hours)
+	spendByService := make(map[string]float64)
+	var spResources []string
+
+	for _, r := range resources {
+		s, ok := series[r.GetId()]
+		if !ok {
+			continue
+		}
+		var key skuKey
+		var reservable, planEligible bool
+		service := ""
+		switch v := r.(type) {
+		case *models.VM:
+			key, reservable, planEligible, service = skuKey{"ec2", "", v.InstanceType, v.Region}, v.InstanceType != "", true, "ec2"
+		case *models.Database:
+			key, reservable = skuKey{"rds", v.Engine, v.InstanceClass, v.Region}, v.InstanceClass != ""
+		case *models.Lambda:
+			planEligible, service = true, "lambda"
+		}
+
+		present := make(usage, hours)
+		sums := make(usage, hours)
+		counts := make([]int, hours)
+		for _, sample := range s {
+			i := bucket(sample.Time)
+			present[i] = 1
+			sums[i] += sample.Value
+			counts[i]++
+		}
+		if reservable {
+			if running[key] == nil {
+				running[key] = make(usage, hours)
+			}
+			for i := range present {
+				running[key][i] += present[i]
+			}
+			members[key] = append(members[key], r.GetId())
+			observed[key] = s[len(s)-1].Value
+		}
+		if planEligible {
+			spResources = append(spResources, r.GetId())
+			for i := range sums {
+				if counts[i] > 0 {
+					rate := sums[i] / float64(counts[i])
+					spend[i] += rate
+					spendByService[service] += rate
+				}
+			}
+		}
+	}
+
+	for key, counts := range running {
+		od := observed[key]
+		if p, ok := catalog.Instance(key.service, key.engine, key.typ, key.region); ok && p.OnDemandHourly > 0 {
+			od = p.OnDemandHourly
+		}
+		rec := ReservedRecommendation{
+			Service:        key.service,
+			Engine:         key.engine,
+			Type:           key.typ,
+			Region:         key.region,
+			Resources:      members[key],
+			AverageRunning: round(mean(counts)),
+			Quantity:       int(math.Floor(percentile(counts, opts.Percentile))),
+			OnDemandHourly: od,
+			Options:        []Projection{},
+		}
+		sort.Strings(rec.Resources)
+		if rec.Quantity > 0 && od > 0 {
+			for _, offer := range offers.Reserved {
+				if offer.Service != key.service {
+					continue
+				}
+				capacity := float64(rec.Quantity) * od
+				rec.Options = append(rec.Options, project(offer, scale(counts, od), capacity, 1-offer.Discount))
+			}
+			rec.Best = best(rec.Options)
+		}
+		plan.Reserved = append(plan.Reserved, rec)
+	}
+	sort.Slice(plan.Reserved, func(i, j int) bool {
+		a, b := plan.Reserved[i], plan.Reserved[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Type < b.Type
+	})
+
+	if len(spResources) > 0 {
+		sort.Strings(spResources)
+		sp := &SavingsPlanRecommendation{
+			Resources:              spResources,
+			AverageOnDemandHourly:  round(mean(spend)),
+			BaselineOnDemandHourly: round(percentile(spend, opts.Percentile)),
+			Options:                []Projection{},
+		}
+		total := spendByService["ec2"] + spendByService["lambda"]
+		if sp.BaselineOnDemandHourly > 0 && total > 0 {
+			for _, term := range []string{OneYear, ThreeYear} {
+				for _, payment := range []string{NoUpfront, PartialUpfront, AllUpfront} {
+					// Weight each service's discount by its share of the spend.
+					discount, upfront, ok := 0.0, 0.0, true
+					for _, service := range []string{"ec2", "lambda"} {
+						amount := spendByService[service]
+						if amount == 0 {
+							continue
+						}
+						d, found := offers.savingsPlanDiscount(service, term, payment)
+						if !found {
+							ok = false
+							break
+						}
+						discount += d * amount / total
+					}
+					if !ok {
+						continue
+					}
+					for _, offer := range offers.SavingsPlans {
+						if offer.Term == term && offer.Payment == payment {
+							upfront = offer.Upfront
+							break
+						}
+					}
+					offer := Offer{Term: term, Payment: payment, Discount: discount, Upfront: upfront}
+					sp.Options = append(sp.Options, project(offer, spend, sp.BaselineOnDemandHourly, 1-discount))
+				}
+			}
+			sp.Best = best(sp.Options)
+		}
+		plan.SavingsPlan = sp
+	}
+	return plan
+}
+
+// project prices a commitment covering capacity dollars of on-demand usage per
+// hour at rate (the fraction of on-demand paid) against the hourly on-demand
+// usage series.
+func project(offer Offer, demand usage, capacity, rate float64) Projection {
+	hours := float64(len(demand))
+	var covered, total float64
+	for _, d := range demand {
+		covered += math.Min(d, capacity)
+		total += d
+	}
+	termHours := TermHours(offer.Term)
+	committed := capacity * rate
+	p := Projection{
+		Term:            offer.Term,
+		Payment:         offer.Payment,
+		Upfront:         round(committed * termHours * offer.Upfront),
+		RecurringHourly: round(committed * (1 - offer.Upfront)),
+		EffectiveHourly: round(committed),
+	}
+	avoided := covered / hours
+	p.MonthlySavings = round((avoided - committed) * pricing.HoursPerMonth)
+	p.TermSavings = round((avoided - committed) * termHours)
+	if total > 0 {
+		p.Coverage = round(100 * covered / total)
+	}
+	if capacity > 0 {
+		p.Utilization = round(100 * covered / (capacity * hours))
+	}
+
+	// Each month avoids avoided*720 of on-demand and bills the recurring fee;
+	// the upfront fee is recovered from the difference.
+	monthly := (avoided - committed*(1-offer.Upfront)) * pricing.HoursPerMonth
+	termMonths := int(termHours / pricing.HoursPerMonth)
+	if monthly > 0 {
+		m := int(math.Ceil(committed * termHours * offer.Upfront / monthly))
+		if m < 1 {
+			m = 1
+		}
+		if m <= termMonths {
+			p.BreakEvenMonth = m
+		}
+	}
+	return p
+}
+
+func best(options []Projection) string {
+	bestIdx := -1
+	for i, p := range options {
+		if p.MonthlySavings > 0 && (bestIdx < 0 || p.MonthlySavings > options[bestIdx].MonthlySavings) {
+			bestIdx = i
+		}
+	}
+	if bestIdx < 0 {
+		return ""
+	}
+	return options[bestIdx].Term + "/" + options[bestIdx].Payment
+}
+
+func scale(u usage, f float64) usage {
+	out := make(usage, len(u))
+	for i, v := range u {
+		out[i] = v * f
+	}
+	return out
+}
+
+func mean(u usage) float64 {
+	if len(u) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range u {
+		sum += v
+	}
+	return sum / float64(len(u))
+}
+
+// percentile is the nearest-rank p-th percentile of u.
+func percentile(u usage, p float64) float64 {
+	if len(u) == 0 {
+		return 0
+	}
+	sorted := append(usage(nil), u...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+// Scale multiplies every amount in p by factor and labels it as currency.
+func (p *Plan) Scale(factor float64, currency string) {
+	p.Currency = currency
+	scaleOptions := func(options []Projection) {
+		for i := range options {
+			o := &options[i]
+			o.Upfront *= factor
+			o.RecurringHourly *= factor
+			o.EffectiveHourly *= factor
+			o.MonthlySavings *= factor
+			o.TermSavings *= factor
+		}
+	}
+	for i := range p.Reserved {
+		p.Reserved[i].OnDemandHourly *= factor
+		scaleOptions(p.Reserved[i].Options)
+	}
+	if p.SavingsPlan != nil {
+		p.SavingsPlan.AverageOnDemandHourly *= factor
+		p.SavingsPlan.BaselineOnDemandHourly *= factor
+		scaleOptions(p.SavingsPlan.Options)
+	}
+}
@@ -0,0 +1,62 @@
+package commitment
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+)
+
+func TestBuildRecommendsSteadyStateQuantity(t *testing.T) {
+	vms := []models.CloudResource{
+		&models.VM{ID: "vm-a", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096},
+		&models.VM{ID: "vm-b", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096},
+		&models.VM{ID: "vm-c", InstanceType: "m5.large", Region: "us-east-1", CostPerHour: 0.096},
+	}
+	hist := history.NewStore(0)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Two instances run around the clock; the third only every other hour.
+	for h := 0; h < 100; h++ {
+		ts := start.Add(time.Duration(h) * time.Hour)
+		for i, vm := range vms {
+			if i == 2 && h%2 == 1 {
+				continue
+			}
+			hist.Record(vm.GetId(), ts, vm.Metrics())
+		}
+	}
+
+	plan := Build(vms, hist, pricing.Default(), DefaultOffers(), Options{Now: start.Add(99 * time.Hour)})
+	if len(plan.Reserved) != 1 {
+		t.Fatalf("got %d reserved recommendations, want 1", len(plan.Reserved))
+	}
+	rec := plan.Reserved[0]
+	if rec.Quantity != 2 || rec.AverageRunning != 2.5 {
+		t.Fatalf("quantity/average = %d/%v, want 2/2.5", rec.Quantity, rec.AverageRunning)
+	}
+	byOption := make(map[string]Projection)
+	for _, p := range rec.Options {
+		byOption[p.Term+"/"+p.Payment] = p
+	}
+	noUpfront := byOption["1y/no_upfront"]
+	if want := (0.192 - 0.192*0.63) * 720; math.Abs(noUpfront.MonthlySavings-want) > 1e-3 {
+		t.Errorf("1y no upfront saves %v/month, want %v", noUpfront.MonthlySavings, want)
+	}
+	if noUpfront.Coverage != 80 || noUpfront.Utilization != 100 || noUpfront.BreakEvenMonth != 1 {
+		t.Errorf("coverage/utilization/break-even = %v/%v/%v, want 80/100/1", noUpfront.Coverage, noUpfront.Utilization, noUpfront.BreakEvenMonth)
+	}
+	// $1,967.81 upfront recovered at $138.24 of avoided on-demand a month.
+	if got := byOption["3y/all_upfront"].BreakEvenMonth; got != 15 {
+		t.Errorf("3y all upfront breaks even in month %d, want 15", got)
+	}
+	if rec.Best != "3y/all_upfront" {
+		t.Errorf("best = %q, want 3y/all_upfront", rec.Best)
+	}
+
+	if plan.SavingsPlan == nil || plan.SavingsPlan.BaselineOnDemandHourly != 0.192 {
+		t.Fatalf("savings plan baseline should be two instances' spend: %+v", plan.SavingsPlan)
+	}
+}
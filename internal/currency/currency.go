@@ -0,0 +1,183 @@
+// Package currency converts the USD amounts used throughout the service into
+// other currencies with a locally configured exchange-rate table.
+package currency
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// USD is the currency every cost field and saving estimate is recorded in.
+const USD = "USD"
+
+// Money is an amount in a currency.
+type Money struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// Table holds exchange rates from Base. A rate applies from its Effective date
+// (UTC) until the next rate for the same currency.
+type Table struct {
+	Base  string `yaml:"base" json:"base"`
+	Rates []Rate `yaml:"rates" json:"rates"`
+}
+
+// Rate is the number of units of Currency per unit of the table's base.
+type Rate struct {
+	Currency  string  `yaml:"currency" json:"currency"`
+	Effective string  `yaml:"effective" json:"effective"`
+	PerBase   float64 `yaml:"per_base" json:"per_base"`
+
+	from time.Time
+}
+
+//go:embed rates.yaml
+var defaultRates []byte
+
+// Default returns the sample table bundled with the binary.
+func Default() *Table {
+	t, err := Parse(defaultRates)
+	if err != nil {
+		panic("currency: invalid bundled rates: " + err.Error())
+	}
+	return t
+}
+
+// Load reads a rates file. JSON files are accepted as well as YAML.
+func Load(path string) (*Table, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse exchange rates %s: %w", path, err)
+	}
+	return t, nil
+}
+
+func Parse(b []byte) (*Table, error) {
+	var t Table
+	if err := yaml.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	if t.Base == "" {
+		t.Base = USD
+	}
+	t.Base = strings.ToUpper(t.Base)
+	for i := range t.Rates {
+		r := &t.Rates[i]
+		r.Currency = strings.ToUpper(r.Currency)
+		from, err := time.Parse("2006-01-02", r.Effective)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: effective must look like 2025-01-31: %w", i+1, err)
+		}
+		if r.PerBase <= 0 {
+			return nil, fmt.Errorf("rate %d: per_base must be positive", i+1)
+		}
+		r.from = from
+	}
+	sort.SliceStable(t.Rates, func(i, j int) bool {
+		if t.Rates[i].Currency != t.Rates[j].Currency {
+			return t.Rates[i].Currency < t.Rates[j].Currency
+		}
+		return t.Rates[i].from.Before(t.Rates[j].from)
+	})
+	return &t, nil
+}
+
+// Supports reports whether code can be converted to.
+func (t *Table) Supports(code string) bool {
+	code = strings.ToUpper(code)
+	if code == t.Base {
+		return true
+	}
+	for _, r := range t.Rates {
+		if r.Currency == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate returns the units of code per unit of base in effect at at. Before the
+// first effective date the earliest rate is used.
+func (t *Table) Rate(code string, at time.Time) (float64, error) {
+	code = strings.ToUpper(code)
+	if code == t.Base {
+		return 1, nil
+	}
+	rate, found := 0.0, false
+	for _, r := range t.Rates {
+		if r.Currency != code {
+			continue
+		}
+		if !found || !r.from.After(at) {
+			rate, found = r.PerBase, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no exchange rate for %s", code)
+	}
+	return rate, nil
+}
+
+// Convert expresses m in currency to at the rates in effect at at.
+func (t *Table) Convert(m Money, to string, at time.Time) (Money, error) {
+	to = strings.ToUpper(to)
+	from := strings.ToUpper(m.Currency)
+	if from == "" {
+		from = USD
+	}
+	if from == to {
+		return Money{Amount: m.Amount, Currency: to}, nil
+	}
+	fromRate, err := t.Rate(from, at)
+	if err != nil {
+		return Money{}, err
+	}
+	toRate, err := t.Rate(to, at)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: round(m.Amount / fromRate * toRate), Currency: to}, nil
+}
+
+// Factor is the multiplier taking USD amounts into currency to at at.
+func (t *Table) Factor(to string, at time.Time) (float64, error) {
+	m, err := t.Convert(Money{Amount: 1, Currency: USD}, to, at)
+	return m.Amount, err
+}
+
+// round keeps amounts to a millionth so converted figures do not carry float
+// noise into reports.
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+var (
+	tableMu     sync.RWMutex
+	activeTable = Default()
+)
+
+// SetTable replaces the exchange rates used by the API.
+func SetTable(t *Table) {
+	tableMu.Lock()
+	defer tableMu.Unlock()
+	activeTable = t
+}
+
+func CurrentTable() *Table {
+	tableMu.RLock()
+	defer tableMu.RUnlock()
+	return activeTable
+}
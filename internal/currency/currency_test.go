@@ -0,0 +1,48 @@
+package currency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertUsesRateInEffect(t *testing.T) {
+	table, err := Parse([]byte(`
+base: USD
+rates:
+  - {currency: EUR, effective: 2025-04-01, per_base: 0.9}
+  - {currency: EUR, effective: 2025-01-01, per_base: 0.8}
+  - {currency: INR, effective: 2025-01-01, per_base: 80}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		in   Money
+		to   string
+		at   time.Time
+		want float64
+	}{
+		{"first rate", Money{100, USD}, "EUR", time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC), 80},
+		{"later rate", Money{100, USD}, "eur", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), 90},
+		{"before any rate", Money{100, USD}, "EUR", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 80},
+		{"cross rate", Money{90, "EUR"}, "INR", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 8000},
+		{"same currency", Money{5, "INR"}, "INR", time.Time{}, 5},
+	}
+	for _, tt := range tests {
+		got, err := table.Convert(tt.in, tt.to, tt.at)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.Amount != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got.Amount, tt.want)
+		}
+	}
+	if _, err := table.Convert(Money{1, USD}, "JPY", time.Time{}); err == nil {
+		t.Error("expected an error for a currency without rates")
+	}
+	if !Default().Supports("INR") {
+		t.Error("bundled table should include INR")
+	}
+}
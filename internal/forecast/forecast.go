@@ -0,0 +1,257 @@
+// Package forecast projects spend from the cost samples kept in the usage
+// history, per resource or aggregated by owner, resource type or provider.
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+// z95 is the two-sided 95% normal quantile used for confidence intervals.
+const z95 = 1.96
+
+// Models.
+const (
+	Linear          = "linear"
+	HoltWintersName = "holt_winters"
+)
+
+// Ways to group forecasts.
+const (
+	ByResource = "resource"
+	ByOwner    = "owner"
+	ByType     = "type"
+	ByProvider = "provider"
+)
+
+// Options controls what Build projects. Zero values mean 30 and 90 days,
+// both models, and DefaultHoltWinters.
+type Options struct {
+	Now          time.Time
+	HorizonsDays []int
+	Models       []string
+	HoltWinters  HoltWinters
+}
+
+// Projection is the expected spend over the next HorizonDays with a 95%
+// confidence interval.
+type Projection struct {
+	Model       string  `json:"model"`
+	HorizonDays int     `json:"horizon_days"`
+	Spend       float64 `json:"spend"`
+	Lower       float64 `json:"lower"`
+	Upper       float64 `json:"upper"`
+	// Seasonal is set when Holt-Winters had enough data for a daily season.
+	Seasonal bool `json:"seasonal,omitempty"`
+
+	halfWidth float64
+}
+
+type Forecast struct {
+	Key       string   `json:"key"`
+	Resources []string `json:"resources"`
+	Samples   int      `json:"samples"`
+	// HistoryFrom is the start of the cost history the models were fitted on;
+	// for a group, the latest start among its members.
+	HistoryFrom   time.Time    `json:"history_from,omitempty"`
+	CurrentHourly float64      `json:"current_hourly"`
+	Projections   []Projection `json:"projections"`
+}
+
+// Subject is a resource to forecast and the attributes it can be grouped by.
+type Subject struct {
+	ID       string
+	Type     string
+	Owner    string
+	Provider string
+}
+
+func (o Options) withDefaults() Options {
+	if len(o.HorizonsDays) == 0 {
+		o.HorizonsDays = []int{30, 90}
+	}
+	if len(o.Models) == 0 {
+		o.Models = []string{Linear, HoltWintersName}
+	}
+	if o.HoltWinters == (HoltWinters{}) {
+		o.HoltWinters = DefaultHoltWinters()
+	}
+	return o
+}
+
+// ForResource projects the spend of one resource. Models that cannot be fitted
+// to its history are left out.
+func ForResource(store *history.Store, id string, opts Options) Forecast {
+	opts = opts.withDefaults()
+	series := HourlyCost(store, id)
+	f := Forecast{Key: id, Resources: []string{id}, Samples: len(series)}
+	if len(series) == 0 {
+		return f
+	}
+	f.CurrentHourly = series[len(series)-1].Value
+	f.HistoryFrom = series[0].Time
+	perHour := perHour(series)
+	for _, model := range opts.Models {
+		for _, days := range opts.HorizonsDays {
+			hours := days * 24
+			p := Projection{Model: model, HorizonDays: days}
+			var ok bool
+			switch model {
+			case Linear:
+				p.Spend, p.halfWidth, ok = linear(perHour, opts.Now, hours)
+			case HoltWintersName:
+				p.Spend, p.halfWidth, p.Seasonal, ok = opts.HoltWinters.forecast(series, opts.Now, hours)
+			}
+			if ok {
+				f.Projections = append(f.Projections, p.withInterval())
+			}
+		}
+	}
+	return f
+}
+
+// Build forecasts every subject and, unless groupBy is ByResource, sums them
+// per owner, type or provider. Group intervals assume resources vary independently. A
+// group only reports a model that could be fitted for each of its resources
+// with samples.
+func Build(store *history.Store, subjects []Subject, groupBy string, opts Options) ([]Forecast, error) {
+	if groupBy == "" {
+		groupBy = ByResource
+	}
+	var key func(Subject) string
+	switch groupBy {
+	case ByResource:
+		key = func(s Subject) string { return s.ID }
+	case ByOwner:
+		key = func(s Subject) string { return s.Owner }
+	case ByType:
+		key = func(s Subject) string { return s.Type }
+	case ByProvider:
+		key = func(s Subject) string { return s.Provider }
+	default:
+		return nil, fmt.Errorf("unknown group_by %q (want resource, owner, type or provider)", groupBy)
+	}
+
+	groups := make(map[string][]Forecast)
+	for _, s := range subjects {
+		f := ForResource(store, s.ID, opts)
+		groups[key(s)] = append(groups[key(s)], f)
+	}
+	out := make([]Forecast, 0, len(groups))
+	for k, members := range groups {
+		out = append(out, combine(k, members))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func combine(key string, members []Forecast) Forecast {
+	g := Forecast{Key: key}
+	type slot struct {
+		model string
+		days  int
+	}
+	sums := make(map[slot]*Projection)
+	counts := make(map[slot]int)
+	var order []slot
+	fitted := 0
+	for _, f := range members {
+		g.Resources = append(g.Resources, f.Resources...)
+		if f.Samples == 0 {
+			continue
+		}
+		fitted++
+		g.Samples += f.Samples
+		g.CurrentHourly += f.CurrentHourly
+		if f.HistoryFrom.After(g.HistoryFrom) {
+			g.HistoryFrom = f.HistoryFrom
+		}
+		for _, p := range f.Projections {
+			k := slot{p.Model, p.HorizonDays}
+			sum, ok := sums[k]
+			if !ok {
+				sum = &Projection{Model: p.Model, HorizonDays: p.HorizonDays, Seasonal: true}
+				sums[k] = sum
+				order = append(order, k)
+			}
+			sum.Spend += p.Spend
+			sum.halfWidth = math.Hypot(sum.halfWidth, p.halfWidth)
+			sum.Seasonal = sum.Seasonal && p.Seasonal
+			counts[k]++
+		}
+	}
+	sort.Strings(g.Resources)
+	for _, k := range order {
+		if counts[k] == fitted {
+			g.Projections = append(g.Projections, sums[k].withInterval())
+		}
+	}
+	return g
+}
+
+func (p Projection) withInterval() Projection {
+	p.Lower = math.Max(0, p.Spend-p.halfWidth)
+	p.Upper = p.Spend + p.halfWidth
+	return p
+}
+
+// Actual is the spend of id between from and to, holding each sampled rate
+// until the next sample (the last one until to).
+func Actual(store *history.Store, id string, from, to time.Time) float64 {
+	series := HourlyCost(store, id)
+	total := 0.0
+	for i, s := range series {
+		if !s.Time.Before(to) {
+			break
+		}
+		end := to
+		if i+1 < len(series) && series[i+1].Time.Before(to) {
+			end = series[i+1].Time
+		}
+		begin := s.Time
+		if begin.Before(from) {
+			begin = from
+		}
+		if end.After(begin) {
+			total += s.Value * end.Sub(begin).Hours()
+		}
+	}
+	return total
+}
+
+// perHour resamples series to one sample per hour for the linear model. Older
+// history is only kept as hourly averages, so fitting the raw samples would
+// weight recent hours more than older ones.
+func perHour(series []history.Sample) []history.Sample {
+	buckets, origin := hourly(series)
+	out := make([]history.Sample, len(buckets))
+	for i, v := range buckets {
+		out[i] = history.Sample{Time: origin.Add(time.Duration(i) * time.Hour), Value: v}
+	}
+	return out
+}
+
+// Projected is the linear projection of id's spend over the given hours after
+// now, as ForResource makes it, or 0 without history.
+func Projected(store *history.Store, id string, now time.Time, hours int) float64 {
+	if hours <= 0 {
+		return 0
+	}
+	spend, _, _ := linear(perHour(HourlyCost(store, id)), now, hours)
+	return spend
+}
+
+// Scale multiplies every amount in f by factor, e.g. to convert it from USD.
+func (f *Forecast) Scale(factor float64) {
+	f.CurrentHourly *= factor
+	for i := range f.Projections {
+		p := &f.Projections[i]
+		p.Spend *= factor
+		p.Lower *= factor
+		p.Upper *= factor
+	}
+}
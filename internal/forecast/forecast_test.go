@@ -0,0 +1,115 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestLinearProjectsTrend(t *testing.T) {
+	store := history.NewStore(0)
+	// $1/h rising by $0.01 every hour, sampled hourly for 100 hours.
+	for i := 0; i < 100; i++ {
+		store.Record("vm-1", start.Add(time.Duration(i)*time.Hour), map[string]float64{HourlyCostMetric: 1 + 0.01*float64(i)})
+	}
+	now := start.Add(99 * time.Hour)
+	f := ForResource(store, "vm-1", Options{Now: now, HorizonsDays: []int{30}, Models: []string{Linear}})
+	if len(f.Projections) != 1 {
+		t.Fatalf("got %d projections, want 1", len(f.Projections))
+	}
+	want := 0.0
+	for h := 1; h <= 720; h++ {
+		want += 1 + 0.01*float64(99+h)
+	}
+	p := f.Projections[0]
+	if math.Abs(p.Spend-want) > 1e-6 {
+		t.Errorf("spend = %v, want %v", p.Spend, want)
+	}
+	if p.Upper-p.Lower > 1e-6 {
+		t.Errorf("exact line should have no interval, got [%v, %v]", p.Lower, p.Upper)
+	}
+}
+
+func TestHoltWintersFollowsDailySeason(t *testing.T) {
+	store := history.NewStore(0)
+	// A flat $2/h with a daily swing of +-$1 over two weeks.
+	for i := 0; i < 14*24; i++ {
+		rate := 2 + math.Sin(2*math.Pi*float64(i%24)/24)
+		store.Record("db-1", start.Add(time.Duration(i)*time.Hour), map[string]float64{HourlyCostMetric: rate})
+	}
+	now := start.Add(14*24*time.Hour - time.Hour)
+	f := ForResource(store, "db-1", Options{Now: now, HorizonsDays: []int{30}, Models: []string{HoltWintersName}})
+	if len(f.Projections) != 1 || !f.Projections[0].Seasonal {
+		t.Fatalf("want one seasonal projection, got %+v", f.Projections)
+	}
+	// Whole days of the season average out to the flat rate.
+	if got, want := f.Projections[0].Spend, 2.0*720; math.Abs(got-want)/want > 0.01 {
+		t.Errorf("spend = %v, want about %v", got, want)
+	}
+}
+
+func TestBuildGroupsByOwnerAndDifferencesLambda(t *testing.T) {
+	store := history.NewStore(0)
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * time.Hour)
+		store.Record("vm-1", ts, map[string]float64{HourlyCostMetric: 1})
+		store.Record("vm-2", ts, map[string]float64{HourlyCostMetric: 2})
+		// Cumulative Lambda spend growing by $0.5 an hour.
+		store.Record("fn-1", ts, map[string]float64{TotalCostMetric: 0.5 * float64(i)})
+	}
+	subjects := []Subject{
+		{ID: "vm-1", Type: "VM", Owner: "team-a", Provider: "aws"},
+		{ID: "vm-2", Type: "VM", Owner: "team-b", Provider: "gcp"},
+		{ID: "fn-1", Type: "Lambda", Owner: "team-a", Provider: "aws"},
+	}
+	opts := Options{Now: start.Add(9 * time.Hour), HorizonsDays: []int{1}, Models: []string{Linear}}
+	got, err := Build(store, subjects, ByOwner, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Key != "team-a" || got[1].Key != "team-b" {
+		t.Fatalf("unexpected groups: %+v", got)
+	}
+	if spend := got[0].Projections[0].Spend; math.Abs(spend-1.5*24) > 1e-9 {
+		t.Errorf("team-a spend = %v, want %v", spend, 1.5*24)
+	}
+	byProvider, err := Build(store, subjects, ByProvider, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byProvider) != 2 || byProvider[0].Key != "aws" || len(byProvider[0].Resources) != 2 {
+		t.Errorf("unexpected provider groups: %+v", byProvider)
+	}
+	if _, err := Build(store, subjects, "region", opts); err == nil {
+		t.Error("expected an error for an unknown group_by")
+	}
+}
+
+func TestSpendOutlivesRawSamples(t *testing.T) {
+	store := history.NewStore(0)
+	// Seven hours at one sample per second, as the live loop records: $3/h for
+	// the first two hours, then $1/h. The raw samples only reach back ~5.6h.
+	for i := 0; i < 7*3600; i++ {
+		rate := 1.0
+		if i < 2*3600 {
+			rate = 3
+		}
+		store.Record("vm-1", start.Add(time.Duration(i)*time.Second), map[string]float64{HourlyCostMetric: rate})
+	}
+	end := start.Add(7 * time.Hour)
+	if got := Actual(store, "vm-1", start, end); math.Abs(got-11) > 0.01 {
+		t.Errorf("actual = %v, want 11", got)
+	}
+	f := ForResource(store, "vm-1", Options{Now: end, HorizonsDays: []int{1}, Models: []string{Linear}})
+	if !f.HistoryFrom.Equal(start) {
+		t.Errorf("history_from = %v, want %v", f.HistoryFrom, start)
+	}
+	// Budgets project with Projected, which must agree with the API's forecast.
+	if got, want := Projected(store, "vm-1", end, 24), f.Projections[0].Spend; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Projected = %v, ForResource = %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package forecast
+
+import (
+	"math"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+// HoltWinters holds the smoothing factors of the additive Holt-Winters model.
+// Season is in hours; series shorter than two seasons are fitted without one
+// (Holt's linear trend).
+type HoltWinters struct {
+	Alpha  float64 `json:"alpha"`
+	Beta   float64 `json:"beta"`
+	Gamma  float64 `json:"gamma"`
+	Season int     `json:"season"`
+}
+
+func DefaultHoltWinters() HoltWinters {
+	return HoltWinters{Alpha: 0.3, Beta: 0.01, Gamma: 0.1, Season: 24}
+}
+
+// hourly averages series into one value per hour, carrying the previous value
+// into hours without samples.
+func hourly(series []history.Sample) ([]float64, time.Time) {
+	if len(series) == 0 {
+		return nil, time.Time{}
+	}
+	origin := series[0].Time.Truncate(time.Hour)
+	n := int(series[len(series)-1].Time.Sub(origin)/time.Hour) + 1
+	sums := make([]float64, n)
+	counts := make([]int, n)
+	for _, s := range series {
+		i := int(s.Time.Sub(origin) / time.Hour)
+		if i < 0 || i >= n {
+			continue
+		}
+		sums[i] += s.Value
+		counts[i]++
+	}
+	buckets := make([]float64, n)
+	for i := range buckets {
+		switch {
+		case counts[i] > 0:
+			buckets[i] = sums[i] / float64(counts[i])
+		case i > 0:
+			buckets[i] = buckets[i-1]
+		}
+	}
+	return buckets, origin
+}
+
+// forecast fits the model to the hourly series and sums the forecast, floored
+// at zero, over each hour of the horizon starting at now. The interval sums the
+// usual h-step variance approximation sigma^2 * (1 + sum (alpha + j*alpha*beta)^2)
+// over the horizon, treating the steps as independent.
+func (p HoltWinters) forecast(series []history.Sample, now time.Time, hours int) (spend, halfWidth float64, seasonal, ok bool) {
+	y, origin := hourly(series)
+	n := len(y)
+	if n < 2 {
+		return 0, 0, false, false
+	}
+	m := p.Season
+	seasonal = m > 1 && n >= 2*m
+
+	var level, trend float64
+	season := make([]float64, max(m, 1))
+	first := 1
+	if seasonal {
+		var s1, s2 float64
+		for i := 0; i < m; i++ {
+			s1 += y[i]
+			s2 += y[m+i]
+		}
+		level = s1 / float64(m)
+		trend = (s2 - s1) / float64(m*m)
+		for i := 0; i < m; i++ {
+			season[i] = y[i] - level
+		}
+		first = m
+	} else {
+		level, trend = y[0], y[1]-y[0]
+	}
+	seasonAt := func(t int) float64 {
+		if !seasonal {
+			return 0
+		}
+		return season[t%m]
+	}
+
+	var sse float64
+	for t := first; t < n; t++ {
+		predicted := level + trend + seasonAt(t)
+		sse += (y[t] - predicted) * (y[t] - predicted)
+		prevLevel := level
+		level = p.Alpha*(y[t]-seasonAt(t)) + (1-p.Alpha)*(level+trend)
+		trend = p.Beta*(level-prevLevel) + (1-p.Beta)*trend
+		if seasonal {
+			season[t%m] = p.Gamma*(y[t]-level) + (1-p.Gamma)*season[t%m]
+		}
+	}
+	sigma := 0.0
+	if steps := n - first; steps > 1 {
+		sigma = math.Sqrt(sse / float64(steps-1))
+	}
+
+	// The last bucket is hour n-1; the horizon starts after now.
+	gap := int(now.Sub(origin)/time.Hour) - (n - 1)
+	if gap < 0 {
+		gap = 0
+	}
+	var variance, stepFactor float64
+	for h := 1; h <= gap+hours; h++ {
+		if h > 1 {
+			c := p.Alpha + float64(h-1)*p.Alpha*p.Beta
+			stepFactor += c * c
+		}
+		if h <= gap {
+			continue
+		}
+		spend += math.Max(0, level+float64(h)*trend+seasonAt(n-1+h))
+		variance += sigma * sigma * (1 + stepFactor)
+	}
+	return spend, z95 * math.Sqrt(variance), seasonal, true
+}
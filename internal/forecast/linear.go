@@ -0,0 +1,61 @@
+package forecast
+
+import (
+	"math"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+// linear fits rate = a + b*t by least squares over the samples and sums the
+// fitted rate, floored at zero, over each hour of the horizon starting at now.
+// The interval combines the uncertainty of the fitted line at mid-horizon with
+// hour-to-hour noise around it.
+func linear(series []history.Sample, now time.Time, hours int) (spend, halfWidth float64, ok bool) {
+	n := len(series)
+	if n == 0 {
+		return 0, 0, false
+	}
+	origin := series[0].Time
+	var sumX, sumY float64
+	for _, s := range series {
+		sumX += s.Time.Sub(origin).Hours()
+		sumY += s.Value
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+	var sxx, sxy float64
+	for _, s := range series {
+		dx := s.Time.Sub(origin).Hours() - meanX
+		sxx += dx * dx
+		sxy += dx * (s.Value - meanY)
+	}
+	slope := 0.0
+	if sxx > 0 {
+		slope = sxy / sxx
+	}
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for _, s := range series {
+		r := s.Value - (intercept + slope*s.Time.Sub(origin).Hours())
+		sse += r * r
+	}
+	sigma := 0.0
+	if n > 2 {
+		sigma = math.Sqrt(sse / float64(n-2))
+	}
+
+	start := now.Sub(origin).Hours()
+	for h := 1; h <= hours; h++ {
+		spend += math.Max(0, intercept+slope*(start+float64(h)))
+	}
+
+	mid := start + float64(hours)/2
+	seMean := sigma * math.Sqrt(1/float64(n))
+	if sxx > 0 {
+		seMean = sigma * math.Sqrt(1/float64(n)+(mid-meanX)*(mid-meanX)/sxx)
+	}
+	h := float64(hours)
+	halfWidth = z95 * math.Sqrt(h*seMean*h*seMean+h*sigma*sigma)
+	return spend, halfWidth, true
+}
@@ -0,0 +1,37 @@
+package forecast
+
+import (
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// History metrics holding spend, as reported by each resource's Metrics.
+// Resources billed by the hour or by the GB-month record their current rate;
+// Lambda records the cumulative cost of its invocations, which HourlyCost turns
+// into a rate.
+const (
+	HourlyCostMetric = models.MetricCostPerHour
+	TotalCostMetric  = models.MetricCostTotal
+)
+
+// HourlyCost returns the spend rate series of id in USD per hour, oldest first.
+func HourlyCost(store *history.Store, id string) []history.Sample {
+	if series := store.Series(id, HourlyCostMetric); len(series) > 0 {
+		return series
+	}
+	totals := store.Series(id, TotalCostMetric)
+	var rates []history.Sample
+	for i := 1; i < len(totals); i++ {
+		hours := totals[i].Time.Sub(totals[i-1].Time).Hours()
+		if hours <= 0 {
+			continue
+		}
+		rate := (totals[i].Value - totals[i-1].Value) / hours
+		if rate < 0 {
+			// Counter reset; the previous total no longer applies.
+			continue
+		}
+		rates = append(rates, history.Sample{Time: totals[i].Time, Value: rate})
+	}
+	return rates
+}
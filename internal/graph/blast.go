@@ -0,0 +1,47 @@
+package graph
+
+import "github.com/chanducheryala/cloud-resource/internal/models"
+
+// Impact is a resource affected by removing another one, and how.
+type Impact struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Kind         string `json:"kind"`
+	// Effect completes a sentence starting with the removal, e.g. "removes a
+	// healthy host from elb-1".
+	Effect string `json:"effect"`
+}
+
+// BlastRadius returns the resources that refer to id and so lose something if
+// it is removed. Only direct dependents are listed.
+func (g *Graph) BlastRadius(id string) []Impact {
+	var out []Impact
+	for _, e := range g.in[id] {
+		imp := Impact{ResourceID: e.From, Kind: e.Kind, Effect: effect(e)}
+		if r, ok := g.nodes[e.From]; ok {
+			imp.ResourceType = r.GetType()
+		}
+		out = append(out, imp)
+	}
+	return out
+}
+
+func effect(e Edge) string {
+	switch e.Kind {
+	case models.RoutesTo:
+		return "removes a healthy host from " + e.From
+	case models.AttachedTo:
+		return "leaves volume " + e.From + " unattached"
+	case models.SnapshotOf:
+		return "leaves snapshot " + e.From + " without its source volume"
+	case models.AssociatedWith:
+		return "leaves Elastic IP " + e.From + " unassociated"
+	case models.MemberOf:
+		return "deletes node group " + e.From
+	case models.ReadsFrom:
+		return "breaks reads by " + e.From
+	case models.WritesTo:
+		return "breaks writes by " + e.From
+	}
+	return "affects " + e.From + " (" + e.Kind + ")"
+}
@@ -0,0 +1,159 @@
+// Package graph links resources through the references they hold, such as a
+// volume's VM or a load balancer's targets, so that callers can find what a
+// resource depends on, what depends on it, and references to resources that
+// no longer exist.
+package graph
+
+import (
+	"sort"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// Edge is a typed reference From one resource To another, e.g. an ELB
+// routes_to a VM. Kind is one of the models relation kinds.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is built once from an inventory and is safe for concurrent reads.
+type Graph struct {
+	nodes map[string]models.CloudResource
+	order []string
+	out   map[string][]Edge
+	in    map[string][]Edge
+}
+
+// Build links every resource to the resources it refers to. References to IDs
+// missing from resources are kept as dangling edges.
+func Build(resources []models.CloudResource) *Graph {
+	g := &Graph{
+		nodes: make(map[string]models.CloudResource, len(resources)),
+		out:   make(map[string][]Edge),
+		in:    make(map[string][]Edge),
+	}
+	for _, r := range resources {
+		if _, dup := g.nodes[r.GetId()]; !dup {
+			g.order = append(g.order, r.GetId())
+		}
+		g.nodes[r.GetId()] = r
+	}
+	for _, id := range g.order {
+		for _, rel := range models.RelationsOf(g.nodes[id]) {
+			if rel.ID == "" {
+				continue
+			}
+			e := Edge{From: id, To: rel.ID, Kind: rel.Kind}
+			g.out[id] = append(g.out[id], e)
+			g.in[rel.ID] = append(g.in[rel.ID], e)
+		}
+	}
+	return g
+}
+
+// Resource returns the resource with id, if it is in the graph.
+func (g *Graph) Resource(id string) (models.CloudResource, bool) {
+	r, ok := g.nodes[id]
+	return r, ok
+}
+
+// Missing reports whether id is referenced by some resource but is not itself
+// in the graph. It is false for an empty id.
+func (g *Graph) Missing(id string) bool {
+	if id == "" {
+		return false
+	}
+	_, ok := g.nodes[id]
+	return !ok
+}
+
+// Outgoing returns the references id holds, in the order the resource lists them.
+func (g *Graph) Outgoing(id string) []Edge {
+	return g.out[id]
+}
+
+// Incoming returns the references other resources hold to id.
+func (g *Graph) Incoming(id string) []Edge {
+	return g.in[id]
+}
+
+// Orphaned reports whether id holds references and all of them dangle.
+func (g *Graph) Orphaned(id string) bool {
+	edges := g.out[id]
+	if len(edges) == 0 {
+		return false
+	}
+	for _, e := range edges {
+		if !g.Missing(e.To) {
+			return false
+		}
+	}
+	return true
+}
+
+// Node is a resource in a View. Missing marks a referenced resource that does
+// not exist.
+type Node struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type,omitempty"`
+	Owner       string  `json:"owner,omitempty"`
+	MonthlyCost float64 `json:"monthly_cost_usd"`
+	Missing     bool    `json:"missing,omitempty"`
+	Distance    int     `json:"distance"`
+}
+
+// View is the part of the graph within some number of hops of Root,
+// following edges in either direction.
+type View struct {
+	Root  string `json:"root"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Neighborhood returns the resources within depth hops of id. It returns
+// false if id is not in the graph.
+func (g *Graph) Neighborhood(id string, depth int) (View, bool) {
+	if _, ok := g.nodes[id]; !ok {
+		return View{}, false
+	}
+	view := View{Root: id, Nodes: []Node{}, Edges: []Edge{}}
+	dist := map[string]int{id: 0}
+	seenEdge := make(map[Edge]bool)
+	frontier := []string{id}
+	for d := 1; d <= depth && len(frontier) > 0; d++ {
+		var next []string
+		for _, cur := range frontier {
+			for _, e := range append(append([]Edge(nil), g.out[cur]...), g.in[cur]...) {
+				if !seenEdge[e] {
+					seenEdge[e] = true
+					view.Edges = append(view.Edges, e)
+				}
+				other := e.To
+				if other == cur {
+					other = e.From
+				}
+				if _, ok := dist[other]; !ok {
+					dist[other] = d
+					next = append(next, other)
+				}
+			}
+		}
+		frontier = next
+	}
+	for nid, d := range dist {
+		n := Node{ID: nid, Distance: d, Missing: g.Missing(nid)}
+		if r, ok := g.nodes[nid]; ok {
+			n.Type, n.Owner, n.MonthlyCost = r.GetType(), r.GetOwner(), r.GetMonthlyCost()
+		}
+		view.Nodes = append(view.Nodes, n)
+	}
+	sort.Slice(view.Nodes, func(i, j int) bool {
+		if view.Nodes[i].Distance != view.Nodes[j].Distance {
+			return view.Nodes[i].Distance < view.Nodes[j].Distance
+		}
+		return view.Nodes[i].ID < view.Nodes[j].ID
+	})
+	return view, true
+}
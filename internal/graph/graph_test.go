@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func testGraph() *Graph {
+	return Build([]models.CloudResource{
+		&models.VM{ID: "vm-1"},
+		&models.VM{ID: "vm-2"},
+		&models.ELB{ID: "elb-1", Targets: []string{"vm-1", "vm-2"}},
+		&models.EBSVolume{ID: "vol-1", AttachedTo: "vm-1"},
+		&models.EBSSnapshot{ID: "snap-1", VolumeID: "vol-1"},
+		&models.EBSSnapshot{ID: "snap-2", VolumeID: "vol-0"},
+		&models.Lambda{ID: "fn-1", ReadsFrom: []string{"s3-gone"}, WritesTo: []string{"ddb-1"}},
+		&models.DynamoDB{ID: "ddb-1"},
+	})
+}
+
+func TestBlastRadius(t *testing.T) {
+	g := testGraph()
+	got := g.BlastRadius("vm-1")
+	if len(got) != 2 {
+		t.Fatalf("blast radius of vm-1 = %+v, want elb-1 and vol-1", got)
+	}
+	if got[0].ResourceID != "elb-1" || got[0].Effect != "removes a healthy host from elb-1" || got[0].ResourceType != "ELB" {
+		t.Errorf("first impact = %+v", got[0])
+	}
+	if got[1].ResourceID != "vol-1" || got[1].Kind != models.AttachedTo {
+		t.Errorf("second impact = %+v", got[1])
+	}
+	if got := g.BlastRadius("snap-1"); len(got) != 0 {
+		t.Errorf("nothing depends on a snapshot, got %+v", got)
+	}
+}
+
+func TestOrphaned(t *testing.T) {
+	g := testGraph()
+	for id, want := range map[string]bool{
+		"snap-2": true,  // its only reference dangles
+		"snap-1": false, // vol-1 exists
+		"fn-1":   false, // one of two references still resolves
+		"vm-1":   false, // holds no references
+	} {
+		if got := g.Orphaned(id); got != want {
+			t.Errorf("Orphaned(%s) = %v, want %v", id, got, want)
+		}
+	}
+	if !g.Missing("vol-0") || g.Missing("vol-1") || g.Missing("") {
+		t.Error("Missing disagrees with the inventory")
+	}
+}
+
+func TestNeighborhood(t *testing.T) {
+	g := testGraph()
+	if _, ok := g.Neighborhood("vol-0", 1); ok {
+		t.Error("a missing resource has no neighborhood")
+	}
+	view, ok := g.Neighborhood("vol-1", 1)
+	if !ok {
+		t.Fatal("vol-1 not found")
+	}
+	ids := func(v View) []string {
+		var out []string
+		for _, n := range v.Nodes {
+			out = append(out, n.ID)
+		}
+		return out
+	}
+	if got := ids(view); len(got) != 3 || got[0] != "vol-1" || got[1] != "snap-1" || got[2] != "vm-1" {
+		t.Errorf("depth 1 nodes = %v, want [vol-1 snap-1 vm-1]", got)
+	}
+	view, _ = g.Neighborhood("vol-1", 2)
+	if got := ids(view); len(got) != 4 || got[3] != "elb-1" {
+		t.Errorf("depth 2 nodes = %v, want elb-1 at distance 2", got)
+	}
+	if len(view.Edges) != 3 {
+		t.Errorf("depth 2 edges = %+v, want 3", view.Edges)
+	}
+}
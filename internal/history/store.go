@@ -0,0 +1,285 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSamples bounds the raw samples kept per series, which rightsizing
+// percentiles and spot volatility read. The live loop records a sample every
+// one-second tick, so this is about 5.6 hours of raw data.
+const DefaultMaxSamples = 20160
+
+// DefaultRetention is how long hourly averages are kept once raw samples are
+// gone: a whole calendar month for budgets and chargeback, and the 90-day
+// history the longest default forecast horizon is fitted on.
+const DefaultRetention = 92 * 24 * time.Hour
+
+type Sample struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// bucket accumulates the samples of one hour.
+type bucket struct {
+	start time.Time
+	sum   float64
+	n     int
+}
+
+func (b bucket) sample() Sample {
+	return Sample{Time: b.start, Value: b.sum / float64(b.n)}
+}
+
+// Store keeps, per resource and metric name, the newest raw samples and an
+// hourly average for every hour of the retention window.
+type Store struct {
+	mu        sync.RWMutex
+	max       int
+	retention time.Duration
+	series    map[string]map[string][]Sample
+	hourly    map[string]map[string][]bucket
+}
+
+func NewStore(maxSamples int) *Store {
+	if maxSamples <= 0 {
+		maxSamples = DefaultMaxSamples
+	}
+	return &Store{
+		max:       maxSamples,
+		retention: DefaultRetention,
+		series:    make(map[string]map[string][]Sample),
+		hourly:    make(map[string]map[string][]bucket),
+	}
+}
+
+var defaultStore = NewStore(DefaultMaxSamples)
+
+// Default is the store fed by the live simulation.
+func Default() *Store {
+	return defaultStore
+}
+
+// Record appends one sample per metric for resource id at t.
+func (s *Store) Record(id string, t time.Time, metrics map[string]float64) {
+	if len(metrics) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byMetric, ok := s.series[id]
+	if !ok {
+		byMetric = make(map[string][]Sample)
+		s.series[id] = byMetric
+		s.hourly[id] = make(map[string][]bucket)
+	}
+	for name, v := range metrics {
+		series := append(byMetric[name], Sample{Time: t, Value: v})
+		// Compact only once the slice holds twice the bound, so that trimming
+		// costs O(1) per sample rather than a copy of the whole series.
+		if len(series) > 2*s.max {
+			series = append(make([]Sample, 0, 2*s.max), series[len(series)-s.max:]...)
+		}
+		byMetric[name] = series
+		s.hourly[id][name] = s.addHourly(s.hourly[id][name], t, v)
+	}
+}
+
+// addHourly adds v at t to the hourly averages in buckets and drops the hours
+// that fall out of the retention window.
+func (s *Store) addHourly(buckets []bucket, t time.Time, v float64) []bucket {
+	start := t.Truncate(time.Hour)
+	i := sort.Search(len(buckets), func(i int) bool { return !buckets[i].start.Before(start) })
+	switch {
+	case i < len(buckets) && buckets[i].start.Equal(start):
+		buckets[i].sum += v
+		buckets[i].n++
+		return buckets
+	case i == len(buckets):
+		buckets = append(buckets, bucket{start: start, sum: v, n: 1})
+	default:
+		buckets = append(buckets, bucket{})
+		copy(buckets[i+1:], buckets[i:])
+		buckets[i] = bucket{start: start, sum: v, n: 1}
+	}
+	oldest := buckets[len(buckets)-1].start.Add(-s.retention)
+	drop := sort.Search(len(buckets), func(i int) bool { return !buckets[i].start.Before(oldest) })
+	return buckets[drop:]
+}
+
+// window returns the newest s.max samples of series, which may hold up to
+// twice as many between compactions.
+func (s *Store) window(series []Sample) []Sample {
+	if len(series) > s.max {
+		return series[len(series)-s.max:]
+	}
+	return series
+}
+
+// Series returns the retained history of id and metric, oldest first: an
+// hourly average for each hour before the raw samples, then the raw samples.
+// An hour the raw samples only partly cover is represented by its average,
+// which then stands for the time before the first raw sample.
+func (s *Store) Series(id, metric string) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	raw := s.window(s.series[id][metric])
+	var out []Sample
+	for _, b := range s.hourly[id][metric] {
+		if len(raw) > 0 && !b.start.Before(raw[0].Time) {
+			break
+		}
+		out = append(out, b.sample())
+	}
+	return append(out, raw...)
+}
+
+// Values returns the raw samples of id and metric, oldest first.
+func (s *Store) Values(id, metric string) []float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	series := s.window(s.series[id][metric])
+	values := make([]float64, len(series))
+	for i, sample := range series {
+		values[i] = sample.Value
+	}
+	return values
+}
+
+// Percentile returns the nearest-rank p-th percentile (0-100) of the raw
+// samples of a series and the number of samples it was computed from.
+func (s *Store) Percentile(id, metric string, p float64) (float64, int) {
+	values := s.Values(id, metric)
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(p/100*float64(len(values)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(values) {
+		rank = len(values) - 1
+	}
+	return values[rank], len(values)
+}
+
+// IDs lists the resources with recorded samples, sorted.
+func (s *Store) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.series))
+	for id := range s.series {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Oldest returns the time of the oldest retained sample or hourly average, or
+// the zero time when the store is empty.
+func (s *Store) Oldest() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var oldest time.Time
+	earlier := func(t time.Time) {
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	for id, byMetric := range s.series {
+		for name, series := range byMetric {
+			if raw := s.window(series); len(raw) > 0 {
+				earlier(raw[0].Time)
+			}
+			if buckets := s.hourly[id][name]; len(buckets) > 0 {
+				earlier(buckets[0].start)
+			}
+		}
+	}
+	return oldest
+}
+
+// Export returns every series compacted to its hourly averages, keyed by
+// resource id and metric. It is a fraction of the size of the raw samples and
+// still covers the whole retention window.
+func (s *Store) Export() map[string]map[string][]Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]map[string][]Sample, len(s.hourly))
+	for id, byMetric := range s.hourly {
+		out[id] = make(map[string][]Sample, len(byMetric))
+		for name, buckets := range byMetric {
+			samples := make([]Sample, len(buckets))
+			for i, b := range buckets {
+				samples[i] = b.sample()
+			}
+			out[id][name] = samples
+		}
+	}
+	return out
+}
+
+// ExportRecent returns a copy of the newest n raw samples of every series,
+// keyed by resource id and metric.
+func (s *Store) ExportRecent(n int) map[string]map[string][]Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]map[string][]Sample, len(s.series))
+	for id, byMetric := range s.series {
+		out[id] = make(map[string][]Sample, len(byMetric))
+		for name, series := range byMetric {
+			raw := s.window(series)
+			if len(raw) > n {
+				raw = raw[len(raw)-n:]
+			}
+			out[id][name] = append([]Sample(nil), raw...)
+		}
+	}
+	return out
+}
+
+// Import replaces the store's contents with hourly, as returned by Export, and
+// recent, as returned by ExportRecent. Samples in hourly are averaged per hour
+// and only feed the hourly averages; recent becomes the raw samples, keeping
+// the newest of any series longer than the store's bound.
+func (s *Store) Import(hourly, recent map[string]map[string][]Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = make(map[string]map[string][]Sample, len(hourly))
+	s.hourly = make(map[string]map[string][]bucket, len(hourly))
+	ensure := func(id string) {
+		if _, ok := s.series[id]; !ok {
+			s.series[id] = make(map[string][]Sample)
+			s.hourly[id] = make(map[string][]bucket)
+		}
+	}
+	for id, byMetric := range hourly {
+		ensure(id)
+		for name, samples := range byMetric {
+			var buckets []bucket
+			for _, sample := range samples {
+				buckets = s.addHourly(buckets, sample.Time, sample.Value)
+			}
+			s.hourly[id][name] = buckets
+		}
+	}
+	for id, byMetric := range recent {
+		ensure(id)
+		for name, samples := range byMetric {
+			if len(samples) > s.max {
+				samples = samples[len(samples)-s.max:]
+			}
+			s.series[id][name] = append([]Sample(nil), samples...)
+		}
+	}
+}
+
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = make(map[string]map[string][]Sample)
+	s.hourly = make(map[string]map[string][]bucket)
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordKeepsNewestSamples(t *testing.T) {
+	s := NewStore(3)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		s.Record("vm-1", start.Add(time.Duration(i)*time.Second), map[string]float64{"cpu": float64(i)})
+		if got := len(s.Values("vm-1", "cpu")); got != min(i+1, 3) {
+			t.Fatalf("after %d samples %d raw samples are kept", i+1, got)
+		}
+	}
+	if got := s.Values("vm-1", "cpu"); got[0] != 7 || got[2] != 9 {
+		t.Errorf("values = %v, want [7 8 9]", got)
+	}
+	// The ten samples fall within one hour, exported as their average.
+	if got := s.Export()["vm-1"]["cpu"]; len(got) != 1 || got[0].Value != 4.5 {
+		t.Errorf("exported %v, want one hourly average of 4.5", got)
+	}
+}
+
+func TestSeriesKeepsHourlyAveragesBeyondRawSamples(t *testing.T) {
+	s := NewStore(0)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	// Eight hours at one sample per second, more than the raw bound holds.
+	for i := 0; i < 8*3600; i++ {
+		v := 1.0
+		if i < 3600 {
+			v = 3
+		}
+		s.Record("vm-1", start.Add(time.Duration(i)*time.Second), map[string]float64{"cost_per_hour": v})
+	}
+	series := s.Series("vm-1", "cost_per_hour")
+	if !series[0].Time.Equal(start) || series[0].Value != 3 {
+		t.Errorf("oldest sample = %+v, want the first hour's average of 3 at %v", series[0], start)
+	}
+	raw := s.Values("vm-1", "cost_per_hour")
+	if len(raw) != DefaultMaxSamples {
+		t.Errorf("%d raw samples, want %d", len(raw), DefaultMaxSamples)
+	}
+	for i := 1; i < len(series); i++ {
+		if !series[i].Time.After(series[i-1].Time) {
+			t.Fatalf("series not in order at %d: %v after %v", i, series[i].Time, series[i-1].Time)
+		}
+	}
+
+	// Hours beyond the retention window are dropped.
+	s.Record("vm-1", start.Add(DefaultRetention+time.Hour), map[string]float64{"cost_per_hour": 1})
+	if got := s.Series("vm-1", "cost_per_hour")[0].Time; !got.After(start) {
+		t.Errorf("oldest sample at %v after the retention window passed", got)
+	}
+}
+
+func TestImportKeepsRawPercentiles(t *testing.T) {
+	src := NewStore(100)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	// Two hours of a spiky series: the hourly averages flatten the peaks.
+	for i := 0; i < 2*60; i++ {
+		v := 10.0
+		if i%10 == 0 {
+			v = 90
+		}
+		src.Record("vm-1", start.Add(time.Duration(i)*time.Minute), map[string]float64{"cpu": v})
+	}
+	wantP, wantN := src.Percentile("vm-1", "cpu", 95)
+
+	dst := NewStore(100)
+	dst.Import(src.Export(), src.ExportRecent(100))
+	if p, n := dst.Percentile("vm-1", "cpu", 95); p != wantP || n != wantN {
+		t.Errorf("p95 after import = %v over %d samples, want %v over %d", p, n, wantP, wantN)
+	}
+	if got, want := dst.Series("vm-1", "cpu"), src.Series("vm-1", "cpu"); len(got) != len(want) || !got[0].Time.Equal(want[0].Time) {
+		t.Errorf("series after import has %d samples from %v, want %d from %v", len(got), got[0].Time, len(want), want[0].Time)
+	}
+
+	// Without raw samples only the hourly averages are restored.
+	hourlyOnly := NewStore(100)
+	hourlyOnly.Import(src.Export(), nil)
+	if _, n := hourlyOnly.Percentile("vm-1", "cpu", 95); n != 0 {
+		t.Errorf("%d raw samples imported from hourly averages, want 0", n)
+	}
+	if got := hourlyOnly.IDs(); len(got) != 1 {
+		t.Errorf("ids = %v, want [vm-1]", got)
+	}
+}
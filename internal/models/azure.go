@@ -0,0 +1,261 @@
+package models
+
+import (
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+func (vm *AzureVM) UpdateUsage() {
+	vm.CPUUsage = sim.Float64() * 100
+	vm.MemoryUsage = 20 + sim.Float64()*40
+	if sim.Float64() < 0.2 {
+		vm.LastActive = sim.Now().Unix()
+	}
+}
+
+func (vm *AzureVM) GetId() string {
+	return vm.ID
+}
+
+func (vm *AzureVM) GetUsage() float64 {
+	return vm.CPUUsage
+}
+
+func (vm *AzureVM) GetType() string {
+	return "AzureVM"
+}
+
+func (vm *AzureVM) GetTags() map[string]string {
+	return vm.Tags
+}
+
+func (vm *AzureVM) GetOwner() string {
+	return vm.Owner
+}
+
+func (vm *AzureVM) GetRegion() string {
+	return vm.Region
+}
+
+func (vm *AzureVM) GetProvider() string {
+	return ProviderAzure
+}
+
+func (vm *AzureVM) GetAccount() string {
+	return vm.Subscription
+}
+
+func (vm *AzureVM) GetMonthlyCost() float64 {
+	return vm.CostPerHour * hoursPerMonth
+}
+
+func (vm *AzureVM) GetCreatedAt() time.Time {
+	return unixTime(vm.CreatedAt)
+}
+
+func (vm *AzureVM) GetLastActive() time.Time {
+	return unixTime(vm.LastActive)
+}
+
+func (vm *AzureVM) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         vm.CPUUsage,
+		MetricMemory:      vm.MemoryUsage,
+		MetricCostPerHour: vm.CostPerHour,
+	}
+}
+
+// UpdateUsage lets requests drift by up to 20% per step. A container without
+// requests is never read or written, so it goes cold.
+func (b *AzureBlobContainer) UpdateUsage() {
+	b.RequestsPerDay *= 0.8 + sim.Float64()*0.4
+	if b.RequestsPerDay > 0 {
+		b.UsedGB += sim.Float64() * 2
+		b.LastAccessed = sim.Now().Unix()
+	}
+}
+
+func (b *AzureBlobContainer) GetId() string {
+	return b.ID
+}
+
+func (b *AzureBlobContainer) GetUsage() float64 {
+	return b.UsedGB
+}
+
+func (b *AzureBlobContainer) GetType() string {
+	return "AzureBlobContainer"
+}
+
+func (b *AzureBlobContainer) GetTags() map[string]string {
+	return b.Tags
+}
+
+func (b *AzureBlobContainer) GetOwner() string {
+	return b.Owner
+}
+
+func (b *AzureBlobContainer) GetRegion() string {
+	return b.Region
+}
+
+func (b *AzureBlobContainer) GetProvider() string {
+	return ProviderAzure
+}
+
+func (b *AzureBlobContainer) GetAccount() string {
+	return b.Subscription
+}
+
+func (b *AzureBlobContainer) GetMonthlyCost() float64 {
+	return b.UsedGB * b.CostPerGBMonth
+}
+
+func (b *AzureBlobContainer) GetCreatedAt() time.Time {
+	return unixTime(b.CreatedAt)
+}
+
+func (b *AzureBlobContainer) GetLastActive() time.Time {
+	return unixTime(b.LastAccessed)
+}
+
+func (b *AzureBlobContainer) Metrics() map[string]float64 {
+	return map[string]float64{
+		"used_gb":          b.UsedGB,
+		"requests_per_day": b.RequestsPerDay,
+		MetricCostPerHour:  b.UsedGB * b.CostPerGBMonth / hoursPerMonth,
+	}
+}
+
+func (db *AzureSQLDatabase) UpdateUsage() {
+	db.Connections = sim.Intn(200)
+	db.CPUUsage = sim.Float64() * 80
+	if db.Connections > 0 {
+		db.LastActive = sim.Now().Unix()
+	}
+}
+
+func (db *AzureSQLDatabase) GetId() string {
+	return db.ID
+}
+
+func (db *AzureSQLDatabase) GetUsage() float64 {
+	return db.CPUUsage
+}
+
+func (db *AzureSQLDatabase) GetType() string {
+	return "AzureSQLDatabase"
+}
+
+func (db *AzureSQLDatabase) GetTags() map[string]string {
+	return db.Tags
+}
+
+func (db *AzureSQLDatabase) GetOwner() string {
+	return db.Owner
+}
+
+func (db *AzureSQLDatabase) GetRegion() string {
+	return db.Region
+}
+
+func (db *AzureSQLDatabase) GetProvider() string {
+	return ProviderAzure
+}
+
+func (db *AzureSQLDatabase) GetAccount() string {
+	return db.Subscription
+}
+
+func (db *AzureSQLDatabase) GetMonthlyCost() float64 {
+	return db.CostPerHour * hoursPerMonth
+}
+
+func (db *AzureSQLDatabase) GetCreatedAt() time.Time {
+	return unixTime(db.CreatedAt)
+}
+
+func (db *AzureSQLDatabase) GetLastActive() time.Time {
+	return unixTime(db.LastActive)
+}
+
+func (db *AzureSQLDatabase) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         db.CPUUsage,
+		MetricConnections: float64(db.Connections),
+		MetricCostPerHour: db.CostPerHour,
+	}
+}
+
+// UpdateUsage lets consumed throughput drift by up to 20% per step, never
+// beyond what is provisioned.
+func (c *CosmosDBAccount) UpdateUsage() {
+	c.ConsumedRUs = min(c.ConsumedRUs*(0.8+sim.Float64()*0.4), c.ProvisionedRUs)
+	if c.ConsumedRUs > 0 {
+		c.LastActive = sim.Now().Unix()
+	}
+}
+
+func (c *CosmosDBAccount) GetId() string {
+	return c.ID
+}
+
+func (c *CosmosDBAccount) GetUsage() float64 {
+	return c.ConsumedRUs
+}
+
+func (c *CosmosDBAccount) GetType() string {
+	return "CosmosDBAccount"
+}
+
+func (c *CosmosDBAccount) GetTags() map[string]string {
+	return c.Tags
+}
+
+func (c *CosmosDBAccount) GetOwner() string {
+	return c.Owner
+}
+
+func (c *CosmosDBAccount) GetRegion() string {
+	return c.Region
+}
+
+func (c *CosmosDBAccount) GetProvider() string {
+	return ProviderAzure
+}
+
+func (c *CosmosDBAccount) GetAccount() string {
+	return c.Subscription
+}
+
+func (c *CosmosDBAccount) GetMonthlyCost() float64 {
+	return c.CostPerHour() * hoursPerMonth
+}
+
+func (c *CosmosDBAccount) GetCreatedAt() time.Time {
+	return unixTime(c.CreatedAt)
+}
+
+func (c *CosmosDBAccount) GetLastActive() time.Time {
+	return unixTime(c.LastActive)
+}
+
+func (c *CosmosDBAccount) Metrics() map[string]float64 {
+	return map[string]float64{
+		"ru_provisioned":  c.ProvisionedRUs,
+		"ru_consumed":     c.ConsumedRUs,
+		"ru_utilization":  c.RUUtilization(),
+		MetricCostPerHour: c.CostPerHour(),
+	}
+}
+
+// CostPerHour is the hourly charge for the provisioned throughput.
+func (c *CosmosDBAccount) CostPerHour() float64 {
+	return c.ProvisionedRUs / 100 * c.CostPer100RUHour
+}
+
+// RUUtilization is consumed throughput as a percentage of provisioned.
+func (c *CosmosDBAccount) RUUtilization() float64 {
+	return percent(c.ConsumedRUs, c.ProvisionedRUs)
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// WireVersion is the version of the resource encoding written by Marshal.
+// Version 0 is the untyped encoding written before the discriminator existed;
+// it can still be decoded when the type is known from elsewhere.
+const WireVersion = 1
+
+// ErrUnknownType is returned when decoding a type that was never registered.
+var ErrUnknownType = errors.New("unknown resource type")
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]func() CloudResource)
+)
+
+// Register makes a resource type decodable under name, which must match what
+// its GetType returns. newResource returns a pointer to a zero value.
+func Register(name string, newResource func() CloudResource) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = newResource
+}
+
+// RegisteredTypes returns the names of all decodable types, sorted.
+func RegisteredTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("VM", func() CloudResource { return &VM{} })
+	Register("Storage", func() CloudResource { return &Storage{} })
+	Register("Database", func() CloudResource { return &Database{} })
+	Register("S3", func() CloudResource { return &S3{} })
+	Register("DynamoDB", func() CloudResource { return &DynamoDB{} })
+	Register("Lambda", func() CloudResource { return &Lambda{} })
+	Register("ELB", func() CloudResource { return &ELB{} })
+	Register("EKSCluster", func() CloudResource { return &EKSCluster{} })
+	Register("EKSNodeGroup", func() CloudResource { return &EKSNodeGroup{} })
+	Register("EBSVolume", func() CloudResource { return &EBSVolume{} })
+	Register("EBSSnapshot", func() CloudResource { return &EBSSnapshot{} })
+	Register("ElasticIP", func() CloudResource { return &ElasticIP{} })
+	Register("NATGateway", func() CloudResource { return &NATGateway{} })
+	Register("GCEInstance", func() CloudResource { return &GCEInstance{} })
+	Register("GCSBucket", func() CloudResource { return &GCSBucket{} })
+	Register("CloudSQLInstance", func() CloudResource { return &CloudSQLInstance{} })
+	Register("BigQueryDataset", func() CloudResource { return &BigQueryDataset{} })
+	Register("AzureVM", func() CloudResource { return &AzureVM{} })
+	Register("AzureBlobContainer", func() CloudResource { return &AzureBlobContainer{} })
+	Register("AzureSQLDatabase", func() CloudResource { return &AzureSQLDatabase{} })
+	Register("CosmosDBAccount", func() CloudResource { return &CosmosDBAccount{} })
+}
+
+// header is the discriminator written ahead of a resource's own fields.
+// Provider is informational; decoding derives it from the type.
+type header struct {
+	Type     string `json:"type"`
+	Version  int    `json:"version"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Marshal encodes r as a JSON object holding its fields plus "type",
+// "version" and "provider", e.g.
+// {"type":"VM","version":1,"provider":"aws","ID":"vm-1",...}.
+func Marshal(r CloudResource) ([]byte, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s %s: %w", r.GetType(), r.GetId(), err)
+	}
+	if len(body) < 2 || body[0] != '{' {
+		return nil, fmt.Errorf("encode %s %s: not a JSON object", r.GetType(), r.GetId())
+	}
+	head, err := json.Marshal(header{Type: r.GetType(), Version: WireVersion, Provider: r.GetProvider()})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(head[:len(head)-1])
+	if !bytes.Equal(bytes.TrimSpace(body[1:]), []byte("}")) {
+		buf.WriteByte(',')
+	}
+	buf.Write(body[1:])
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a resource written by Marshal, choosing the concrete model
+// from its "type" field.
+func Unmarshal(data []byte) (CloudResource, error) {
+	return UnmarshalAs("", data)
+}
+
+// UnmarshalAs is Unmarshal for data whose type may be recorded elsewhere, such
+// as version 0 replay snapshots. typ is used when data has no "type" field and
+// must agree with it otherwise.
+func UnmarshalAs(typ string, data []byte) (CloudResource, error) {
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	if h.Version > WireVersion {
+		return nil, fmt.Errorf("resource encoding version %d is newer than supported version %d", h.Version, WireVersion)
+	}
+	switch {
+	case h.Type == "":
+		h.Type = typ
+	case typ != "" && typ != h.Type:
+		return nil, fmt.Errorf("resource type %q does not match %q", h.Type, typ)
+	}
+	if h.Type == "" {
+		return nil, errors.New("resource has no type")
+	}
+	registryMu.RLock()
+	newResource, ok := registry[h.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownType, h.Type)
+	}
+	r := newResource()
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", h.Type, err)
+	}
+	return r, nil
+}
+
+// Resources is a list of resources that encodes each element with Marshal and
+// decodes them through the type registry.
+type Resources []CloudResource
+
+func (rs Resources) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, r := range rs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+func (rs *Resources) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := make(Resources, 0, len(raw))
+	for i, b := range raw {
+		r, err := Unmarshal(b)
+		if err != nil {
+			return fmt.Errorf("resource %d: %w", i, err)
+		}
+		out = append(out, r)
+	}
+	*rs = out
+	return nil
+}
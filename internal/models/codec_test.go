@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResourcesRoundTrip(t *testing.T) {
+	in := Resources{
+		&VM{ID: "vm-1", InstanceType: "t3.medium", CostPerHour: 0.05, Tags: map[string]string{"environment": "production"}},
+		&Storage{ID: "s-1", UsedGB: 10, CostPerGB: 0.02},
+		&S3{ID: "s3-1", UsedGB: 10, CostPerGB: 0.02},
+		&Database{ID: "db-1", Engine: "postgres"},
+		&DynamoDB{ID: "ddb-1"},
+		&Lambda{ID: "fn-1", Invocations: 10},
+		&ELB{ID: "elb-1"},
+	}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `{"type":"Storage","version":1,"provider":"aws","ID":"s-1"`) {
+		t.Errorf("missing discriminator: %s", b)
+	}
+	var out Resources
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip changed resources:\n got %#v\nwant %#v", out, in)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	if _, err := Unmarshal([]byte(`{"type":"Mainframe","version":1}`)); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("unknown type: err = %v", err)
+	}
+	if _, err := Unmarshal([]byte(`{"type":"VM","version":99}`)); err == nil {
+		t.Error("accepted a future version")
+	}
+	if _, err := Unmarshal([]byte(`{"ID":"vm-1"}`)); err == nil {
+		t.Error("decoded a resource without a type")
+	}
+
+	// Version 0 bodies carry no type; the caller supplies it.
+	r, err := UnmarshalAs("ELB", []byte(`{"ID":"elb-1","RequestCount":5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elb, ok := r.(*ELB); !ok || elb.RequestCount != 5 {
+		t.Errorf("legacy decode = %#v", r)
+	}
+	if _, err := UnmarshalAs("ELB", []byte(`{"type":"VM","version":1}`)); err == nil {
+		t.Error("accepted a type that contradicts the caller's")
+	}
+}
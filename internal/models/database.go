@@ -2,12 +2,18 @@ package models
 
 import (
 	"fmt"
-	"math/rand"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
 )
 
 func (d *Database) UpdateUsage() {
-	d.Connections = rand.Intn(200)
-	d.CPUUsage = rand.Float64() * 80
+	d.Connections = sim.Intn(200)
+	d.CPUUsage = sim.Float64() * 80
+	d.MemoryUsage = 30 + sim.Float64()*30
+	if d.Connections > 0 {
+		d.LastActive = sim.Now().Unix()
+	}
 }
 
 func (db *Database) GetId() string {
@@ -22,6 +28,47 @@ func (db *Database) GetType() string {
 	return "Database"
 }
 
+func (db *Database) GetTags() map[string]string {
+	return db.Tags
+}
+
+func (db *Database) GetOwner() string {
+	return db.Owner
+}
+
+func (db *Database) GetRegion() string {
+	return db.Region
+}
+
+func (db *Database) GetProvider() string {
+	return ProviderAWS
+}
+
+func (db *Database) GetAccount() string {
+	return db.Account
+}
+
+func (db *Database) GetMonthlyCost() float64 {
+	return db.CostPerHr * hoursPerMonth
+}
+
+func (db *Database) GetCreatedAt() time.Time {
+	return unixTime(db.CreatedAt)
+}
+
+func (db *Database) GetLastActive() time.Time {
+	return unixTime(db.LastActive)
+}
+
+func (db *Database) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         db.CPUUsage,
+		MetricMemory:      db.MemoryUsage,
+		MetricConnections: float64(db.Connections),
+		MetricCostPerHour: db.CostPerHr,
+	}
+}
+
 func (db *Database) String() string {
-	return fmt.Sprintf("Database[ID=%s, Connections=%d, CostPerHr=%.2f, PreviousCostPerHr=%.2f, Owner=%s]", db.ID, db.Connections, db.CostPerHr, db.PreviousCostPerHr, db.Owner)
+	return fmt.Sprintf("Database[ID=%s, InstanceClass=%s, Region=%s, Connections=%d, CostPerHr=%.2f, PreviousCostPerHr=%.2f, Owner=%s]", db.ID, db.InstanceClass, db.Region, db.Connections, db.CostPerHr, db.PreviousCostPerHr, db.Owner)
 }
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+func (v *EBSVolume) UpdateUsage() {
+	if v.AttachedTo != "" {
+		v.LastAttached = sim.Now().Unix()
+	}
+}
+
+func (v *EBSVolume) GetId() string {
+	return v.ID
+}
+
+func (v *EBSVolume) GetUsage() float64 {
+	return v.SizeGB
+}
+
+func (v *EBSVolume) GetType() string {
+	return "EBSVolume"
+}
+
+func (v *EBSVolume) GetTags() map[string]string {
+	return v.Tags
+}
+
+func (v *EBSVolume) GetOwner() string {
+	return v.Owner
+}
+
+func (v *EBSVolume) GetRegion() string {
+	return v.Region
+}
+
+func (v *EBSVolume) GetProvider() string {
+	return ProviderAWS
+}
+
+func (v *EBSVolume) GetAccount() string {
+	return v.Account
+}
+
+func (v *EBSVolume) GetMonthlyCost() float64 {
+	return v.SizeGB * v.CostPerGBMonth
+}
+
+func (v *EBSVolume) GetCreatedAt() time.Time {
+	return unixTime(v.CreatedAt)
+}
+
+// GetLastActive is the last time the volume was seen attached.
+func (v *EBSVolume) GetLastActive() time.Time {
+	return unixTime(v.LastAttached)
+}
+
+func (v *EBSVolume) Metrics() map[string]float64 {
+	return map[string]float64{
+		"size_gb":         v.SizeGB,
+		MetricCostPerHour: v.GetMonthlyCost() / hoursPerMonth,
+	}
+}
+
+func (v *EBSVolume) Relations() []Relation {
+	if v.AttachedTo == "" {
+		return nil
+	}
+	return []Relation{{Kind: AttachedTo, ID: v.AttachedTo}}
+}
+
+// UpdateUsage does nothing: a snapshot does not change once taken.
+func (s *EBSSnapshot) UpdateUsage() {}
+
+func (s *EBSSnapshot) GetId() string {
+	return s.ID
+}
+
+func (s *EBSSnapshot) GetUsage() float64 {
+	return s.SizeGB
+}
+
+func (s *EBSSnapshot) GetType() string {
+	return "EBSSnapshot"
+}
+
+func (s *EBSSnapshot) GetTags() map[string]string {
+	return s.Tags
+}
+
+func (s *EBSSnapshot) GetOwner() string {
+	return s.Owner
+}
+
+func (s *EBSSnapshot) GetRegion() string {
+	return s.Region
+}
+
+func (s *EBSSnapshot) GetProvider() string {
+	return ProviderAWS
+}
+
+func (s *EBSSnapshot) GetAccount() string {
+	return s.Account
+}
+
+func (s *EBSSnapshot) GetMonthlyCost() float64 {
+	return s.SizeGB * s.CostPerGBMonth
+}
+
+func (s *EBSSnapshot) GetCreatedAt() time.Time {
+	return unixTime(s.CreatedAt)
+}
+
+func (s *EBSSnapshot) GetLastActive() time.Time {
+	return time.Time{}
+}
+
+func (s *EBSSnapshot) Metrics() map[string]float64 {
+	return map[string]float64{
+		"size_gb":         s.SizeGB,
+		MetricCostPerHour: s.GetMonthlyCost() / hoursPerMonth,
+	}
+}
+
+func (s *EBSSnapshot) Relations() []Relation {
+	if s.VolumeID == "" {
+		return nil
+	}
+	return []Relation{{Kind: SnapshotOf, ID: s.VolumeID}}
+}
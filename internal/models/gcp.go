@@ -0,0 +1,260 @@
+package models
+
+import (
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+func (i *GCEInstance) UpdateUsage() {
+	i.CPUUsage = sim.Float64() * 100
+	i.MemoryUsage = 20 + sim.Float64()*40
+	if sim.Float64() < 0.2 {
+		i.LastActive = sim.Now().Unix()
+	}
+}
+
+func (i *GCEInstance) GetId() string {
+	return i.ID
+}
+
+func (i *GCEInstance) GetUsage() float64 {
+	return i.CPUUsage
+}
+
+func (i *GCEInstance) GetType() string {
+	return "GCEInstance"
+}
+
+func (i *GCEInstance) GetTags() map[string]string {
+	return i.Tags
+}
+
+func (i *GCEInstance) GetOwner() string {
+	return i.Owner
+}
+
+func (i *GCEInstance) GetRegion() string {
+	return i.Region
+}
+
+func (i *GCEInstance) GetProvider() string {
+	return ProviderGCP
+}
+
+func (i *GCEInstance) GetAccount() string {
+	return i.Project
+}
+
+func (i *GCEInstance) GetMonthlyCost() float64 {
+	return i.CostPerHour * hoursPerMonth
+}
+
+func (i *GCEInstance) GetCreatedAt() time.Time {
+	return unixTime(i.CreatedAt)
+}
+
+func (i *GCEInstance) GetLastActive() time.Time {
+	return unixTime(i.LastActive)
+}
+
+func (i *GCEInstance) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         i.CPUUsage,
+		MetricMemory:      i.MemoryUsage,
+		MetricCostPerHour: i.CostPerHour,
+	}
+}
+
+// UpdateUsage lets requests drift by up to 20% per step. A bucket without
+// requests is never read or written, so it goes cold.
+func (b *GCSBucket) UpdateUsage() {
+	b.RequestsPerDay *= 0.8 + sim.Float64()*0.4
+	if b.RequestsPerDay > 0 {
+		b.UsedGB += sim.Float64() * 2
+		b.LastAccessed = sim.Now().Unix()
+	}
+}
+
+func (b *GCSBucket) GetId() string {
+	return b.ID
+}
+
+func (b *GCSBucket) GetUsage() float64 {
+	return b.UsedGB
+}
+
+func (b *GCSBucket) GetType() string {
+	return "GCSBucket"
+}
+
+func (b *GCSBucket) GetTags() map[string]string {
+	return b.Tags
+}
+
+func (b *GCSBucket) GetOwner() string {
+	return b.Owner
+}
+
+func (b *GCSBucket) GetRegion() string {
+	return b.Region
+}
+
+func (b *GCSBucket) GetProvider() string {
+	return ProviderGCP
+}
+
+func (b *GCSBucket) GetAccount() string {
+	return b.Project
+}
+
+func (b *GCSBucket) GetMonthlyCost() float64 {
+	return b.UsedGB * b.CostPerGBMonth
+}
+
+func (b *GCSBucket) GetCreatedAt() time.Time {
+	return unixTime(b.CreatedAt)
+}
+
+func (b *GCSBucket) GetLastActive() time.Time {
+	return unixTime(b.LastAccessed)
+}
+
+func (b *GCSBucket) Metrics() map[string]float64 {
+	return map[string]float64{
+		"used_gb":          b.UsedGB,
+		"requests_per_day": b.RequestsPerDay,
+		MetricCostPerHour:  b.UsedGB * b.CostPerGBMonth / hoursPerMonth,
+	}
+}
+
+func (i *CloudSQLInstance) UpdateUsage() {
+	i.Connections = sim.Intn(200)
+	i.CPUUsage = sim.Float64() * 80
+	if i.Connections > 0 {
+		i.LastActive = sim.Now().Unix()
+	}
+}
+
+func (i *CloudSQLInstance) GetId() string {
+	return i.ID
+}
+
+func (i *CloudSQLInstance) GetUsage() float64 {
+	return i.CPUUsage
+}
+
+func (i *CloudSQLInstance) GetType() string {
+	return "CloudSQLInstance"
+}
+
+func (i *CloudSQLInstance) GetTags() map[string]string {
+	return i.Tags
+}
+
+func (i *CloudSQLInstance) GetOwner() string {
+	return i.Owner
+}
+
+func (i *CloudSQLInstance) GetRegion() string {
+	return i.Region
+}
+
+func (i *CloudSQLInstance) GetProvider() string {
+	return ProviderGCP
+}
+
+func (i *CloudSQLInstance) GetAccount() string {
+	return i.Project
+}
+
+func (i *CloudSQLInstance) GetMonthlyCost() float64 {
+	return i.CostPerHour * hoursPerMonth
+}
+
+func (i *CloudSQLInstance) GetCreatedAt() time.Time {
+	return unixTime(i.CreatedAt)
+}
+
+func (i *CloudSQLInstance) GetLastActive() time.Time {
+	return unixTime(i.LastActive)
+}
+
+func (i *CloudSQLInstance) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         i.CPUUsage,
+		MetricConnections: float64(i.Connections),
+		MetricCostPerHour: i.CostPerHour,
+	}
+}
+
+// UpdateUsage lets the volume scanned drift by up to 20% per step. A dataset
+// nobody queries stays unqueried.
+func (d *BigQueryDataset) UpdateUsage() {
+	d.TBScannedPerDay *= 0.8 + sim.Float64()*0.4
+	if d.TBScannedPerDay > 0 {
+		d.LastQueried = sim.Now().Unix()
+	}
+}
+
+func (d *BigQueryDataset) GetId() string {
+	return d.ID
+}
+
+func (d *BigQueryDataset) GetUsage() float64 {
+	return d.TBScannedPerDay
+}
+
+func (d *BigQueryDataset) GetType() string {
+	return "BigQueryDataset"
+}
+
+func (d *BigQueryDataset) GetTags() map[string]string {
+	return d.Tags
+}
+
+func (d *BigQueryDataset) GetOwner() string {
+	return d.Owner
+}
+
+func (d *BigQueryDataset) GetRegion() string {
+	return d.Region
+}
+
+func (d *BigQueryDataset) GetProvider() string {
+	return ProviderGCP
+}
+
+func (d *BigQueryDataset) GetAccount() string {
+	return d.Project
+}
+
+func (d *BigQueryDataset) GetMonthlyCost() float64 {
+	return d.StorageCost() + d.QueryCost()
+}
+
+func (d *BigQueryDataset) GetCreatedAt() time.Time {
+	return unixTime(d.CreatedAt)
+}
+
+func (d *BigQueryDataset) GetLastActive() time.Time {
+	return unixTime(d.LastQueried)
+}
+
+func (d *BigQueryDataset) Metrics() map[string]float64 {
+	return map[string]float64{
+		"stored_gb":          d.StoredGB,
+		"tb_scanned_per_day": d.TBScannedPerDay,
+		MetricCostPerHour:    d.GetMonthlyCost() / hoursPerMonth,
+	}
+}
+
+// StorageCost is the monthly charge for the data d stores.
+func (d *BigQueryDataset) StorageCost() float64 {
+	return d.StoredGB * d.StorageCostPerGBMonth
+}
+
+// QueryCost is the monthly on-demand charge for the data d's queries scan.
+func (d *BigQueryDataset) QueryCost() float64 {
+	return d.TBScannedPerDay * d.CostPerTBScanned * 30
+}
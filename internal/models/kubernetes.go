@@ -0,0 +1,200 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+// Kubernetes metric names, as percentages of the node group's allocatable
+// capacity except for the request utilization, which is usage as a percentage
+// of what pods request.
+const (
+	MetricCPURequested             = "cpu_requested"
+	MetricMemoryRequested          = "memory_requested"
+	MetricCPURequestUtilization    = "cpu_request_utilization"
+	MetricMemoryRequestUtilization = "memory_request_utilization"
+	MetricPods                     = "pods"
+	MetricNodes                    = "nodes"
+)
+
+func (c *EKSCluster) UpdateUsage() {
+	c.LastActive = sim.Now().Unix()
+}
+
+func (c *EKSCluster) GetId() string {
+	return c.ID
+}
+
+func (c *EKSCluster) GetUsage() float64 {
+	return float64(len(c.NodeGroups))
+}
+
+func (c *EKSCluster) GetType() string {
+	return "EKSCluster"
+}
+
+func (c *EKSCluster) GetTags() map[string]string {
+	return c.Tags
+}
+
+func (c *EKSCluster) GetOwner() string {
+	return c.Owner
+}
+
+func (c *EKSCluster) GetRegion() string {
+	return c.Region
+}
+
+func (c *EKSCluster) GetProvider() string {
+	return ProviderAWS
+}
+
+func (c *EKSCluster) GetAccount() string {
+	return c.Account
+}
+
+func (c *EKSCluster) GetMonthlyCost() float64 {
+	return c.ControlPlaneCostPerHour * hoursPerMonth
+}
+
+func (c *EKSCluster) GetCreatedAt() time.Time {
+	return unixTime(c.CreatedAt)
+}
+
+func (c *EKSCluster) GetLastActive() time.Time {
+	return unixTime(c.LastActive)
+}
+
+func (c *EKSCluster) Metrics() map[string]float64 {
+	return map[string]float64{
+		"node_groups":     float64(len(c.NodeGroups)),
+		MetricCostPerHour: c.ControlPlaneCostPerHour,
+	}
+}
+
+// UpdateUsage lets the pod count drift by up to 10% per step, within what the
+// nodes can schedule, with requests following the pods. Pods use a fraction
+// of what they request, as most workloads do. A group without pods stays
+// empty.
+func (ng *EKSNodeGroup) UpdateUsage() {
+	if ng.Pods > 0 {
+		cpuPerPod := ng.RequestedCPU / float64(ng.Pods)
+		memPerPod := ng.RequestedMemoryGiB / float64(ng.Pods)
+		pods := int(math.Round(float64(ng.Pods) * (0.9 + sim.Float64()*0.2)))
+		limit := math.MaxInt
+		if ng.MaxPodsPerNode > 0 {
+			limit = ng.NodeCount * ng.MaxPodsPerNode
+		}
+		if cpuPerPod > 0 {
+			limit = min(limit, int(ng.TotalAllocatableCPU()/cpuPerPod))
+		}
+		if memPerPod > 0 {
+			limit = min(limit, int(ng.TotalAllocatableMemoryGiB()/memPerPod))
+		}
+		pods = max(min(pods, limit), 1)
+		ng.Pods = pods
+		ng.RequestedCPU = cpuPerPod * float64(pods)
+		ng.RequestedMemoryGiB = memPerPod * float64(pods)
+		ng.LastActive = sim.Now().Unix()
+	}
+	ng.UsedCPU = ng.RequestedCPU * (0.1 + sim.Float64()*0.4)
+	ng.UsedMemoryGiB = ng.RequestedMemoryGiB * (0.4 + sim.Float64()*0.4)
+}
+
+func (ng *EKSNodeGroup) GetId() string {
+	return ng.ID
+}
+
+// GetUsage is the CPU in use as a percentage of the group's allocatable CPU.
+func (ng *EKSNodeGroup) GetUsage() float64 {
+	return percent(ng.UsedCPU, ng.TotalAllocatableCPU())
+}
+
+func (ng *EKSNodeGroup) GetType() string {
+	return "EKSNodeGroup"
+}
+
+func (ng *EKSNodeGroup) GetTags() map[string]string {
+	return ng.Tags
+}
+
+func (ng *EKSNodeGroup) GetOwner() string {
+	return ng.Owner
+}
+
+func (ng *EKSNodeGroup) GetRegion() string {
+	return ng.Region
+}
+
+func (ng *EKSNodeGroup) GetProvider() string {
+	return ProviderAWS
+}
+
+func (ng *EKSNodeGroup) GetAccount() string {
+	return ng.Account
+}
+
+func (ng *EKSNodeGroup) GetMonthlyCost() float64 {
+	return ng.CostPerHour() * hoursPerMonth
+}
+
+func (ng *EKSNodeGroup) GetCreatedAt() time.Time {
+	return unixTime(ng.CreatedAt)
+}
+
+// GetLastActive is the last time the group was running pods.
+func (ng *EKSNodeGroup) GetLastActive() time.Time {
+	return unixTime(ng.LastActive)
+}
+
+func (ng *EKSNodeGroup) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:                      ng.GetUsage(),
+		MetricMemory:                   percent(ng.UsedMemoryGiB, ng.TotalAllocatableMemoryGiB()),
+		MetricCPURequested:             percent(ng.RequestedCPU, ng.TotalAllocatableCPU()),
+		MetricMemoryRequested:          percent(ng.RequestedMemoryGiB, ng.TotalAllocatableMemoryGiB()),
+		MetricCPURequestUtilization:    percent(ng.UsedCPU, ng.RequestedCPU),
+		MetricMemoryRequestUtilization: percent(ng.UsedMemoryGiB, ng.RequestedMemoryGiB),
+		MetricPods:                     float64(ng.Pods),
+		MetricNodes:                    float64(ng.NodeCount),
+		MetricCostPerHour:              ng.CostPerHour(),
+	}
+}
+
+// CostPerHour is the cost of all the group's nodes.
+func (ng *EKSNodeGroup) CostPerHour() float64 {
+	return float64(ng.NodeCount) * ng.CostPerNodeHour
+}
+
+func (ng *EKSNodeGroup) TotalAllocatableCPU() float64 {
+	return float64(ng.NodeCount) * ng.AllocatableCPU
+}
+
+func (ng *EKSNodeGroup) TotalAllocatableMemoryGiB() float64 {
+	return float64(ng.NodeCount) * ng.AllocatableMemoryGiB
+}
+
+// PodDensity is the average number of pods per node.
+func (ng *EKSNodeGroup) PodDensity() float64 {
+	if ng.NodeCount == 0 {
+		return 0
+	}
+	return float64(ng.Pods) / float64(ng.NodeCount)
+}
+
+// percent returns part as a percentage of whole, or 0 when whole is not positive.
+func percent(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return part / whole * 100
+}
+
+func (ng *EKSNodeGroup) Relations() []Relation {
+	if ng.ClusterID == "" {
+		return nil
+	}
+	return []Relation{{Kind: MemberOf, ID: ng.ClusterID}}
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Metric names shared by the resource types' Metrics. Costs are in USD;
+// MetricCostTotal is a running total for resources billed per request.
+const (
+	MetricCPU         = "cpu"
+	MetricMemory      = "memory"
+	MetricConnections = "connections"
+	MetricCostPerHour = "cost_per_hour"
+	MetricCostTotal   = "cost_total"
+)
+
+// Cloud providers a resource can belong to.
+const (
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+	ProviderAzure = "azure"
+)
+
+// hoursPerMonth is the 24*30 month used for monthly figures throughout.
+const hoursPerMonth = 24 * 30
+
+// unixTime converts a Unix timestamp field, treating 0 as unknown.
+func unixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
+
+// TagFilter selects resources by tag. A key with an empty value only requires
+// the tag to be present.
+type TagFilter map[string]string
+
+// Matches reports whether r carries every tag in f.
+func (f TagFilter) Matches(r CloudResource) bool {
+	tags := r.GetTags()
+	for k, v := range f {
+		got, ok := tags[k]
+		if !ok || (v != "" && got != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Relation kinds.
+const (
+	AttachedTo     = "attached_to"
+	SnapshotOf     = "snapshot_of"
+	AssociatedWith = "associated_with"
+	MemberOf       = "member_of"
+	RoutesTo       = "routes_to"
+	ReadsFrom      = "reads_from"
+	WritesTo       = "writes_to"
+)
+
+// Relation is a reference from one resource to another, such as a volume to
+// the VM it is attached to.
+type Relation struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+}
+
+// Related is implemented by resources that refer to other resources.
+type Related interface {
+	Relations() []Relation
+}
+
+// RelationsOf returns r's references to other resources, if it has any.
+func RelationsOf(r CloudResource) []Relation {
+	if rel, ok := r.(Related); ok {
+		return rel.Relations()
+	}
+	return nil
+}
+
+// relationsTo returns a relation of kind to each of ids.
+func relationsTo(kind string, ids []string) []Relation {
+	var out []Relation
+	for _, id := range ids {
+		out = append(out, Relation{Kind: kind, ID: id})
+	}
+	return out
+}
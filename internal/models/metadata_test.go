@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+func TestCommonAccessors(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tags := map[string]string{"environment": "production"}
+	// Thirty days after creation, so a Lambda's monthly cost is what it has
+	// spent so far.
+	defer sim.SetClock(sim.GetClock())
+	sim.SetClock(sim.NewVirtualClock(created.Add(30 * 24 * time.Hour)))
+	cases := []struct {
+		res     CloudResource
+		monthly float64
+		metric  string
+		// provider is left empty for AWS.
+		provider string
+	}{
+		{&VM{ID: "vm", Owner: "o", Region: "r", CostPerHour: 0.1, CreatedAt: created.Unix(), Tags: tags}, 72, MetricCostPerHour, ""},
+		{&Database{ID: "db", Owner: "o", Region: "r", CostPerHr: 0.2, CreatedAt: created.Unix(), Tags: tags}, 144, MetricCostPerHour, ""},
+		{&DynamoDB{ID: "ddb", Owner: "o", Region: "r", CostPerHr: 0.1, CreatedAt: created.Unix(), Tags: tags}, 72, MetricCostPerHour, ""},
+		{&ELB{ID: "elb", Owner: "o", Region: "r", CostPerHour: 0.025, CreatedAt: created.Unix(), Tags: tags}, 18, MetricCostPerHour, ""},
+		{&Storage{ID: "s", Owner: "o", Region: "r", UsedGB: 100, CostPerGB: 0.02, CreatedAt: created.Unix(), Tags: tags}, 2, MetricCostPerHour, ""},
+		{&S3{ID: "s3", Owner: "o", Region: "r", UsedGB: 500, CostPerGB: 0.023, CreatedAt: created.Unix(), Tags: tags}, 11.5, MetricCostPerHour, ""},
+		{&Lambda{ID: "fn", Owner: "o", Region: "r", Invocations: 2000000, CostPerMillion: 0.2, CreatedAt: created.Unix(), Tags: tags}, 0.4, MetricCostTotal, ""},
+		{&EKSCluster{ID: "eks", Owner: "o", Region: "r", ControlPlaneCostPerHour: 0.1, CreatedAt: created.Unix(), Tags: tags}, 72, MetricCostPerHour, ""},
+		{&EKSNodeGroup{ID: "ng", Owner: "o", Region: "r", NodeCount: 3, CostPerNodeHour: 0.2, CreatedAt: created.Unix(), Tags: tags}, 432, MetricCostPerHour, ""},
+		{&EBSVolume{ID: "vol", Owner: "o", Region: "r", SizeGB: 100, CostPerGBMonth: 0.08, CreatedAt: created.Unix(), Tags: tags}, 8, MetricCostPerHour, ""},
+		{&EBSSnapshot{ID: "snap", Owner: "o", Region: "r", SizeGB: 100, CostPerGBMonth: 0.05, CreatedAt: created.Unix(), Tags: tags}, 5, MetricCostPerHour, ""},
+		{&ElasticIP{ID: "eip", Owner: "o", Region: "r", CostPerHour: 0.005, CreatedAt: created.Unix(), Tags: tags}, 3.6, MetricCostPerHour, ""},
+		{&NATGateway{ID: "nat", Owner: "o", Region: "r", GBPerHour: 1, CostPerHour: 0.045, CostPerGB: 0.045, CreatedAt: created.Unix(), Tags: tags}, 64.8, MetricCostPerHour, ""},
+		{&GCEInstance{ID: "gce", Owner: "o", Region: "r", CostPerHour: 0.1, CreatedAt: created.Unix(), Tags: tags}, 72, MetricCostPerHour, ProviderGCP},
+		{&GCSBucket{ID: "gcs", Owner: "o", Region: "r", UsedGB: 100, CostPerGBMonth: 0.02, CreatedAt: created.Unix(), Tags: tags}, 2, MetricCostPerHour, ProviderGCP},
+		{&CloudSQLInstance{ID: "sql", Owner: "o", Region: "r", CostPerHour: 0.2, CreatedAt: created.Unix(), Tags: tags}, 144, MetricConnections, ProviderGCP},
+		{&BigQueryDataset{ID: "bq", Owner: "o", Region: "r", StoredGB: 1000, StorageCostPerGBMonth: 0.02, TBScannedPerDay: 2, CostPerTBScanned: 6.25, CreatedAt: created.Unix(), Tags: tags}, 395, MetricCostPerHour, ProviderGCP},
+		{&AzureVM{ID: "azvm", Owner: "o", Region: "r", CostPerHour: 0.1, CreatedAt: created.Unix(), Tags: tags}, 72, MetricCostPerHour, ProviderAzure},
+		{&AzureBlobContainer{ID: "blob", Owner: "o", Region: "r", UsedGB: 100, CostPerGBMonth: 0.0184, CreatedAt: created.Unix(), Tags: tags}, 1.84, MetricCostPerHour, ProviderAzure},
+		{&AzureSQLDatabase{ID: "azsql", Owner: "o", Region: "r", CostPerHour: 0.5, CreatedAt: created.Unix(), Tags: tags}, 360, MetricConnections, ProviderAzure},
+		{&CosmosDBAccount{ID: "cosmos", Owner: "o", Region: "r", ProvisionedRUs: 1000, CostPer100RUHour: 0.008, CreatedAt: created.Unix(), Tags: tags}, 57.6, MetricCostPerHour, ProviderAzure},
+	}
+	for _, c := range cases {
+		r := c.res
+		if r.GetOwner() != "o" || r.GetRegion() != "r" || r.GetTags()["environment"] != "production" {
+			t.Errorf("%s: owner=%q region=%q tags=%v", r.GetType(), r.GetOwner(), r.GetRegion(), r.GetTags())
+		}
+		want := c.provider
+		if want == "" {
+			want = ProviderAWS
+		}
+		if r.GetProvider() != want {
+			t.Errorf("%s: provider = %q, want %q", r.GetType(), r.GetProvider(), want)
+		}
+		if !r.GetCreatedAt().Equal(created) {
+			t.Errorf("%s: created = %v", r.GetType(), r.GetCreatedAt())
+		}
+		if !r.GetLastActive().IsZero() {
+			t.Errorf("%s: unset last-active time = %v, want zero", r.GetType(), r.GetLastActive())
+		}
+		if got := r.GetMonthlyCost(); math.Abs(got-c.monthly) > 1e-9 {
+			t.Errorf("%s: monthly cost = %v, want %v", r.GetType(), got, c.monthly)
+		}
+		if _, ok := r.Metrics()[c.metric]; !ok {
+			t.Errorf("%s: metrics %v lack %s", r.GetType(), r.Metrics(), c.metric)
+		}
+	}
+}
+
+func TestRelations(t *testing.T) {
+	vol := &EBSVolume{ID: "vol-1", AttachedTo: "vm-1"}
+	if got := RelationsOf(vol); len(got) != 1 || got[0] != (Relation{Kind: AttachedTo, ID: "vm-1"}) {
+		t.Errorf("volume relations = %v", got)
+	}
+	if got := RelationsOf(&EBSVolume{ID: "vol-2"}); got != nil {
+		t.Errorf("detached volume relations = %v, want none", got)
+	}
+	if got := RelationsOf(&VM{ID: "vm-1"}); got != nil {
+		t.Errorf("VM relations = %v, want none", got)
+	}
+}
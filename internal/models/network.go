@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+func (e *ElasticIP) UpdateUsage() {
+	if e.AssociatedWith != "" {
+		e.LastAssociated = sim.Now().Unix()
+	}
+}
+
+func (e *ElasticIP) GetId() string {
+	return e.ID
+}
+
+func (e *ElasticIP) GetUsage() float64 {
+	if e.AssociatedWith == "" {
+		return 0
+	}
+	return 1
+}
+
+func (e *ElasticIP) GetType() string {
+	return "ElasticIP"
+}
+
+func (e *ElasticIP) GetTags() map[string]string {
+	return e.Tags
+}
+
+func (e *ElasticIP) GetOwner() string {
+	return e.Owner
+}
+
+func (e *ElasticIP) GetRegion() string {
+	return e.Region
+}
+
+func (e *ElasticIP) GetProvider() string {
+	return ProviderAWS
+}
+
+func (e *ElasticIP) GetAccount() string {
+	return e.Account
+}
+
+func (e *ElasticIP) GetMonthlyCost() float64 {
+	return e.CostPerHour * hoursPerMonth
+}
+
+func (e *ElasticIP) GetCreatedAt() time.Time {
+	return unixTime(e.CreatedAt)
+}
+
+// GetLastActive is the last time the address was seen associated.
+func (e *ElasticIP) GetLastActive() time.Time {
+	return unixTime(e.LastAssociated)
+}
+
+func (e *ElasticIP) Metrics() map[string]float64 {
+	return map[string]float64{
+		"associated":      e.GetUsage(),
+		MetricCostPerHour: e.CostPerHour,
+	}
+}
+
+func (e *ElasticIP) Relations() []Relation {
+	if e.AssociatedWith == "" {
+		return nil
+	}
+	return []Relation{{Kind: AssociatedWith, ID: e.AssociatedWith}}
+}
+
+// UpdateUsage lets traffic drift by up to 20% per step.
+func (n *NATGateway) UpdateUsage() {
+	n.GBPerHour *= 0.8 + sim.Float64()*0.4
+	if n.GBPerHour > 0 {
+		n.LastActive = sim.Now().Unix()
+	}
+}
+
+func (n *NATGateway) GetId() string {
+	return n.ID
+}
+
+func (n *NATGateway) GetUsage() float64 {
+	return n.GBPerHour
+}
+
+func (n *NATGateway) GetType() string {
+	return "NATGateway"
+}
+
+func (n *NATGateway) GetTags() map[string]string {
+	return n.Tags
+}
+
+func (n *NATGateway) GetOwner() string {
+	return n.Owner
+}
+
+func (n *NATGateway) GetRegion() string {
+	return n.Region
+}
+
+func (n *NATGateway) GetProvider() string {
+	return ProviderAWS
+}
+
+func (n *NATGateway) GetAccount() string {
+	return n.Account
+}
+
+func (n *NATGateway) GetMonthlyCost() float64 {
+	return n.CostPerHourTotal() * hoursPerMonth
+}
+
+func (n *NATGateway) GetCreatedAt() time.Time {
+	return unixTime(n.CreatedAt)
+}
+
+func (n *NATGateway) GetLastActive() time.Time {
+	return unixTime(n.LastActive)
+}
+
+func (n *NATGateway) Metrics() map[string]float64 {
+	return map[string]float64{
+		"gb_per_hour":     n.GBPerHour,
+		MetricCostPerHour: n.CostPerHourTotal(),
+	}
+}
+
+// CostPerHourTotal is the hourly charge plus processing at the current
+// traffic.
+func (n *NATGateway) CostPerHourTotal() float64 {
+	return n.CostPerHour + n.GBPerHour*n.CostPerGB
+}
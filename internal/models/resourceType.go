@@ -1,72 +1,375 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
 
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+// CloudResource is implemented by every resource model, so features that only
+// need an owner, a region, tags or spend work for any resource type. Cost
+// fields on every resource type are in USD.
 type CloudResource interface {
 	UpdateUsage()
 	GetId() string
 	GetUsage() float64
 	GetType() string
+	GetOwner() string
+	// GetRegion is empty when the resource does not record one.
+	GetRegion() string
+	// GetProvider is one of the Provider* names.
+	GetProvider() string
+	// GetAccount is the AWS account, GCP project or Azure subscription the
+	// resource belongs to, empty when unknown.
+	GetAccount() string
+	GetTags() map[string]string
+	// GetMonthlyCost is the current spend rate over a 30-day month.
+	GetMonthlyCost() float64
+	// GetCreatedAt and GetLastActive are the zero time when unknown.
+	GetCreatedAt() time.Time
+	GetLastActive() time.Time
+	// Metrics returns the current usage and cost figures, keyed by the
+	// Metric* names where one applies.
+	Metrics() map[string]float64
 }
 
 type VM struct {
 	ID               string
+	Account          string
+	InstanceType     string
+	VCPU             int
+	MemoryGiB        float64
+	Region           string
+	AvailabilityZone string
 	CPUUsage         float64
+	MemoryUsage      float64
 	CostPerHour      float64
 	PreviousCostPerHour float64 
 	Owner            string
+	CreatedAt        int64
 	LastActive       int64 
+	Tags             map[string]string
 }
 
 type Storage struct {
 	ID              string
+	Account         string
+	Region          string
 	UsedGB          float64
 	CostPerGB       float64
 	PreviousCostPerGB float64 
 	LastAccessed    int64
 	Owner           string
+	CreatedAt       int64
+	Tags            map[string]string
 }
 
 type Lambda struct {
 	ID             string
+	Account        string
+	Region         string
 	Invocations    int
 	Errors         int
 	CostPerMillion float64
 	Owner          string
+	CreatedAt      int64
 	LastModified   int64
+	Tags           map[string]string
+	// IDs of the tables and buckets the function reads from and writes to.
+	ReadsFrom []string
+	WritesTo  []string
 }
 
 type ELB struct {
 	ID           string
+	Account      string
+	Region       string
 	RequestCount int
 	HealthyHosts int
 	CostPerHour  float64
 	Owner        string
+	CreatedAt    int64
 	LastChecked  int64
+	Tags         map[string]string
+	// Targets are the IDs of the VMs the load balancer routes to.
+	Targets []string
 }
 
 type S3 struct {
 	ID          string
+	Account     string
+	Region      string
 	UsedGB      float64
 	ObjectCount int
 	CostPerGB   float64
 	Owner       string
+	CreatedAt   int64
 	LastAccessed int64
+	Tags        map[string]string
 }
 
 type DynamoDB struct {
 	ID           string
+	Account      string
+	Region       string
 	ReadCapacity int
 	WriteCapacity int
 	ItemCount    int
 	CostPerHr    float64
 	Owner        string
+	CreatedAt    int64
 	LastUpdated  int64
+	Tags         map[string]string
+}
+
+// EBSVolume is a block storage volume. AttachedTo is the ID of the VM it is
+// attached to, empty when it is detached.
+type EBSVolume struct {
+	ID               string
+	Account          string
+	Region           string
+	AvailabilityZone string
+	VolumeType       string
+	SizeGB           float64
+	CostPerGBMonth   float64
+	AttachedTo       string
+	Owner            string
+	CreatedAt        int64
+	LastAttached     int64
+	Tags             map[string]string
+}
+
+// EBSSnapshot is a point-in-time copy of the volume VolumeID, which may since
+// have been deleted.
+type EBSSnapshot struct {
+	ID             string
+	Account        string
+	Region         string
+	VolumeID       string
+	SizeGB         float64
+	CostPerGBMonth float64
+	Owner          string
+	CreatedAt      int64
+	Tags           map[string]string
+}
+
+// ElasticIP is a static public address. AssociatedWith is the ID of the VM or
+// NAT gateway using it, empty when it is unassociated.
+type ElasticIP struct {
+	ID             string
+	Account        string
+	Region         string
+	PublicIP       string
+	AssociatedWith string
+	CostPerHour    float64
+	Owner          string
+	CreatedAt      int64
+	LastAssociated int64
+	Tags           map[string]string
+}
+
+// NATGateway is billed per hour plus per GB it processes.
+type NATGateway struct {
+	ID               string
+	Account          string
+	Region           string
+	AvailabilityZone string
+	GBPerHour        float64
+	CostPerHour      float64
+	CostPerGB        float64
+	Owner            string
+	CreatedAt        int64
+	LastActive       int64
+	Tags             map[string]string
+}
+
+// EKSCluster is a Kubernetes control plane. Its worker capacity is billed
+// through the EKSNodeGroup resources listed in NodeGroups.
+type EKSCluster struct {
+	ID                      string
+	Account                 string
+	Region                  string
+	KubernetesVersion       string
+	NodeGroups              []string
+	ControlPlaneCostPerHour float64
+	Owner                   string
+	CreatedAt               int64
+	LastActive              int64
+	Tags                    map[string]string
+}
+
+// EKSNodeGroup is a group of identical worker nodes in an EKS cluster.
+// Allocatable figures are per node; requested and used figures are totals
+// across the group's pods, in vCPU and GiB.
+type EKSNodeGroup struct {
+	ID                   string
+	Account              string
+	ClusterID            string
+	InstanceType         string
+	Region               string
+	NodeCount            int
+	MinNodes             int
+	MaxNodes             int
+	AllocatableCPU       float64
+	AllocatableMemoryGiB float64
+	RequestedCPU         float64
+	RequestedMemoryGiB   float64
+	UsedCPU              float64
+	UsedMemoryGiB        float64
+	Pods                 int
+	MaxPodsPerNode       int
+	CostPerNodeHour      float64
+	Owner                string
+	CreatedAt            int64
+	LastActive           int64
+	Tags                 map[string]string
+}
+
+// GCEInstance is a Google Compute Engine VM. GCP resources also record their
+// project; their labels are kept in Tags.
+type GCEInstance struct {
+	ID          string
+	Project     string
+	MachineType string
+	VCPU        int
+	MemoryGiB   float64
+	Region      string
+	Zone        string
+	CPUUsage    float64
+	MemoryUsage float64
+	CostPerHour float64
+	Owner       string
+	CreatedAt   int64
+	LastActive  int64
+	Tags        map[string]string
+}
+
+// GCSBucket is a Cloud Storage bucket. StorageClass is the catalog name of
+// its default class, e.g. "standard" or "coldline".
+type GCSBucket struct {
+	ID             string
+	Project        string
+	Region         string
+	StorageClass   string
+	UsedGB         float64
+	CostPerGBMonth float64
+	RequestsPerDay float64
+	Owner          string
+	CreatedAt      int64
+	LastAccessed   int64
+	Tags           map[string]string
+}
+
+// CloudSQLInstance is a Cloud SQL database instance.
+type CloudSQLInstance struct {
+	ID              string
+	Project         string
+	Tier            string
+	DatabaseVersion string
+	VCPU            int
+	MemoryGiB       float64
+	Region          string
+	CPUUsage        float64
+	Connections     int
+	CostPerHour     float64
+	Owner           string
+	CreatedAt       int64
+	LastActive      int64
+	Tags            map[string]string
+}
+
+// BigQueryDataset is billed for the data it stores plus the data its queries
+// scan, on demand.
+type BigQueryDataset struct {
+	ID                    string
+	Project               string
+	Region                string
+	StoredGB              float64
+	StorageCostPerGBMonth float64
+	TBScannedPerDay       float64
+	CostPerTBScanned      float64
+	Owner                 string
+	CreatedAt             int64
+	LastQueried           int64
+	Tags                  map[string]string
+}
+
+// AzureVM is an Azure virtual machine. Azure resources also record their
+// subscription and resource group.
+type AzureVM struct {
+	ID            string
+	Subscription  string
+	ResourceGroup string
+	Size          string
+	VCPU          int
+	MemoryGiB     float64
+	Region        string
+	CPUUsage      float64
+	MemoryUsage   float64
+	CostPerHour   float64
+	Owner         string
+	CreatedAt     int64
+	LastActive    int64
+	Tags          map[string]string
+}
+
+// AzureBlobContainer is a Blob Storage container. AccessTier is the catalog
+// name of its tier, e.g. "hot" or "cool".
+type AzureBlobContainer struct {
+	ID             string
+	Subscription   string
+	ResourceGroup  string
+	StorageAccount string
+	Region         string
+	AccessTier     string
+	UsedGB         float64
+	CostPerGBMonth float64
+	RequestsPerDay float64
+	Owner          string
+	CreatedAt      int64
+	LastAccessed   int64
+	Tags           map[string]string
+}
+
+// AzureSQLDatabase is an Azure SQL Database on the vCore model.
+type AzureSQLDatabase struct {
+	ID            string
+	Subscription  string
+	ResourceGroup string
+	Server        string
+	SKU           string
+	VCores        int
+	Region        string
+	CPUUsage      float64
+	Connections   int
+	CostPerHour   float64
+	Owner         string
+	CreatedAt     int64
+	LastActive    int64
+	Tags          map[string]string
+}
+
+// CosmosDBAccount is billed for the request units per second it provisions,
+// whether or not ConsumedRUs uses them.
+type CosmosDBAccount struct {
+	ID               string
+	Subscription     string
+	ResourceGroup    string
+	Region           string
+	API              string
+	ProvisionedRUs   float64
+	ConsumedRUs      float64
+	CostPer100RUHour float64
+	Owner            string
+	CreatedAt        int64
+	LastActive       int64
+	Tags             map[string]string
 }
 
 func (d *DynamoDB) UpdateUsage() {
-	d.ItemCount += 100 + int(time.Now().Unix()%30)
-	d.LastUpdated = time.Now().Unix()
+	d.ItemCount += 100 + int(sim.Now().Unix()%30)
+	d.LastUpdated = sim.Now().Unix()
 }
 
 func (d *DynamoDB) GetId() string {
@@ -81,10 +384,51 @@ func (d *DynamoDB) GetType() string {
 	return "DynamoDB"
 }
 
+func (d *DynamoDB) GetTags() map[string]string {
+	return d.Tags
+}
+
+func (d *DynamoDB) GetOwner() string {
+	return d.Owner
+}
+
+func (d *DynamoDB) GetRegion() string {
+	return d.Region
+}
+
+func (d *DynamoDB) GetProvider() string {
+	return ProviderAWS
+}
+
+func (d *DynamoDB) GetAccount() string {
+	return d.Account
+}
+
+func (d *DynamoDB) GetMonthlyCost() float64 {
+	return d.CostPerHr * hoursPerMonth
+}
+
+func (d *DynamoDB) GetCreatedAt() time.Time {
+	return unixTime(d.CreatedAt)
+}
+
+func (d *DynamoDB) GetLastActive() time.Time {
+	return unixTime(d.LastUpdated)
+}
+
+func (d *DynamoDB) Metrics() map[string]float64 {
+	return map[string]float64{
+		"read_capacity":   float64(d.ReadCapacity),
+		"write_capacity":  float64(d.WriteCapacity),
+		"items":           float64(d.ItemCount),
+		MetricCostPerHour: d.CostPerHr,
+	}
+}
+
 func (s *S3) UpdateUsage() {
-	s.UsedGB += 1.0 + float64(time.Now().Unix()%10)/10.0
-	s.ObjectCount += 100 + int(time.Now().Unix()%20)
-	s.LastAccessed = time.Now().Unix()
+	s.UsedGB += 1.0 + float64(sim.Now().Unix()%10)/10.0
+	s.ObjectCount += 100 + int(sim.Now().Unix()%20)
+	s.LastAccessed = sim.Now().Unix()
 }
 
 func (s *S3) GetId() string {
@@ -99,10 +443,50 @@ func (s *S3) GetType() string {
 	return "S3"
 }
 
+func (s *S3) GetTags() map[string]string {
+	return s.Tags
+}
+
+func (s *S3) GetOwner() string {
+	return s.Owner
+}
+
+func (s *S3) GetRegion() string {
+	return s.Region
+}
+
+func (s *S3) GetProvider() string {
+	return ProviderAWS
+}
+
+func (s *S3) GetAccount() string {
+	return s.Account
+}
+
+func (s *S3) GetMonthlyCost() float64 {
+	return s.UsedGB * s.CostPerGB
+}
+
+func (s *S3) GetCreatedAt() time.Time {
+	return unixTime(s.CreatedAt)
+}
+
+func (s *S3) GetLastActive() time.Time {
+	return unixTime(s.LastAccessed)
+}
+
+func (s *S3) Metrics() map[string]float64 {
+	return map[string]float64{
+		"used_gb":         s.UsedGB,
+		"objects":         float64(s.ObjectCount),
+		MetricCostPerHour: s.UsedGB * s.CostPerGB / hoursPerMonth,
+	}
+}
+
 func (e *ELB) UpdateUsage() {
-	e.RequestCount += 1000 + int(time.Now().Unix()%100)
-	e.HealthyHosts = 2 + int(time.Now().Unix()%3)
-	e.LastChecked = time.Now().Unix()
+	e.RequestCount += 1000 + int(sim.Now().Unix()%100)
+	e.HealthyHosts = 2 + int(sim.Now().Unix()%3)
+	e.LastChecked = sim.Now().Unix()
 }
 
 func (e *ELB) GetId() string {
@@ -117,10 +501,54 @@ func (e *ELB) GetType() string {
 	return "ELB"
 }
 
+func (e *ELB) GetTags() map[string]string {
+	return e.Tags
+}
+
+func (e *ELB) GetOwner() string {
+	return e.Owner
+}
+
+func (e *ELB) GetRegion() string {
+	return e.Region
+}
+
+func (e *ELB) GetProvider() string {
+	return ProviderAWS
+}
+
+func (e *ELB) GetAccount() string {
+	return e.Account
+}
+
+func (e *ELB) GetMonthlyCost() float64 {
+	return e.CostPerHour * hoursPerMonth
+}
+
+func (e *ELB) GetCreatedAt() time.Time {
+	return unixTime(e.CreatedAt)
+}
+
+func (e *ELB) GetLastActive() time.Time {
+	return unixTime(e.LastChecked)
+}
+
+func (e *ELB) Relations() []Relation {
+	return relationsTo(RoutesTo, e.Targets)
+}
+
+func (e *ELB) Metrics() map[string]float64 {
+	return map[string]float64{
+		"requests":        float64(e.RequestCount),
+		"healthy_hosts":   float64(e.HealthyHosts),
+		MetricCostPerHour: e.CostPerHour,
+	}
+}
+
 func (l *Lambda) UpdateUsage() {
-	l.Invocations += 100 + int(time.Now().Unix()%50)
-	l.Errors += int(time.Now().Unix() % 3)
-	l.LastModified = time.Now().Unix()
+	l.Invocations += 100 + int(sim.Now().Unix()%50)
+	l.Errors += int(sim.Now().Unix() % 3)
+	l.LastModified = sim.Now().Unix()
 }
 
 func (l *Lambda) GetId() string {
@@ -135,11 +563,74 @@ func (l *Lambda) GetType() string {
 	return "Lambda"
 }
 
+func (l *Lambda) GetTags() map[string]string {
+	return l.Tags
+}
+
+func (l *Lambda) GetOwner() string {
+	return l.Owner
+}
+
+func (l *Lambda) GetRegion() string {
+	return l.Region
+}
+
+func (l *Lambda) GetProvider() string {
+	return ProviderAWS
+}
+
+func (l *Lambda) GetAccount() string {
+	return l.Account
+}
+
+// GetMonthlyCost of a Lambda is its average invocation rate since it was
+// created, priced per request: its counters are cumulative. It is 0 when the
+// creation time is unknown. Elapsed time under an hour counts as an hour, so
+// a new function's first invocations are not extrapolated.
+func (l *Lambda) GetMonthlyCost() float64 {
+	if l.CreatedAt == 0 {
+		return 0
+	}
+	hours := math.Max(sim.Now().Sub(unixTime(l.CreatedAt)).Hours(), 1)
+	return float64(l.Invocations) * l.CostPerMillion / 1e6 / hours * hoursPerMonth
+}
+
+func (l *Lambda) GetCreatedAt() time.Time {
+	return unixTime(l.CreatedAt)
+}
+
+func (l *Lambda) GetLastActive() time.Time {
+	return unixTime(l.LastModified)
+}
+
+func (l *Lambda) Relations() []Relation {
+	return append(relationsTo(ReadsFrom, l.ReadsFrom), relationsTo(WritesTo, l.WritesTo)...)
+}
+
+func (l *Lambda) Metrics() map[string]float64 {
+	return map[string]float64{
+		"invocations":   float64(l.Invocations),
+		"errors":        float64(l.Errors),
+		MetricCostTotal: float64(l.Invocations) * l.CostPerMillion / 1e6,
+	}
+}
+
 type Database struct {
 	ID              string
+	Account         string
+	InstanceClass   string
+	Engine          string
+	VCPU            int
+	MemoryGiB       float64
+	Region          string
+	AvailabilityZone string
 	Connections     int
 	CPUUsage        float64
+	MemoryUsage     float64
 	CostPerHr       float64
 	PreviousCostPerHr float64 
 	Owner           string
+	CreatedAt       int64
+	LastActive      int64
+	Tags            map[string]string
 }
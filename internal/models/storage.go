@@ -2,13 +2,14 @@ package models
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
 )
 
 func (s *Storage) UpdateUsage() {
-	s.UsedGB += rand.Float64() * 2
-	s.LastAccessed = time.Now().Unix()
+	s.UsedGB += sim.Float64() * 2
+	s.LastAccessed = sim.Now().Unix()
 }
 func (storage *Storage) GetId() string {
 	return storage.ID
@@ -22,6 +23,45 @@ func (storage *Storage) GetType() string {
 	return "Storage"
 }
 
+func (storage *Storage) GetTags() map[string]string {
+	return storage.Tags
+}
+
+func (storage *Storage) GetOwner() string {
+	return storage.Owner
+}
+
+func (storage *Storage) GetRegion() string {
+	return storage.Region
+}
+
+func (storage *Storage) GetProvider() string {
+	return ProviderAWS
+}
+
+func (storage *Storage) GetAccount() string {
+	return storage.Account
+}
+
+func (storage *Storage) GetMonthlyCost() float64 {
+	return storage.UsedGB * storage.CostPerGB
+}
+
+func (storage *Storage) GetCreatedAt() time.Time {
+	return unixTime(storage.CreatedAt)
+}
+
+func (storage *Storage) GetLastActive() time.Time {
+	return unixTime(storage.LastAccessed)
+}
+
+func (storage *Storage) Metrics() map[string]float64 {
+	return map[string]float64{
+		"used_gb":         storage.UsedGB,
+		MetricCostPerHour: storage.UsedGB * storage.CostPerGB / hoursPerMonth,
+	}
+}
+
 func (s *Storage) String() string {
 	return fmt.Sprintf("Storage[ID=%s, UsedGB=%.2f, CostPerGB=%.2f, PreviousCostPerGB=%.2f, LastAccessed=%d, Owner=%s]", s.ID, s.UsedGB, s.CostPerGB, s.PreviousCostPerGB, s.LastAccessed, s.Owner)
 }
@@ -2,14 +2,16 @@ package models
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/sim"
 )
 
 func (vm *VM) UpdateUsage() {
-	vm.CPUUsage = rand.Float64() * 100
-	if rand.Float64() < 0.2 {
-		vm.LastActive = time.Now().Unix()
+	vm.CPUUsage = sim.Float64() * 100
+	vm.MemoryUsage = 20 + sim.Float64()*40
+	if sim.Float64() < 0.2 {
+		vm.LastActive = sim.Now().Unix()
 	}
 }
 
@@ -25,6 +27,46 @@ func (vm *VM) GetType() string {
 	return "VM"
 }
 
+func (vm *VM) GetTags() map[string]string {
+	return vm.Tags
+}
+
+func (vm *VM) GetOwner() string {
+	return vm.Owner
+}
+
+func (vm *VM) GetRegion() string {
+	return vm.Region
+}
+
+func (vm *VM) GetProvider() string {
+	return ProviderAWS
+}
+
+func (vm *VM) GetAccount() string {
+	return vm.Account
+}
+
+func (vm *VM) GetMonthlyCost() float64 {
+	return vm.CostPerHour * hoursPerMonth
+}
+
+func (vm *VM) GetCreatedAt() time.Time {
+	return unixTime(vm.CreatedAt)
+}
+
+func (vm *VM) GetLastActive() time.Time {
+	return unixTime(vm.LastActive)
+}
+
+func (vm *VM) Metrics() map[string]float64 {
+	return map[string]float64{
+		MetricCPU:         vm.CPUUsage,
+		MetricMemory:      vm.MemoryUsage,
+		MetricCostPerHour: vm.CostPerHour,
+	}
+}
+
 func (vm *VM) String() string {
-	return fmt.Sprintf("VM[ID=%s, CPUUsage=%.2f, CostPerHour=%.2f, PreviousCostPerHour=%.2f, Owner=%s, LastActive=%d]", vm.ID, vm.CPUUsage, vm.CostPerHour, vm.PreviousCostPerHour, vm.Owner, vm.LastActive)
+	return fmt.Sprintf("VM[ID=%s, InstanceType=%s, Region=%s, CPUUsage=%.2f, MemoryUsage=%.2f, CostPerHour=%.2f, PreviousCostPerHour=%.2f, Owner=%s, LastActive=%d]", vm.ID, vm.InstanceType, vm.Region, vm.CPUUsage, vm.MemoryUsage, vm.CostPerHour, vm.PreviousCostPerHour, vm.Owner, vm.LastActive)
 }
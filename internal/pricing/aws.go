@@ -0,0 +1,170 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// awsOffer is the subset of an AWS Price List bulk offer file
+// (offers/v1.0/aws/<service>/current/<region>/index.json) that we read.
+type awsOffer struct {
+	Products map[string]awsProduct `json:"products"`
+	Terms    struct {
+		OnDemand map[string]map[string]awsTerm `json:"OnDemand"`
+		Reserved map[string]map[string]awsTerm `json:"Reserved"`
+	} `json:"terms"`
+}
+
+type awsProduct struct {
+	SKU           string            `json:"sku"`
+	ProductFamily string            `json:"productFamily"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+type awsTerm struct {
+	TermAttributes  map[string]string `json:"termAttributes"`
+	PriceDimensions map[string]struct {
+		Unit         string            `json:"unit"`
+		BeginRange   string            `json:"beginRange"`
+		PricePerUnit map[string]string `json:"pricePerUnit"`
+	} `json:"priceDimensions"`
+}
+
+// S3 volume types in the bulk file and the storage classes they map to.
+var awsStorageClasses = map[string]string{
+	"Standard":                            "standard",
+	"Standard - Infrequent Access":        "standard_ia",
+	"One Zone - Infrequent Access":        "onezone_ia",
+	"Amazon Glacier":                      "glacier",
+	"Glacier Flexible Retrieval":          "glacier",
+	"Glacier Deep Archive":                "deep_archive",
+	"Intelligent-Tiering Frequent Access": "intelligent_tiering",
+}
+
+// ImportAWSPriceList reads an AWS Price List bulk offer file for AmazonEC2,
+// AmazonRDS or AmazonS3 and returns the prices it understands: Linux shared-
+// tenancy EC2 instances, single-AZ RDS classes and the first tier of each S3
+// storage class. Spot prices are not part of the bulk files and stay unset.
+func ImportAWSPriceList(r io.Reader) ([]InstancePrice, []StoragePrice, error) {
+	var offer awsOffer
+	if err := json.NewDecoder(r).Decode(&offer); err != nil {
+		return nil, nil, fmt.Errorf("decode offer file: %w", err)
+	}
+
+	var instances []InstancePrice
+	var storage []StoragePrice
+	for sku, p := range offer.Products {
+		a := p.Attributes
+		region := a["regionCode"]
+		if region == "" {
+			continue
+		}
+		switch p.ProductFamily {
+		case "Compute Instance":
+			if a["operatingSystem"] != "Linux" || a["tenancy"] != "Shared" || a["preInstalledSw"] != "NA" {
+				continue
+			}
+			if cs, ok := a["capacitystatus"]; ok && cs != "Used" {
+				continue
+			}
+			ip := instanceFromAttributes("ec2", "", a)
+			ip.OnDemandHourly = onDemandPrice(offer.Terms.OnDemand[sku], "Hrs")
+			ip.ReservedHourly = reservedHourly(offer.Terms.Reserved[sku])
+			if ip.OnDemandHourly > 0 {
+				instances = append(instances, ip)
+			}
+		case "Database Instance":
+			if a["deploymentOption"] != "Single-AZ" {
+				continue
+			}
+			ip := instanceFromAttributes("rds", engineName(a["databaseEngine"]), a)
+			ip.OnDemandHourly = onDemandPrice(offer.Terms.OnDemand[sku], "Hrs")
+			ip.ReservedHourly = reservedHourly(offer.Terms.Reserved[sku])
+			if ip.OnDemandHourly > 0 {
+				instances = append(instances, ip)
+			}
+		case "Storage":
+			class, ok := awsStorageClasses[a["volumeType"]]
+			if !ok {
+				continue
+			}
+			price := onDemandPrice(offer.Terms.OnDemand[sku], "GB-Mo")
+			if price > 0 {
+				storage = append(storage, StoragePrice{Class: class, Region: region, PerGBMonth: price})
+			}
+		}
+	}
+	return instances, storage, nil
+}
+
+func instanceFromAttributes(service, engine string, a map[string]string) InstancePrice {
+	vcpu, _ := strconv.Atoi(a["vcpu"])
+	mem, _ := strconv.ParseFloat(strings.TrimSuffix(strings.ReplaceAll(a["memory"], ",", ""), " GiB"), 64)
+	return InstancePrice{
+		Service:   service,
+		Type:      a["instanceType"],
+		Region:    a["regionCode"],
+		Engine:    engine,
+		VCPU:      vcpu,
+		MemoryGiB: mem,
+	}
+}
+
+// engineName maps the bulk file's databaseEngine to the short names used in
+// the catalog ("PostgreSQL" -> "postgres").
+func engineName(engine string) string {
+	switch engine {
+	case "PostgreSQL":
+		return "postgres"
+	case "MySQL":
+		return "mysql"
+	case "MariaDB":
+		return "mariadb"
+	case "Aurora PostgreSQL":
+		return "aurora-postgresql"
+	case "Aurora MySQL":
+		return "aurora-mysql"
+	}
+	return strings.ToLower(engine)
+}
+
+// onDemandPrice returns the USD price of the first tier with the given unit.
+func onDemandPrice(terms map[string]awsTerm, unit string) float64 {
+	for _, t := range terms {
+		for _, d := range t.PriceDimensions {
+			if d.Unit != unit || (d.BeginRange != "" && d.BeginRange != "0") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(d.PricePerUnit["USD"], 64); err == nil {
+				return v
+			}
+		}
+	}
+	return 0
+}
+
+// reservedHourly returns the hourly rate of the 1-year no-upfront standard
+// reservation, which has no upfront fee to amortize.
+func reservedHourly(terms map[string]awsTerm) float64 {
+	for _, t := range terms {
+		ta := t.TermAttributes
+		if ta["LeaseContractLength"] != "1yr" || ta["PurchaseOption"] != "No Upfront" {
+			continue
+		}
+		if class, ok := ta["OfferingClass"]; ok && class != "standard" {
+			continue
+		}
+		for _, d := range t.PriceDimensions {
+			if d.Unit != "Hrs" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(d.PricePerUnit["USD"], 64); err == nil && v > 0 {
+				return v
+			}
+		}
+	}
+	return 0
+}
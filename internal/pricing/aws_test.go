@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+)
+
+const offerFixture = `{
+  "products": {
+    "EC2A": {"sku": "EC2A", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "t3.large", "regionCode": "us-east-1", "vcpu": "2", "memory": "8 GiB",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "EC2W": {"sku": "EC2W", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "t3.large", "regionCode": "us-east-1", "vcpu": "2", "memory": "8 GiB",
+      "operatingSystem": "Windows", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "RDS1": {"sku": "RDS1", "productFamily": "Database Instance", "attributes": {
+      "instanceType": "db.m5.large", "regionCode": "us-east-1", "vcpu": "2", "memory": "8 GiB",
+      "databaseEngine": "PostgreSQL", "deploymentOption": "Single-AZ"}},
+    "S3IA": {"sku": "S3IA", "productFamily": "Storage", "attributes": {
+      "regionCode": "us-east-1", "volumeType": "Standard - Infrequent Access"}}
+  },
+  "terms": {
+    "OnDemand": {
+      "EC2A": {"EC2A.JRTCKXETXF": {"priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0832000000"}}}}},
+      "EC2W": {"EC2W.JRTCKXETXF": {"priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.1108000000"}}}}},
+      "RDS1": {"RDS1.JRTCKXETXF": {"priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.1780000000"}}}}},
+      "S3IA": {"S3IA.JRTCKXETXF": {"priceDimensions": {"d": {"unit": "GB-Mo", "beginRange": "0", "pricePerUnit": {"USD": "0.0125000000"}}}}}
+    },
+    "Reserved": {
+      "EC2A": {
+        "EC2A.3yr": {"termAttributes": {"LeaseContractLength": "3yr", "PurchaseOption": "No Upfront", "OfferingClass": "standard"},
+          "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0360000000"}}}},
+        "EC2A.1yr": {"termAttributes": {"LeaseContractLength": "1yr", "PurchaseOption": "No Upfront", "OfferingClass": "standard"},
+          "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0520000000"}}}}
+      }
+    }
+  }
+}`
+
+func TestImportAWSPriceList(t *testing.T) {
+	instances, storage, err := ImportAWSPriceList(strings.NewReader(offerFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Catalog{}
+	c.reindex()
+	c.Merge(instances, storage)
+
+	if len(c.Instances) != 2 {
+		t.Fatalf("imported %d instances, want 2 (Windows must be skipped): %+v", len(c.Instances), c.Instances)
+	}
+	vm, ok := c.Instance("ec2", "", "t3.large", "us-east-1")
+	if !ok || vm.OnDemandHourly != 0.0832 || vm.ReservedHourly != 0.052 || vm.VCPU != 2 || vm.MemoryGiB != 8 {
+		t.Errorf("t3.large = %+v, %v", vm, ok)
+	}
+	db, ok := c.Instance("rds", "postgres", "db.m5.large", "us-east-1")
+	if !ok || db.OnDemandHourly != 0.178 {
+		t.Errorf("db.m5.large = %+v, %v", db, ok)
+	}
+	ia, ok := c.StorageClass("standard_ia", "us-east-1")
+	if !ok || ia.PerGBMonth != 0.0125 {
+		t.Errorf("standard_ia = %+v, %v", ia, ok)
+	}
+}
+
+func TestMergeKeepsSpotPrices(t *testing.T) {
+	c := Default()
+	before, _ := c.Instance("ec2", "", "t3.large", "us-east-1")
+	c.Merge([]InstancePrice{{Service: "ec2", Type: "t3.large", Region: "us-east-1", OnDemandHourly: 0.09}}, nil)
+	after, _ := c.Instance("ec2", "", "t3.large", "us-east-1")
+	if after.OnDemandHourly != 0.09 || after.SpotHourly != before.SpotHourly {
+		t.Errorf("merged t3.large = %+v, want new on-demand price and spot %v", after, before.SpotHourly)
+	}
+}
@@ -0,0 +1,228 @@
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HoursPerMonth matches the 24*30 month the analyzer uses for monthly figures.
+const HoursPerMonth = 24 * 30
+
+const DefaultRegion = "us-east-1"
+
+// Purchase options for instance prices.
+const (
+	OnDemand = "on_demand"
+	Reserved = "reserved"
+	Spot     = "spot"
+)
+
+// Catalog is a local price table for compute instances, database classes and
+// storage classes, keyed by region. Prices are in Currency (USD by default).
+type Catalog struct {
+	Currency  string          `yaml:"currency" json:"currency"`
+	Instances []InstancePrice `yaml:"instances" json:"instances"`
+	Storage   []StoragePrice  `yaml:"storage" json:"storage"`
+
+	instanceIndex map[string]int
+	storageIndex  map[string]int
+}
+
+// InstancePrice is the hourly price of one SKU. Service is "ec2" or "rds";
+// Engine is only set for rds. ReservedHourly is the effective hourly rate of a
+// 1-year no-upfront standard reservation. A zero price means "not offered".
+type InstancePrice struct {
+	Service        string  `yaml:"service" json:"service"`
+	Type           string  `yaml:"type" json:"type"`
+	Region         string  `yaml:"region" json:"region"`
+	Engine         string  `yaml:"engine,omitempty" json:"engine,omitempty"`
+	VCPU           int     `yaml:"vcpu" json:"vcpu"`
+	MemoryGiB      float64 `yaml:"memory_gib" json:"memory_gib"`
+	OnDemandHourly float64 `yaml:"on_demand_hourly" json:"on_demand_hourly"`
+	ReservedHourly float64 `yaml:"reserved_hourly,omitempty" json:"reserved_hourly,omitempty"`
+	SpotHourly     float64 `yaml:"spot_hourly,omitempty" json:"spot_hourly,omitempty"`
+}
+
+// StoragePrice is the monthly per-GB price of a storage class, e.g.
+// "standard", "standard_ia", "glacier".
+type StoragePrice struct {
+	Class      string  `yaml:"class" json:"class"`
+	Region     string  `yaml:"region" json:"region"`
+	PerGBMonth float64 `yaml:"per_gb_month" json:"per_gb_month"`
+}
+
+//go:embed default_catalog.yaml
+var defaultCatalog []byte
+
+// Default returns the catalog bundled with the binary.
+func Default() *Catalog {
+	c, err := Parse(defaultCatalog)
+	if err != nil {
+		panic("pricing: invalid bundled catalog: " + err.Error())
+	}
+	return c
+}
+
+// Load reads a catalog file. JSON files are accepted as well as YAML.
+func Load(path string) (*Catalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse catalog %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func Parse(b []byte) (*Catalog, error) {
+	var c Catalog
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if c.Currency == "" {
+		c.Currency = "USD"
+	}
+	c.reindex()
+	return &c, nil
+}
+
+// Save writes the catalog as indented JSON, sorted for stable diffs.
+func (c *Catalog) Save(path string) error {
+	sort.Slice(c.Instances, func(i, j int) bool {
+		a, b := c.Instances[i], c.Instances[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Engine != b.Engine {
+			return a.Engine < b.Engine
+		}
+		return a.Type < b.Type
+	})
+	sort.Slice(c.Storage, func(i, j int) bool {
+		if c.Storage[i].Region != c.Storage[j].Region {
+			return c.Storage[i].Region < c.Storage[j].Region
+		}
+		return c.Storage[i].Class < c.Storage[j].Class
+	})
+	c.reindex()
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+func instanceKey(service, engine, typ, region string) string {
+	return service + "|" + engine + "|" + typ + "|" + region
+}
+
+func storageKey(class, region string) string {
+	return class + "|" + region
+}
+
+func (c *Catalog) reindex() {
+	c.instanceIndex = make(map[string]int, len(c.Instances))
+	for i, p := range c.Instances {
+		c.instanceIndex[instanceKey(p.Service, p.Engine, p.Type, p.Region)] = i
+	}
+	c.storageIndex = make(map[string]int, len(c.Storage))
+	for i, p := range c.Storage {
+		c.storageIndex[storageKey(p.Class, p.Region)] = i
+	}
+}
+
+// Instance looks up an EC2 instance type ("ec2", "", type) or an RDS class
+// ("rds", engine, class) in region.
+func (c *Catalog) Instance(service, engine, typ, region string) (InstancePrice, bool) {
+	i, ok := c.instanceIndex[instanceKey(service, engine, typ, region)]
+	if !ok {
+		return InstancePrice{}, false
+	}
+	return c.Instances[i], true
+}
+
+func (c *Catalog) StorageClass(class, region string) (StoragePrice, bool) {
+	i, ok := c.storageIndex[storageKey(class, region)]
+	if !ok {
+		return StoragePrice{}, false
+	}
+	return c.Storage[i], true
+}
+
+// Hourly returns the hourly price of p under a purchase option, or 0 if that
+// option is not offered.
+func (p InstancePrice) Hourly(option string) float64 {
+	switch option {
+	case Reserved:
+		return p.ReservedHourly
+	case Spot:
+		return p.SpotHourly
+	default:
+		return p.OnDemandHourly
+	}
+}
+
+// ClosestInstance returns the SKU of service/engine in region whose on-demand
+// price is nearest to hourly. It is used when a resource's own type is unknown.
+func (c *Catalog) ClosestInstance(service, engine, region string, hourly float64) (InstancePrice, bool) {
+	var best InstancePrice
+	found := false
+	for _, p := range c.Instances {
+		if p.Service != service || p.Engine != engine || p.Region != region || p.OnDemandHourly == 0 {
+			continue
+		}
+		if !found || math.Abs(p.OnDemandHourly-hourly) < math.Abs(best.OnDemandHourly-hourly) {
+			best = p
+			found = true
+		}
+	}
+	return best, found
+}
+
+// MonthlySavings is the monthly difference between running from at fromOption
+// and to at toOption, floored at zero. It returns false if either price is
+// not offered.
+func MonthlySavings(from InstancePrice, fromOption string, to InstancePrice, toOption string) (float64, bool) {
+	a, b := from.Hourly(fromOption), to.Hourly(toOption)
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+	return math.Max(0, (a-b)*HoursPerMonth), true
+}
+
+// Merge adds or replaces prices, keeping existing spot prices when the incoming
+// entry has none (the AWS bulk files do not carry spot prices).
+func (c *Catalog) Merge(instances []InstancePrice, storage []StoragePrice) {
+	for _, p := range instances {
+		k := instanceKey(p.Service, p.Engine, p.Type, p.Region)
+		if i, ok := c.instanceIndex[k]; ok {
+			if p.SpotHourly == 0 {
+				p.SpotHourly = c.Instances[i].SpotHourly
+			}
+			c.Instances[i] = p
+			continue
+		}
+		c.instanceIndex[k] = len(c.Instances)
+		c.Instances = append(c.Instances, p)
+	}
+	for _, p := range storage {
+		k := storageKey(p.Class, p.Region)
+		if i, ok := c.storageIndex[k]; ok {
+			c.Storage[i] = p
+			continue
+		}
+		c.storageIndex[k] = len(c.Storage)
+		c.Storage = append(c.Storage, p)
+	}
+}
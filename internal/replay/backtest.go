@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+)
+
+// BacktestReport compares what a candidate rule configuration would have raised
+// over a recording with what the built-in thresholds raise over the same window.
+type BacktestReport struct {
+	Recording      string         `json:"recording"`
+	Snapshots      int            `json:"snapshots"`
+	Start          time.Time      `json:"start"`
+	End            time.Time      `json:"end"`
+	CandidateRules analyzer.Rules `json:"candidate_rules"`
+	Rules          []RuleDiff     `json:"rules"`
+	Totals         BacktestTotals `json:"totals"`
+}
+
+type BacktestTotals struct {
+	CandidateSuggestions int     `json:"candidate_suggestions"`
+	BaselineSuggestions  int     `json:"baseline_suggestions"`
+	CandidateSavingsUSD  float64 `json:"candidate_savings_usd"`
+	BaselineSavingsUSD   float64 `json:"baseline_savings_usd"`
+	SavingsDeltaUSD      float64 `json:"savings_delta_usd"`
+}
+
+// RuleDiff is one check's outcome under both configurations. AddedResources are
+// only flagged by the candidate, RemovedResources only by the baseline.
+type RuleDiff struct {
+	ResourceType     string                `json:"resource_type"`
+	Action           string                `json:"action"`
+	Candidate        *analyzer.RuleSummary `json:"candidate,omitempty"`
+	Baseline         *analyzer.RuleSummary `json:"baseline,omitempty"`
+	CountDelta       int                   `json:"count_delta"`
+	SavingsDeltaUSD  float64               `json:"savings_delta_usd"`
+	AddedResources   []string              `json:"added_resources,omitempty"`
+	RemovedResources []string              `json:"removed_resources,omitempty"`
+}
+
+// Backtest replays path instantly under candidate and under DefaultRules and
+// diffs the results per check.
+func Backtest(ctx context.Context, path string, candidate analyzer.Rules) (*BacktestReport, error) {
+	candidateSink := &analyzer.InMemorySuggestionSink{}
+	result, err := Replay(ctx, path, candidateSink, Options{Rules: candidate})
+	if err != nil {
+		return nil, err
+	}
+	baselineSink := &analyzer.InMemorySuggestionSink{}
+	if _, err := Replay(ctx, path, baselineSink, Options{Rules: analyzer.DefaultRules()}); err != nil {
+		return nil, err
+	}
+
+	report := &BacktestReport{
+		Recording:      path,
+		Snapshots:      result.Snapshots,
+		Start:          result.Start,
+		End:            result.End,
+		CandidateRules: candidate,
+	}
+	candidateSuggestions := candidateSink.GetSuggestions()
+	baselineSuggestions := baselineSink.GetSuggestions()
+	report.Totals.CandidateSuggestions = len(candidateSuggestions)
+	report.Totals.BaselineSuggestions = len(baselineSuggestions)
+
+	type key struct{ resourceType, action string }
+	diffs := make(map[key]*RuleDiff)
+	diffFor := func(rs analyzer.RuleSummary) *RuleDiff {
+		k := key{rs.ResourceType, rs.Action}
+		d, ok := diffs[k]
+		if !ok {
+			d = &RuleDiff{ResourceType: rs.ResourceType, Action: rs.Action}
+			diffs[k] = d
+		}
+		return d
+	}
+	for _, rs := range analyzer.SummarizeRules(candidateSuggestions, result.Start) {
+		rs := rs
+		diffFor(rs).Candidate = &rs
+		report.Totals.CandidateSavingsUSD += rs.EstimatedSavingsUSD
+	}
+	for _, rs := range analyzer.SummarizeRules(baselineSuggestions, result.Start) {
+		rs := rs
+		diffFor(rs).Baseline = &rs
+		report.Totals.BaselineSavingsUSD += rs.EstimatedSavingsUSD
+	}
+	report.Totals.SavingsDeltaUSD = report.Totals.CandidateSavingsUSD - report.Totals.BaselineSavingsUSD
+
+	for _, d := range diffs {
+		var candidateIDs, baselineIDs []string
+		if d.Candidate != nil {
+			d.CountDelta += d.Candidate.Count
+			d.SavingsDeltaUSD += d.Candidate.EstimatedSavingsUSD
+			candidateIDs = d.Candidate.Resources
+		}
+		if d.Baseline != nil {
+			d.CountDelta -= d.Baseline.Count
+			d.SavingsDeltaUSD -= d.Baseline.EstimatedSavingsUSD
+			baselineIDs = d.Baseline.Resources
+		}
+		d.AddedResources = missingFrom(candidateIDs, baselineIDs)
+		d.RemovedResources = missingFrom(baselineIDs, candidateIDs)
+		report.Rules = append(report.Rules, *d)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].ResourceType != report.Rules[j].ResourceType {
+			return report.Rules[i].ResourceType < report.Rules[j].ResourceType
+		}
+		return report.Rules[i].Action < report.Rules[j].Action
+	})
+	return report, nil
+}
+
+// missingFrom returns the ids in a that are not in b, in a's order.
+func missingFrom(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, id := range b {
+		in[id] = true
+	}
+	var out []string
+	for _, id := range a {
+		if !in[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+// Snapshot is one line of a recording: the state of a single resource at the
+// (possibly virtual) time it was emitted by the simulation. Resource is in the
+// models wire format; recordings made before it carried a type are decoded
+// using Type.
+type Snapshot struct {
+	Time     time.Time       `json:"t"`
+	Type     string          `json:"type"`
+	Resource json.RawMessage `json:"r"`
+}
+
+// NewSnapshot captures res as of t.
+func NewSnapshot(t time.Time, res models.CloudResource) (Snapshot, error) {
+	body, err := models.Marshal(res)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Time: t, Type: res.GetType(), Resource: body}, nil
+}
+
+// Recorder appends snapshots to a JSONL file.
+type Recorder struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (r *Recorder) Record(res models.CloudResource) error {
+	snap, err := NewSnapshot(sim.Now(), res)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Flush()
+}
+
+func (r *Recorder) Close() error {
+	if err := r.Flush(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+// Decode turns a snapshot back into its concrete model.
+func (s Snapshot) Decode() (models.CloudResource, error) {
+	return models.UnmarshalAs(s.Type, s.Resource)
+}
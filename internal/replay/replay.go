@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+type Options struct {
+	// Speed replays at that multiple of the recorded pace; zero replays instantly.
+	Speed float64
+	Rules analyzer.Rules
+	// OnSnapshot, when set, is called for every decoded resource after analysis.
+	OnSnapshot func(t time.Time, res models.CloudResource)
+}
+
+type Result struct {
+	Snapshots int       `json:"snapshots"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// ReadFile calls fn for every snapshot in a recording, in order.
+func ReadFile(path string, fn func(Snapshot) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := fn(snap); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Replay feeds a recording through the analyzer with opts.Rules. Each snapshot
+// is evaluated at its recorded time, so time-based checks and suggestion
+// timestamps see the original times; the shared clock is left alone. Usage
+// history starts empty, so rightsizing only sees the recording.
+func Replay(ctx context.Context, path string, sink analyzer.SuggestionSink, opts Options) (*Result, error) {
+	result := &Result{}
+	hist := history.NewStore(0)
+	err := ReadFile(path, func(snap Snapshot) error {
+		res, err := snap.Decode()
+		if err != nil {
+			return err
+		}
+		if result.Snapshots == 0 {
+			result.Start = snap.Time
+		} else if opts.Speed > 0 {
+			if wait := time.Duration(float64(snap.Time.Sub(result.End)) / opts.Speed); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		analyzer.AnalyzeWith(res, sink, analyzer.Env{Rules: opts.Rules, Now: snap.Time, History: hist})
+		if opts.OnSnapshot != nil {
+			opts.OnSnapshot(snap.Time, res)
+		}
+		result.Snapshots++
+		result.End = snap.Time
+		return nil
+	})
+	return result, err
+}
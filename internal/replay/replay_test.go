@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+// writeRecording records ten hourly snapshots of an always-active VM and an
+// underused ELB.
+func writeRecording(t *testing.T) string {
+	t.Helper()
+	defer sim.SetClock(sim.RealClock{})
+	clock := sim.UseSeed(1, time.Time{})
+
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	rec, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := []models.CloudResource{
+		&models.VM{ID: "vm-1", CostPerHour: 0.05, Owner: "Finance Team", LastActive: clock.Now().Unix()},
+		&models.ELB{ID: "elb-1", RequestCount: 500, HealthyHosts: 3, CostPerHour: 0.025, Owner: "WebOps"},
+	}
+	for i := 0; i < 10; i++ {
+		for _, r := range resources {
+			if err := rec.Record(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		clock.Advance(time.Hour)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := writeRecording(t)
+
+	baseline := &analyzer.InMemorySuggestionSink{}
+	result, err := Replay(context.Background(), path, baseline, Options{Rules: analyzer.DefaultRules()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Snapshots != 20 {
+		t.Fatalf("replayed %d snapshots, want 20", result.Snapshots)
+	}
+	if got := result.End.Sub(result.Start); got != 9*time.Hour {
+		t.Errorf("replay spans %s, want 9h", got)
+	}
+
+	// Lowering the idle threshold to zero days must flag the VM on every snapshot
+	// after the first hour, which the default 30 days never does.
+	strict := analyzer.DefaultRules()
+	strict.VMIdleDays = 0
+	candidate := &analyzer.InMemorySuggestionSink{}
+	if _, err := Replay(context.Background(), path, candidate, Options{Rules: strict}); err != nil {
+		t.Fatal(err)
+	}
+	count := func(sink *analyzer.InMemorySuggestionSink) int {
+		n := 0
+		for _, s := range sink.GetSuggestions() {
+			if s.ResourceID == "vm-1" && s.Action == "Terminate" {
+				n++
+			}
+		}
+		return n
+	}
+	if got := count(baseline); got != 0 {
+		t.Errorf("default rules raised %d idle suggestions, want 0", got)
+	}
+	if got := count(candidate); got != 9 {
+		t.Errorf("strict rules raised %d idle suggestions, want 9", got)
+	}
+}
+
+func TestBacktestDiffsAgainstDefaults(t *testing.T) {
+	path := writeRecording(t)
+
+	candidate := analyzer.DefaultRules()
+	candidate.VMIdleDays = 0
+	candidate.ELBMinRequests = 100
+	report, err := Backtest(context.Background(), path, candidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byAction := make(map[string]RuleDiff)
+	for _, d := range report.Rules {
+		byAction[d.ResourceType+"/"+d.Action] = d
+	}
+	idle := byAction["VM/Terminate"]
+	if idle.Baseline != nil || idle.Candidate == nil || len(idle.AddedResources) != 1 || idle.AddedResources[0] != "vm-1" {
+		t.Errorf("idle VM rule diff = %+v, want vm-1 added by the candidate only", idle)
+	}
+	underused := byAction["ELB/Review for downsizing/removal"]
+	if underused.Candidate != nil || underused.Baseline == nil || len(underused.RemovedResources) != 1 {
+		t.Errorf("underused ELB rule diff = %+v, want elb-1 removed by the candidate", underused)
+	}
+	if underused.SavingsDeltaUSD >= 0 {
+		t.Errorf("dropping the ELB rule should lower savings, got delta %v", underused.SavingsDeltaUSD)
+	}
+}
@@ -0,0 +1,134 @@
+package savings
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+// LedgerRow aggregates findings by owner, rule and month. The month is when a
+// finding was resolved, or when it was first seen while still open.
+// EstimatedSavings covers every finding; RealizedEstimate only those
+// measured, for comparison with RealizedSavings.
+type LedgerRow struct {
+	Owner            string  `json:"owner"`
+	Rule             string  `json:"rule"`
+	Month            string  `json:"month"`
+	Findings         int     `json:"findings"`
+	Realized         int     `json:"realized"`
+	EstimatedSavings float64 `json:"estimated_savings"`
+	RealizedEstimate float64 `json:"realized_estimate"`
+	RealizedSavings  float64 `json:"realized_savings"`
+}
+
+// Ledger amounts are in Currency; Tracker.Ledger reports in USD.
+type Ledger struct {
+	Currency string      `json:"currency"`
+	Rows     []LedgerRow `json:"rows"`
+	Totals   LedgerRow   `json:"totals"`
+}
+
+// LedgerFilter limits the ledger to a tenant, owner, rule or month
+// ("2025-01"); empty fields match everything.
+type LedgerFilter struct {
+	Tenant string
+	Owner  string
+	Rule   string
+	Month  string
+}
+
+func (f Finding) month() string {
+	if f.ResolvedAt != nil {
+		return f.ResolvedAt.UTC().Format("2006-01")
+	}
+	return f.FirstSeen.UTC().Format("2006-01")
+}
+
+// Ledger aggregates the tracker's findings.
+func (t *Tracker) Ledger(filter LedgerFilter) Ledger {
+	type key struct{ owner, rule, month string }
+	rows := make(map[key]*LedgerRow)
+	ledger := Ledger{Currency: "USD", Rows: []LedgerRow{}}
+	for _, f := range t.Findings() {
+		k := key{f.Owner, f.Rule(), f.month()}
+		if filter.Tenant != "" && tenant.Or(f.Tenant) != filter.Tenant {
+			continue
+		}
+		if (filter.Owner != "" && k.owner != filter.Owner) || (filter.Rule != "" && k.rule != filter.Rule) || (filter.Month != "" && k.month != filter.Month) {
+			continue
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &LedgerRow{Owner: k.owner, Rule: k.rule, Month: k.month}
+			rows[k] = row
+		}
+		for _, r := range []*LedgerRow{row, &ledger.Totals} {
+			r.Findings++
+			r.EstimatedSavings += f.EstimatedSavingsUSD
+			if f.Status == Realized {
+				r.Realized++
+				r.RealizedEstimate += f.EstimatedSavingsUSD
+				r.RealizedSavings += f.RealizedSavingsUSD
+			}
+		}
+	}
+	for _, r := range rows {
+		ledger.Rows = append(ledger.Rows, *r)
+	}
+	sort.Slice(ledger.Rows, func(i, j int) bool {
+		a, b := ledger.Rows[i], ledger.Rows[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Rule < b.Rule
+	})
+	return ledger
+}
+
+// Convert rescales each row by the factor for its month and recomputes the
+// totals, so every month is converted at its own exchange rate.
+func (l *Ledger) Convert(factor func(month string) (float64, error), currency string) error {
+	l.Totals.EstimatedSavings, l.Totals.RealizedEstimate, l.Totals.RealizedSavings = 0, 0, 0
+	for i := range l.Rows {
+		r := &l.Rows[i]
+		f, err := factor(r.Month)
+		if err != nil {
+			return err
+		}
+		r.EstimatedSavings *= f
+		r.RealizedEstimate *= f
+		r.RealizedSavings *= f
+		l.Totals.EstimatedSavings += r.EstimatedSavings
+		l.Totals.RealizedEstimate += r.RealizedEstimate
+		l.Totals.RealizedSavings += r.RealizedSavings
+	}
+	l.Currency = currency
+	return nil
+}
+
+// Watch sweeps the tracker every interval until ctx is cancelled.
+func (t *Tracker) Watch(ctx context.Context, interval time.Duration, now func() time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Sweep(now())
+		}
+	}
+}
+
+var defaultTracker = NewTracker(history.Default(), 0, 0)
+
+// Default is the tracker fed by the live service.
+func Default() *Tracker {
+	return defaultTracker
+}
@@ -0,0 +1,235 @@
+// Package savings follows suggestions from the moment they are raised until
+// they are resolved, then measures what resolving them actually saved by
+// comparing the resource's spend before and after.
+package savings
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
+)
+
+var ErrNotFound = errors.New("finding not found")
+
+// Finding states.
+const (
+	Open      = "open"
+	Measuring = "measuring"
+	Realized  = "realized"
+)
+
+// How a finding was resolved.
+const (
+	ConditionCleared = "condition_cleared"
+	MarkedDone       = "marked_done"
+)
+
+// Finding is one check firing for one resource, from the first suggestion
+// until it is resolved. Savings figures are monthly, like EstimatedSavingsUSD.
+type Finding struct {
+	ID                  string     `json:"id"`
+	Tenant              string     `json:"tenant,omitempty"`
+	ResourceID          string     `json:"resource_id"`
+	ResourceType        string     `json:"resource_type"`
+	Action              string     `json:"action"`
+	Owner               string     `json:"owner"`
+	EstimatedSavingsUSD float64    `json:"estimated_savings_usd"`
+	FirstSeen           time.Time  `json:"first_seen"`
+	LastSeen            time.Time  `json:"last_seen"`
+	Status              string     `json:"status"`
+	Resolution          string     `json:"resolution,omitempty"`
+	ResolvedAt          *time.Time `json:"resolved_at,omitempty"`
+	MeasuredAt          *time.Time `json:"measured_at,omitempty"`
+	BeforeHourlyUSD     float64    `json:"before_hourly_usd,omitempty"`
+	AfterHourlyUSD      float64    `json:"after_hourly_usd,omitempty"`
+	RealizedSavingsUSD  float64    `json:"realized_savings_usd,omitempty"`
+}
+
+// Rule names the check that raised the finding.
+func (f Finding) Rule() string {
+	return f.ResourceType + ": " + f.Action
+}
+
+// Tracker turns suggestions with an estimated saving into findings. A finding
+// is resolved when its check has not fired for Grace or when it is marked
+// done, and measured once Window has passed since: realized savings are the
+// drop in average hourly spend between the Window before and the Window after
+// resolution, expressed per month.
+type Tracker struct {
+	History *history.Store
+	Window  time.Duration
+	Grace   time.Duration
+
+	mu       sync.Mutex
+	findings []*Finding
+	open     map[string]*Finding
+	nextID   int
+}
+
+// Defaults for NewTracker.
+const (
+	DefaultWindow = 24 * time.Hour
+	DefaultGrace  = time.Hour
+)
+
+func NewTracker(hist *history.Store, window, grace time.Duration) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if grace <= 0 {
+		grace = DefaultGrace
+	}
+	return &Tracker{History: hist, Window: window, Grace: grace, open: make(map[string]*Finding)}
+}
+
+func openKey(s analyzer.Suggestion) string {
+	return tenant.Or(s.Tenant) + "|" + s.ResourceID + "|" + s.ResourceType + "|" + s.Action
+}
+
+// Observe records a suggestion. Suggestions without an estimated saving are
+// ignored.
+func (t *Tracker) Observe(s analyzer.Suggestion) {
+	if s.EstimatedSavingsUSD <= 0 {
+		return
+	}
+	if s.Timestamp.IsZero() {
+		s.Timestamp = sim.Now()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := openKey(s)
+	f, ok := t.open[key]
+	if !ok {
+		t.nextID++
+		f = &Finding{
+			ID:           "finding-" + strconv.Itoa(t.nextID),
+			Tenant:       tenant.Or(s.Tenant),
+			ResourceID:   s.ResourceID,
+			ResourceType: s.ResourceType,
+			Action:       s.Action,
+			FirstSeen:    s.Timestamp,
+			Status:       Open,
+		}
+		t.findings = append(t.findings, f)
+		t.open[key] = f
+	}
+	if owner, ok := s.Details["owner"].(string); ok {
+		f.Owner = owner
+	}
+	f.EstimatedSavingsUSD = s.EstimatedSavingsUSD
+	if s.Timestamp.After(f.LastSeen) {
+		f.LastSeen = s.Timestamp
+	}
+}
+
+// MarkDone resolves an open finding at now.
+func (t *Tracker) MarkDone(id string, now time.Time) (Finding, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range t.findings {
+		if f.ID != id {
+			continue
+		}
+		if f.Status != Open {
+			return *f, errors.New("finding " + id + " is already " + f.Status)
+		}
+		t.resolve(f, MarkedDone, now)
+		return *f, nil
+	}
+	return Finding{}, ErrNotFound
+}
+
+// Sweep resolves findings whose check stopped firing and measures those whose
+// after-window has passed.
+func (t *Tracker) Sweep(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range t.findings {
+		switch f.Status {
+		case Open:
+			if now.Sub(f.LastSeen) >= t.Grace {
+				// The condition cleared right after it was last seen.
+				t.resolve(f, ConditionCleared, f.LastSeen)
+			}
+		case Measuring:
+			if !now.Before(f.ResolvedAt.Add(t.Window)) {
+				t.measure(f, now)
+			}
+		}
+	}
+}
+
+// resolve starts measuring f. The spend before resolution is read now, while
+// the history still holds the raw samples of the before-window.
+func (t *Tracker) resolve(f *Finding, how string, at time.Time) {
+	f.Status = Measuring
+	f.Resolution = how
+	f.ResolvedAt = &at
+	f.BeforeHourlyUSD = forecast.Actual(t.History, f.ResourceID, at.Add(-t.Window), at) / t.Window.Hours()
+	for key, open := range t.open {
+		if open == f {
+			delete(t.open, key)
+		}
+	}
+}
+
+func (t *Tracker) measure(f *Finding, now time.Time) {
+	at := *f.ResolvedAt
+	f.AfterHourlyUSD = forecast.Actual(t.History, f.ResourceID, at, at.Add(t.Window)) / t.Window.Hours()
+	f.RealizedSavingsUSD = (f.BeforeHourlyUSD - f.AfterHourlyUSD) * pricing.HoursPerMonth
+	f.MeasuredAt = &now
+	f.Status = Realized
+}
+
+// Restore replaces every finding, e.g. with ones saved in a snapshot. Open
+// findings keep collecting their check's suggestions.
+func (t *Tracker) Restore(findings []Finding) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.findings = make([]*Finding, 0, len(findings))
+	t.open = make(map[string]*Finding)
+	t.nextID = 0
+	for i := range findings {
+		f := findings[i]
+		t.findings = append(t.findings, &f)
+		if f.Status == Open {
+			t.open[openKey(analyzer.Suggestion{Tenant: f.Tenant, ResourceID: f.ResourceID, ResourceType: f.ResourceType, Action: f.Action})] = &f
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(f.ID, "finding-")); err == nil && n > t.nextID {
+			t.nextID = n
+		}
+	}
+}
+
+// Findings returns a copy of every finding, oldest first.
+func (t *Tracker) Findings() []Finding {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]Finding, len(t.findings))
+	for i, f := range t.findings {
+		out[i] = *f
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FirstSeen.Before(out[j].FirstSeen) })
+	return out
+}
+
+// TrackingSink passes suggestions on to SuggestionSink and to Tracker.
+type TrackingSink struct {
+	analyzer.SuggestionSink
+	Tracker *Tracker
+}
+
+func (s *TrackingSink) AddSuggestion(sug analyzer.Suggestion) {
+	s.SuggestionSink.AddSuggestion(sug)
+	s.Tracker.Observe(sug)
+}
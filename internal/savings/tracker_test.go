@@ -0,0 +1,97 @@
+package savings
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/forecast"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+)
+
+func TestTrackerMeasuresRealizedSavings(t *testing.T) {
+	hist := history.NewStore(0)
+	tr := NewTracker(hist, 24*time.Hour, time.Hour)
+	sink := &TrackingSink{SuggestionSink: &analyzer.InMemorySuggestionSink{}, Tracker: tr}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// vm-1 costs $1/h and is flagged for 48 hours, then resized to $0.25/h.
+	for h := 0; h < 96; h++ {
+		now := start.Add(time.Duration(h) * time.Hour)
+		rate := 1.0
+		if h >= 48 {
+			rate = 0.25
+		}
+		hist.Record("vm-1", now, map[string]float64{forecast.HourlyCostMetric: rate})
+		if h < 48 {
+			sink.AddSuggestion(analyzer.Suggestion{
+				ResourceID: "vm-1", ResourceType: "VM", Action: "Resize down", Timestamp: now,
+				EstimatedSavingsUSD: 500, Details: map[string]interface{}{"owner": "Engineering"},
+			})
+		}
+		tr.Sweep(now)
+	}
+
+	findings := tr.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.Status != Realized || f.Resolution != ConditionCleared {
+		t.Fatalf("finding not realized: %+v", f)
+	}
+	// Resolved when last seen at hour 47: 23h at $1 and 1h at $0.25 after.
+	wantAfter := (1.0 + 23*0.25) / 24
+	if f.BeforeHourlyUSD != 1 || math.Abs(f.AfterHourlyUSD-wantAfter) > 1e-9 {
+		t.Errorf("before/after = %v/%v, want 1/%v", f.BeforeHourlyUSD, f.AfterHourlyUSD, wantAfter)
+	}
+
+	ledger := tr.Ledger(LedgerFilter{Owner: "Engineering"})
+	if len(ledger.Rows) != 1 || ledger.Rows[0].Rule != "VM: Resize down" || ledger.Rows[0].Month != "2025-01" {
+		t.Fatalf("unexpected ledger rows: %+v", ledger.Rows)
+	}
+	if got, want := ledger.Totals.RealizedSavings, (1-wantAfter)*24*30; math.Abs(got-want) > 1e-9 {
+		t.Errorf("realized = %v, want %v", got, want)
+	}
+
+	if _, err := tr.MarkDone(f.ID, start); err == nil {
+		t.Error("marking a realized finding done should fail")
+	}
+}
+
+func TestBeforeSpendOutlivesRawSamples(t *testing.T) {
+	// 90 minutes of raw samples at one every 30 seconds, and a one-hour window:
+	// the before-window is gone from the raw samples by the time the finding
+	// is measured.
+	hist := history.NewStore(180)
+	tr := NewTracker(hist, time.Hour, 5*time.Minute)
+	sink := &TrackingSink{SuggestionSink: &analyzer.InMemorySuggestionSink{}, Tracker: tr}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// vm-1 costs $2/h for half an hour and $1/h while flagged from 0:30 to
+	// 1:30, then is resized to $0.25/h.
+	for i := 0; i <= 3*120; i++ {
+		now := start.Add(time.Duration(i) * 30 * time.Second)
+		elapsed := now.Sub(start)
+		rate := 0.25
+		switch {
+		case elapsed < 30*time.Minute:
+			rate = 2
+		case elapsed <= 90*time.Minute:
+			rate = 1
+			sink.AddSuggestion(analyzer.Suggestion{ResourceID: "vm-1", ResourceType: "VM", Action: "Resize down", Timestamp: now, EstimatedSavingsUSD: 500})
+		}
+		hist.Record("vm-1", now, map[string]float64{forecast.HourlyCostMetric: rate})
+		tr.Sweep(now)
+	}
+
+	f := tr.Findings()[0]
+	if f.Status != Realized {
+		t.Fatalf("finding not realized: %+v", f)
+	}
+	wantAfter := (30*1.0 + 3570*0.25) / 3600
+	if math.Abs(f.BeforeHourlyUSD-1) > 1e-9 || math.Abs(f.AfterHourlyUSD-wantAfter) > 1e-9 {
+		t.Errorf("before/after = %v/%v, want 1/%v", f.BeforeHourlyUSD, f.AfterHourlyUSD, wantAfter)
+	}
+}
@@ -0,0 +1,213 @@
+// Package snapshot persists the service's in-memory state — resources, usage
+// history, suggestions, budgets, fired budget alerts and savings findings — so
+// that a restarted process can pick up where the previous one stopped.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/budget"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+)
+
+// Version is the snapshot format written by Save. Load rejects snapshots
+// from a newer version. Version 2 snapshots are gzip-compressed and hold the
+// usage history as hourly averages plus the newest raw samples; Load still
+// reads version 1 JSON.
+const Version = 2
+
+// DefaultRecentSamples is how many of the newest raw samples of each series a
+// snapshot keeps when Manager.RecentSamples is 0: an hour at the live loop's
+// one sample per second, enough for rightsizing and spot scoring to resume.
+const DefaultRecentSamples = 3600
+
+// State is everything a snapshot holds. Clock is the (possibly virtual) time
+// the state was captured at, which a seeded simulation resumes from.
+// Suggestions is empty unless the service keeps them in memory.
+type State struct {
+	Version   int                                    `json:"version"`
+	TakenAt   time.Time                              `json:"taken_at"`
+	Clock     time.Time                              `json:"clock"`
+	Resources models.Resources                       `json:"resources"`
+	History   map[string]map[string][]history.Sample `json:"history,omitempty"`
+	// RecentHistory holds the newest raw samples of each series.
+	RecentHistory map[string]map[string][]history.Sample `json:"recent_history,omitempty"`
+	Suggestions   []analyzer.Suggestion                  `json:"suggestions,omitempty"`
+	Budgets       []budget.Budget                        `json:"budgets,omitempty"`
+	// BudgetAlerts are the budget thresholds that already fired.
+	BudgetAlerts []string          `json:"budget_alerts,omitempty"`
+	Findings     []savings.Finding `json:"findings,omitempty"`
+}
+
+// Info summarizes a saved snapshot.
+type Info struct {
+	TakenAt     time.Time `json:"taken_at"`
+	Clock       time.Time `json:"clock"`
+	Resources   int       `json:"resources"`
+	Series      int       `json:"series"`
+	Suggestions int       `json:"suggestions"`
+	Budgets     int       `json:"budgets"`
+	Findings    int       `json:"findings"`
+	Bytes       int       `json:"bytes"`
+}
+
+// SuggestionStore is a suggestion sink whose contents can be replaced, such as
+// an analyzer.InMemorySuggestionSink or analyzer.TenantSinks of them.
+type SuggestionStore interface {
+	GetSuggestions() []analyzer.Suggestion
+	SetSuggestions([]analyzer.Suggestion)
+}
+
+// Manager captures state from, and restores it into, the stores it is given.
+// Nil stores are skipped.
+type Manager struct {
+	Store     Store
+	Resources func() []models.CloudResource
+	History   *history.Store
+	// RecentSamples bounds the raw samples kept per series; 0 means
+	// DefaultRecentSamples.
+	RecentSamples int
+	Suggestions   SuggestionStore
+	Budgets       *budget.Store
+	Monitor       *budget.Monitor
+	Findings      *savings.Tracker
+}
+
+// Capture collects the current state as of clock.
+func (m *Manager) Capture(clock time.Time) State {
+	st := State{Version: Version, TakenAt: time.Now().UTC(), Clock: clock}
+	if m.Resources != nil {
+		st.Resources = m.Resources()
+	}
+	if m.History != nil {
+		st.History = m.History.Export()
+		recent := m.RecentSamples
+		if recent <= 0 {
+			recent = DefaultRecentSamples
+		}
+		st.RecentHistory = m.History.ExportRecent(recent)
+	}
+	if m.Suggestions != nil {
+		st.Suggestions = m.Suggestions.GetSuggestions()
+	}
+	if m.Budgets != nil {
+		st.Budgets = m.Budgets.List()
+	}
+	if m.Monitor != nil {
+		st.BudgetAlerts = m.Monitor.Fired()
+	}
+	if m.Findings != nil {
+		st.Findings = m.Findings.Findings()
+	}
+	return st
+}
+
+// Save captures the current state and writes it to the store.
+func (m *Manager) Save(ctx context.Context, clock time.Time) (Info, error) {
+	st := m.Capture(clock)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(st); err != nil {
+		return Info{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Info{}, err
+	}
+	if err := m.Store.Save(ctx, buf.Bytes()); err != nil {
+		return Info{}, err
+	}
+	info := st.Info()
+	info.Bytes = buf.Len()
+	return info, nil
+}
+
+// gzipMagic starts every gzip stream; version 1 snapshots are plain JSON.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Load reads the latest snapshot from the store. It returns ErrNoSnapshot
+// when none has been saved yet.
+func (m *Manager) Load(ctx context.Context) (State, error) {
+	b, err := m.Store.Load(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	var r io.Reader = bytes.NewReader(b)
+	if bytes.HasPrefix(b, gzipMagic) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return State{}, fmt.Errorf("decode snapshot: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	var st State
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return State{}, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if st.Version > Version {
+		return State{}, fmt.Errorf("snapshot version %d is newer than supported version %d", st.Version, Version)
+	}
+	return st, nil
+}
+
+// Apply restores st into the manager's stores. Resources are left to the
+// caller, which owns the slice the simulation mutates.
+func (m *Manager) Apply(st State) {
+	if m.History != nil {
+		m.History.Import(st.History, st.RecentHistory)
+	}
+	if m.Suggestions != nil {
+		m.Suggestions.SetSuggestions(st.Suggestions)
+	}
+	if m.Budgets != nil {
+		m.Budgets.Restore(st.Budgets)
+	}
+	if m.Monitor != nil {
+		m.Monitor.RestoreFired(st.BudgetAlerts)
+	}
+	if m.Findings != nil {
+		m.Findings.Restore(st.Findings)
+	}
+}
+
+// Watch saves a snapshot every interval until ctx is cancelled. Failures are
+// passed to onError, which may be nil.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration, clock func() time.Time, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Save(ctx, clock()); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Info summarizes st.
+func (st State) Info() Info {
+	info := Info{
+		TakenAt:     st.TakenAt,
+		Clock:       st.Clock,
+		Resources:   len(st.Resources),
+		Suggestions: len(st.Suggestions),
+		Budgets:     len(st.Budgets),
+		Findings:    len(st.Findings),
+	}
+	for _, byMetric := range st.History {
+		info.Series += len(byMetric)
+	}
+	return info
+}
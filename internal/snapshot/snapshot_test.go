@@ -0,0 +1,150 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/budget"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+)
+
+func newManager(store Store, resources []models.CloudResource) *Manager {
+	hist := history.NewStore(0)
+	return &Manager{
+		Store:       store,
+		Resources:   func() []models.CloudResource { return resources },
+		History:     hist,
+		Suggestions: &analyzer.TenantSinks{New: func(string) analyzer.SuggestionSink { return &analyzer.InMemorySuggestionSink{} }},
+		Budgets:     budget.NewStore(),
+		Monitor:     &budget.Monitor{},
+		Findings:    savings.NewTracker(hist, 0, 0),
+	}
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	store := FileStore{Path: filepath.Join(t.TempDir(), "state", "snapshot.json")}
+	if _, err := newManager(store, nil).Load(ctx); !errors.Is(err, ErrNoSnapshot) {
+		t.Fatalf("Load before Save = %v, want ErrNoSnapshot", err)
+	}
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	vm := &models.VM{ID: "vm-1", Owner: "Engineering", CPUUsage: 12, CostPerHour: 0.5}
+	src := newManager(store, []models.CloudResource{vm})
+	src.History.Record("vm-1", now, vm.Metrics())
+	sink := &savings.TrackingSink{SuggestionSink: src.Suggestions.(*analyzer.TenantSinks), Tracker: src.Findings}
+	sink.AddSuggestion(analyzer.Suggestion{ResourceID: "vm-1", ResourceType: "VM", Action: "Resize down", Timestamp: now, EstimatedSavingsUSD: 100, Tenant: "analytics"})
+	if _, err := src.Budgets.Create(budget.Budget{Owner: "Engineering", MonthlyLimitUSD: 1000}, now); err != nil {
+		t.Fatal(err)
+	}
+	src.Monitor.RestoreFired([]string{"budget-1|2025-03-01T12:00:00Z|2025-03|actual|50"})
+
+	info, err := src.Save(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Resources != 1 || info.Suggestions != 1 || info.Budgets != 1 || info.Findings != 1 || info.Series == 0 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	dst := newManager(store, nil)
+	st, err := dst.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst.Apply(st)
+
+	if !st.Clock.Equal(now) {
+		t.Errorf("clock = %v, want %v", st.Clock, now)
+	}
+	if len(st.Resources) != 1 || st.Resources[0].GetId() != "vm-1" || st.Resources[0].GetUsage() != 12 {
+		t.Errorf("resources not restored: %+v", st.Resources)
+	}
+	if got := dst.History.Values("vm-1", models.MetricCPU); len(got) != 1 || got[0] != 12 {
+		t.Errorf("history = %v, want [12]", got)
+	}
+	if got := dst.Monitor.Fired(); len(got) != 1 || got[0] != "budget-1|2025-03-01T12:00:00Z|2025-03|actual|50" {
+		t.Errorf("fired budget alerts = %v", got)
+	}
+	if got := dst.Suggestions.(*analyzer.TenantSinks).For("analytics").GetSuggestions(); len(got) != 1 || got[0].Action != "Resize down" {
+		t.Errorf("analytics suggestions = %+v", got)
+	}
+
+	// Restored IDs carry on from where the snapshot left off, and open
+	// findings keep collecting their check's suggestions.
+	b, err := dst.Budgets.Create(budget.Budget{MonthlyLimitUSD: 50}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "budget-2" {
+		t.Errorf("new budget id = %s, want budget-2", b.ID)
+	}
+	dst.Findings.Observe(analyzer.Suggestion{ResourceID: "vm-1", ResourceType: "VM", Action: "Resize down", Timestamp: now.Add(time.Minute), EstimatedSavingsUSD: 100, Tenant: "analytics"})
+	dst.Findings.Observe(analyzer.Suggestion{ResourceID: "vm-2", ResourceType: "VM", Action: "Resize down", Timestamp: now, EstimatedSavingsUSD: 100, Tenant: "analytics"})
+	findings := dst.Findings.Findings()
+	if len(findings) != 2 || findings[1].ID != "finding-2" || !findings[0].LastSeen.Equal(now.Add(time.Minute)) {
+		t.Errorf("findings after restore: %+v", findings)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	if err := store.Save(context.Background(), []byte(`{"version": 99}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newManager(store, nil).Load(context.Background()); err == nil {
+		t.Error("expected an error for a snapshot from a newer version")
+	}
+}
+
+func TestSnapshotSizeIsBounded(t *testing.T) {
+	ctx := context.Background()
+	store := FileStore{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	vm := &models.VM{ID: "vm-1", Owner: "Engineering", CostPerHour: 0.5}
+	m := newManager(store, []models.CloudResource{vm})
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	// A day at the live loop's one sample per second.
+	for i := 0; i < 24*3600; i++ {
+		vm.CPUUsage = float64(i % 100)
+		m.History.Record(vm.ID, start.Add(time.Duration(i)*time.Second), vm.Metrics())
+	}
+
+	info, err := m.Save(ctx, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The raw samples alone would take megabytes.
+	if info.Bytes > 64<<10 {
+		t.Errorf("snapshot is %d bytes, want at most 64 KiB", info.Bytes)
+	}
+	st, err := newManager(store, nil).Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.History["vm-1"][models.MetricCPU]; len(got) != 24 || got[0].Value != 49.5 {
+		t.Errorf("restored %d hourly samples starting %+v, want 24 averaging 49.5", len(got), got[0])
+	}
+	if got := len(st.RecentHistory["vm-1"][models.MetricCPU]); got != DefaultRecentSamples {
+		t.Errorf("restored %d raw samples, want %d", got, DefaultRecentSamples)
+	}
+}
+
+func TestLoadReadsUncompressedSnapshots(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	if err := store.Save(context.Background(), []byte(`{"version": 1, "budgets": [{"id": "budget-1"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	st, err := newManager(store, nil).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Budgets) != 1 || st.Budgets[0].ID != "budget-1" {
+		t.Errorf("budgets = %+v", st.Budgets)
+	}
+}
@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var ErrNoSnapshot = errors.New("no snapshot saved")
+
+// Store holds the latest encoded snapshot.
+type Store interface {
+	Save(ctx context.Context, data []byte) error
+	// Load returns ErrNoSnapshot when nothing has been saved.
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileStore keeps the snapshot in a file. Saves write a temporary file next to
+// it and rename it into place, so a crash mid-save leaves the previous
+// snapshot intact.
+type FileStore struct {
+	Path string
+}
+
+func (s FileStore) Save(ctx context.Context, data []byte) error {
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+func (s FileStore) Load(ctx context.Context) ([]byte, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	return b, err
+}
+
+// RedisStore keeps the snapshot in a single Redis string key.
+type RedisStore struct {
+	Client *redis.Client
+	Key    string
+}
+
+func (s RedisStore) Save(ctx context.Context, data []byte) error {
+	return s.Client.Set(ctx, s.Key, data, 0).Err()
+}
+
+func (s RedisStore) Load(ctx context.Context) ([]byte, error) {
+	b, err := s.Client.Get(ctx, s.Key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNoSnapshot
+	}
+	return b, err
+}
@@ -0,0 +1,109 @@
+// Package spot reads a local dataset of spot interruption frequencies and
+// typical savings per instance type and region, in the shape published by the
+// AWS Spot Instance Advisor.
+package spot
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Interruption frequency ranges and the monthly interruption rate each is
+// treated as (its upper bound; ">20%" is taken as 30%).
+var interruptionRates = map[string]float64{
+	"<5%":    0.05,
+	"5-10%":  0.10,
+	"10-15%": 0.15,
+	"15-20%": 0.20,
+	">20%":   0.30,
+}
+
+// Entry is the spot outlook for one instance type in one region.
+type Entry struct {
+	Region         string  `yaml:"region" json:"region"`
+	Type           string  `yaml:"type" json:"type"`
+	Interruption   string  `yaml:"interruption" json:"interruption"`
+	SavingsPercent float64 `yaml:"savings_percent" json:"savings_percent"`
+}
+
+// InterruptionRate is the estimated share of instances interrupted per month.
+func (e Entry) InterruptionRate() float64 {
+	return interruptionRates[e.Interruption]
+}
+
+type Dataset struct {
+	Instances []Entry `yaml:"instances" json:"instances"`
+
+	index map[string]int
+}
+
+//go:embed default_dataset.yaml
+var defaultDataset []byte
+
+// Default returns the sample dataset bundled with the binary.
+func Default() *Dataset {
+	d, err := Parse(defaultDataset)
+	if err != nil {
+		panic("spot: invalid bundled dataset: " + err.Error())
+	}
+	return d
+}
+
+// Load reads a dataset file. JSON files are accepted as well as YAML.
+func Load(path string) (*Dataset, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("parse spot dataset %s: %w", path, err)
+	}
+	return d, nil
+}
+
+func Parse(b []byte) (*Dataset, error) {
+	var d Dataset
+	if err := yaml.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+	d.index = make(map[string]int, len(d.Instances))
+	for i, e := range d.Instances {
+		if _, ok := interruptionRates[e.Interruption]; !ok {
+			return nil, fmt.Errorf("entry %d (%s %s): unknown interruption range %q", i+1, e.Region, e.Type, e.Interruption)
+		}
+		d.index[e.Region+"|"+e.Type] = i
+	}
+	return &d, nil
+}
+
+// Lookup returns the entry for an instance type in region.
+func (d *Dataset) Lookup(region, typ string) (Entry, bool) {
+	i, ok := d.index[region+"|"+typ]
+	if !ok {
+		return Entry{}, false
+	}
+	return d.Instances[i], true
+}
+
+var (
+	datasetMu     sync.RWMutex
+	activeDataset = Default()
+)
+
+// SetDataset replaces the dataset used by the spot suitability check.
+func SetDataset(d *Dataset) {
+	datasetMu.Lock()
+	defer datasetMu.Unlock()
+	activeDataset = d
+}
+
+func CurrentDataset() *Dataset {
+	datasetMu.RLock()
+	defer datasetMu.RUnlock()
+	return activeDataset
+}
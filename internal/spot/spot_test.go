@@ -0,0 +1,30 @@
+package spot
+
+import "testing"
+
+func TestParseAndLookup(t *testing.T) {
+	d, err := Parse([]byte(`
+instances:
+  - {region: us-east-1, type: m5.large, interruption: "5-10%", savings_percent: 62}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := d.Lookup("us-east-1", "m5.large")
+	if !ok {
+		t.Fatal("m5.large not found")
+	}
+	if e.InterruptionRate() != 0.10 || e.SavingsPercent != 62 {
+		t.Errorf("got %+v (rate %v)", e, e.InterruptionRate())
+	}
+	if _, ok := d.Lookup("eu-west-1", "m5.large"); ok {
+		t.Error("found m5.large in a region the dataset does not list")
+	}
+
+	if _, err := Parse([]byte(`instances: [{region: us-east-1, type: m5.large, interruption: "often"}]`)); err == nil {
+		t.Error("accepted an unknown interruption range")
+	}
+	if _, ok := Default().Lookup("us-east-1", "t3.medium"); !ok {
+		t.Error("bundled dataset has no t3.medium in us-east-1")
+	}
+}
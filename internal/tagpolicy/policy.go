@@ -0,0 +1,172 @@
+// Package tagpolicy checks resources against tag-compliance rules, e.g. that
+// every resource carries a cost-center and an environment tag with a valid
+// value.
+package tagpolicy
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule requires Tag on every resource of ResourceTypes (all types when empty).
+// When Allowed is set the value must be one of them; when Pattern is set it
+// must match the regular expression. Optional rules only check the value of
+// a tag that is present.
+type Rule struct {
+	Tag           string   `yaml:"tag" json:"tag"`
+	ResourceTypes []string `yaml:"resource_types,omitempty" json:"resource_types,omitempty"`
+	Allowed       []string `yaml:"allowed,omitempty" json:"allowed,omitempty"`
+	Pattern       string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Optional      bool     `yaml:"optional,omitempty" json:"optional,omitempty"`
+
+	re *regexp.Regexp
+}
+
+type Policy struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Violation is one tag that is missing or has a value the policy rejects.
+type Violation struct {
+	Tag    string `json:"tag"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Reasons for a violation.
+const (
+	Missing = "missing"
+	Invalid = "invalid"
+)
+
+//go:embed default_policy.yaml
+var defaultPolicy []byte
+
+// Default returns the policy bundled with the binary.
+func Default() Policy {
+	p, err := Parse(defaultPolicy)
+	if err != nil {
+		panic("tagpolicy: invalid bundled policy: " + err.Error())
+	}
+	return p
+}
+
+// Load reads a YAML (or JSON) policy file.
+func Load(path string) (Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	p, err := Parse(b)
+	if err != nil {
+		return Policy{}, fmt.Errorf("parse tag policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func Parse(b []byte) (Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return p, err
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Tag == "" {
+			return p, fmt.Errorf("rule %d: %w", i+1, errors.New("tag must be set"))
+		}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return p, fmt.Errorf("rule %d (%s): %w", i+1, r.Tag, err)
+			}
+			r.re = re
+		}
+	}
+	return p, nil
+}
+
+func (r Rule) appliesTo(resourceType string) bool {
+	if len(r.ResourceTypes) == 0 {
+		return true
+	}
+	for _, t := range r.ResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the violations of res, sorted by tag.
+func (p Policy) Check(res models.CloudResource) []Violation {
+	tags := res.GetTags()
+	var out []Violation
+	for _, r := range p.Rules {
+		if !r.appliesTo(res.GetType()) {
+			continue
+		}
+		v, ok := tags[r.Tag]
+		if !ok || strings.TrimSpace(v) == "" {
+			if !r.Optional {
+				out = append(out, Violation{Tag: r.Tag, Reason: Missing})
+			}
+			continue
+		}
+		if !r.valid(v) {
+			out = append(out, Violation{Tag: r.Tag, Value: v, Reason: Invalid})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out
+}
+
+func (r Rule) valid(v string) bool {
+	if len(r.Allowed) > 0 {
+		found := false
+		for _, a := range r.Allowed {
+			if v == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	re := r.re
+	if re == nil && r.Pattern != "" {
+		// A Rule built in code rather than parsed.
+		var err error
+		if re, err = regexp.Compile(r.Pattern); err != nil {
+			return false
+		}
+	}
+	return re == nil || re.MatchString(v)
+}
+
+var (
+	policyMu     sync.RWMutex
+	activePolicy = Default()
+)
+
+// SetPolicy replaces the policy checked by the analyzer.
+func SetPolicy(p Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	activePolicy = p
+}
+
+func CurrentPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return activePolicy
+}
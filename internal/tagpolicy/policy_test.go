@@ -0,0 +1,49 @@
+package tagpolicy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(`
+rules:
+  - tag: cost-center
+    pattern: "^CC-[0-9]+$"
+  - tag: environment
+    allowed: [production, staging]
+  - tag: data-classification
+    resource_types: [S3]
+  - tag: team
+    optional: true
+    allowed: [web, data]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := &models.VM{ID: "vm-1", Tags: map[string]string{"cost-center": "CC-12", "environment": "production"}}
+	if got := p.Check(vm); len(got) != 0 {
+		t.Errorf("compliant VM: %+v", got)
+	}
+
+	s3 := &models.S3{ID: "s3-1", Tags: map[string]string{"environment": "prod", "team": "ops"}}
+	want := []Violation{
+		{Tag: "cost-center", Reason: Missing},
+		{Tag: "data-classification", Reason: Missing},
+		{Tag: "environment", Value: "prod", Reason: Invalid},
+		{Tag: "team", Value: "ops", Reason: Invalid},
+	}
+	if got := p.Check(s3); !reflect.DeepEqual(got, want) {
+		t.Errorf("S3 violations = %+v, want %+v", got, want)
+	}
+
+	if _, err := Parse([]byte(`rules: [{tag: x, pattern: "("}]`)); err == nil {
+		t.Error("accepted an invalid pattern")
+	}
+	if got := Default().Check(&models.Lambda{ID: "fn"}); len(got) != 2 {
+		t.Errorf("bundled policy on an untagged resource: %+v", got)
+	}
+}
@@ -0,0 +1,141 @@
+// Package tenant assigns the cloud accounts resources belong to to tenants,
+// such as business units, so that one instance can serve many of them while
+// each only sees its own resources, suggestions, budgets and findings.
+package tenant
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Default owns every resource whose account no tenant lists, and is the only
+// tenant when none are configured.
+const Default = "default"
+
+// validID keeps tenant IDs safe to use in storage keys and file paths.
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Config lists the accounts (AWS account IDs, GCP projects or Azure
+// subscriptions) of each tenant.
+type Config struct {
+	Tenants map[string][]string `yaml:"tenants" json:"tenants"`
+
+	accounts map[string]string
+}
+
+// Load reads a YAML (or JSON) tenant file.
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	c, err := Parse(b)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse tenants %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse decodes a tenant file and rejects invalid tenant IDs and accounts
+// listed under more than one tenant.
+func Parse(b []byte) (Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, err
+	}
+	c.accounts = make(map[string]string)
+	for _, id := range c.IDs() {
+		if !validID.MatchString(id) {
+			return c, fmt.Errorf("tenant %q: IDs may only hold lowercase letters, digits, '-' and '_'", id)
+		}
+		for _, account := range c.Tenants[id] {
+			if other, dup := c.accounts[account]; dup {
+				return c, fmt.Errorf("account %q is listed under both %q and %q", account, other, id)
+			}
+			c.accounts[account] = id
+		}
+	}
+	return c, nil
+}
+
+// IDs returns the configured tenants and Default, sorted.
+func (c Config) IDs() []string {
+	ids := []string{Default}
+	for id := range c.Tenants {
+		if id != Default {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Known reports whether id is Default or a configured tenant.
+func (c Config) Known(id string) bool {
+	_, ok := c.Tenants[id]
+	return ok || id == Default
+}
+
+// Of returns the tenant that owns account.
+func (c Config) Of(account string) string {
+	if id, ok := c.accounts[account]; ok {
+		return id
+	}
+	return Default
+}
+
+var (
+	configMu     sync.RWMutex
+	activeConfig = Config{}
+)
+
+// SetConfig replaces the tenants used to scope resources.
+func SetConfig(c Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	activeConfig = c
+}
+
+func CurrentConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return activeConfig
+}
+
+// Of returns the tenant that owns r under the current configuration.
+func Of(r models.CloudResource) string {
+	return CurrentConfig().Of(r.GetAccount())
+}
+
+// Or returns id, or Default when id is empty, e.g. for records written before
+// tenants existed.
+func Or(id string) string {
+	if id == "" {
+		return Default
+	}
+	return id
+}
+
+// Filter returns the resources owned by id.
+func Filter(resources []models.CloudResource, id string) []models.CloudResource {
+	c := CurrentConfig()
+	out := make([]models.CloudResource, 0, len(resources))
+	for _, r := range resources {
+		if c.Of(r.GetAccount()) == id {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Key scopes a storage key such as "suggestions" to tenant id, giving
+// "tenant:<id>:suggestions".
+func Key(id, key string) string {
+	return "tenant:" + id + ":" + key
+}
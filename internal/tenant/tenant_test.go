@@ -0,0 +1,61 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestConfig(t *testing.T) {
+	c, err := Parse([]byte(`
+tenants:
+  analytics: [analytics-prod, "444455556666"]
+  web: [sub-web-prod]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.IDs(); len(got) != 3 || got[0] != "analytics" || got[1] != Default || got[2] != "web" {
+		t.Errorf("IDs = %v", got)
+	}
+	if !c.Known("web") || !c.Known(Default) || c.Known("payments") {
+		t.Error("Known disagrees with the configured tenants")
+	}
+	if got := c.Of("444455556666"); got != "analytics" {
+		t.Errorf("Of(444455556666) = %q, want analytics", got)
+	}
+	if got := c.Of("111122223333"); got != Default {
+		t.Errorf("unlisted account belongs to %q, want %q", got, Default)
+	}
+
+	if _, err := Parse([]byte(`tenants: {a: ["1"], b: ["1"]}`)); err == nil {
+		t.Error("accepted an account listed under two tenants")
+	}
+	if _, err := Parse([]byte(`tenants: {"../etc": ["1"]}`)); err == nil {
+		t.Error("accepted a tenant ID unsafe for keys and paths")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	c, err := Parse([]byte(`tenants: {analytics: [analytics-prod]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetConfig(c)
+	defer SetConfig(Config{})
+
+	res := []models.CloudResource{
+		&models.VM{ID: "vm-1", Account: "111122223333"},
+		&models.GCEInstance{ID: "gce-1", Project: "analytics-prod"},
+		&models.S3{ID: "s3-1"},
+	}
+	if got := Filter(res, "analytics"); len(got) != 1 || got[0].GetId() != "gce-1" {
+		t.Errorf("analytics resources = %v", got)
+	}
+	if got := Filter(res, Default); len(got) != 2 {
+		t.Errorf("default resources = %v", got)
+	}
+	if got := Key("analytics", "suggestions"); got != "tenant:analytics:suggestions" {
+		t.Errorf("Key = %q", got)
+	}
+}
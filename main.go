@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,34 +12,335 @@ import (
 	"time"
 	"github.com/chanducheryala/cloud-resource/api"
 	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/anomaly"
+	"github.com/chanducheryala/cloud-resource/internal/auth"
+	"github.com/chanducheryala/cloud-resource/internal/budget"
+	"github.com/chanducheryala/cloud-resource/internal/chargeback"
+	"github.com/chanducheryala/cloud-resource/internal/commitment"
+	"github.com/chanducheryala/cloud-resource/internal/currency"
+	"github.com/chanducheryala/cloud-resource/internal/history"
 	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/pricing"
+	"github.com/chanducheryala/cloud-resource/internal/replay"
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/chanducheryala/cloud-resource/internal/snapshot"
+	"github.com/chanducheryala/cloud-resource/internal/spot"
+	"github.com/chanducheryala/cloud-resource/internal/tagpolicy"
+	"github.com/chanducheryala/cloud-resource/internal/tenant"
 	"github.com/chanducheryala/cloud-resource/utils"
+	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
 
 func main() {
+	scenarioPath := flag.String("scenario", "", "YAML scenario file that drives the simulation")
+	headless := flag.Bool("headless", false, "run the scenario without the API server and exit non-zero if its expectations fail")
+	simulateDays := flag.Int("simulate-days", 0, "simulate this many days of virtual time as fast as possible, print a report and exit")
+	recordPath := flag.String("record", "", "append every resource snapshot from the simulation to this JSONL file")
+	replayPath := flag.String("replay", "", "replay a JSONL recording through the analyzer, print a report and exit")
+	replaySpeed := flag.Float64("replay-speed", 0, "replay pace: 0 = instant, 1 = recorded speed, N = N times faster")
+	rulesPath := flag.String("rules", "", "YAML file overriding the analyzer thresholds")
+	backtestPath := flag.String("backtest", "", "compare -rules against the built-in thresholds over a JSONL recording, print a report and exit")
+	pricingPath := flag.String("pricing", "", "price catalog file used to estimate savings (defaults to the bundled catalog)")
+	savingsWindow := flag.Duration("savings-window", savings.DefaultWindow, "spend window compared before and after a suggestion is resolved to measure realized savings")
+	offersPath := flag.String("commitment-prices", "", "YAML table of reserved instance and savings plan discounts (defaults to the bundled table)")
+	ratesPath := flag.String("exchange-rates", "", "YAML exchange-rate table used for ?currency= conversions (defaults to bundled sample rates)")
+	spotPath := flag.String("spot-dataset", "", "YAML spot interruption and savings dataset used to score spot suitability (defaults to bundled sample data)")
+	anomalyPath := flag.String("anomaly-config", "", "YAML file with cost anomaly detection sensitivity, optionally per owner")
+	tagPolicyPath := flag.String("tag-policy", "", "YAML tag-compliance rules checked on every resource (defaults to requiring cost-center and environment)")
+	inventoryPath := flag.String("inventory", "", "JSON resource inventory to simulate instead of the built-in mock resources (the format of GET /api/v1/resources)")
+	snapshotPath := flag.String("snapshot", "", "file to restore service state from at startup and save it to periodically")
+	snapshotRedisKey := flag.String("snapshot-redis-key", "", "Redis key to restore service state from at startup and save it to periodically (instead of -snapshot)")
+	snapshotInterval := flag.Duration("snapshot-interval", 5*time.Minute, "how often to save a snapshot when -snapshot or -snapshot-redis-key is set")
+	splitsPath := flag.String("splits", "", "YAML file with chargeback split rules for shared resources")
+	tenantsPath := flag.String("tenants", "", "YAML file assigning AWS accounts, GCP projects and Azure subscriptions to tenants (defaults to a single tenant)")
+	authPath := flag.String("auth", "", "YAML file with hashed API keys and/or a JWKS for JWT bearer tokens; without it the API is unauthenticated")
+	newAPIKey := flag.Bool("new-api-key", false, "print a new random API key and the hash to put in the -auth file, and exit")
+	importPrices := flag.String("import-aws-prices", "", "merge an AWS Price List bulk offer file (EC2, RDS or S3) into the -pricing catalog and exit")
+	flag.Parse()
+
+	if *newAPIKey {
+		key, err := auth.NewKey()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("key:    %s\nsha256: %s\n", key, auth.HashKey(key))
+		os.Exit(0)
+	}
+	if *importPrices != "" {
+		os.Exit(runImportPrices(*importPrices, *pricingPath))
+	}
+	if *pricingPath != "" {
+		catalog, err := pricing.Load(*pricingPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		analyzer.SetCatalog(catalog)
+	}
+
+	if *rulesPath != "" {
+		rules, err := analyzer.LoadRules(*rulesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		analyzer.SetRules(rules)
+	}
+
+	if *offersPath != "" {
+		offers, err := commitment.LoadOffers(*offersPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		commitment.SetOffers(offers)
+	}
+
+	if *ratesPath != "" {
+		table, err := currency.Load(*ratesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		currency.SetTable(table)
+	}
+
+	if *spotPath != "" {
+		dataset, err := spot.Load(*spotPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		spot.SetDataset(dataset)
+	}
+
+	if *anomalyPath != "" {
+		cfg, err := anomaly.LoadConfig(*anomalyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		anomaly.SetConfig(cfg)
+	}
+
+	if *tagPolicyPath != "" {
+		policy, err := tagpolicy.Load(*tagPolicyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		tagpolicy.SetPolicy(policy)
+	}
+
+	if *splitsPath != "" {
+		splits, err := chargeback.LoadSplits(*splitsPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		chargeback.SetSplits(splits)
+	}
+
+	if *tenantsPath != "" {
+		cfg, err := tenant.Load(*tenantsPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		tenant.SetConfig(cfg)
+	}
+
+	if *backtestPath != "" {
+		os.Exit(runBacktest(*backtestPath))
+	}
+	if *replayPath != "" {
+		os.Exit(runReplay(*replayPath, *replaySpeed))
+	}
+
+	if *simulateDays > 0 {
+		os.Exit(runBatch(*simulateDays, *scenarioPath, *recordPath))
+	}
+	if *headless {
+		os.Exit(runHeadlessScenario(*scenarioPath))
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	resources := utils.GenerateMockResources()
+	logger := api.GetLogger()
 
-	out := make(chan models.CloudResource)
+	if *authPath != "" {
+		cfg, err := auth.Load(*authPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		var redisClient *redis.Client
+		if cfg.RedisAPIKeys != "" {
+			redisClient = api.GetRedisClient()
+		}
+		authenticator, err := auth.New(cfg, redisClient)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		api.SetAuthenticator(authenticator)
+	} else {
+		logger.Warn("API authentication is disabled; pass -auth to require API keys or JWTs")
+	}
 
-	logger := api.GetLogger()
+	var snapshots *snapshot.Manager
+	var restored *snapshot.State
+	if *snapshotPath != "" || *snapshotRedisKey != "" {
+		var store snapshot.Store = snapshot.FileStore{Path: *snapshotPath}
+		if *snapshotRedisKey != "" {
+			store = snapshot.RedisStore{Client: api.GetRedisClient(), Key: *snapshotRedisKey}
+		}
+		snapshots = &snapshot.Manager{Store: store, History: history.Default(), Budgets: budget.Default(), Findings: savings.Default()}
+		st, err := snapshots.Load(ctx)
+		switch {
+		case errors.Is(err, snapshot.ErrNoSnapshot):
+			logger.Info("No snapshot to restore")
+		case err != nil:
+			logger.Fatal("Failed to load snapshot", zap.Error(err))
+		default:
+			restored = &st
+		}
+	}
 
-	redisClient := api.GetRedisClient() 
-	redisSink := analyzer.NewRedisSuggestionSink(redisClient, "suggestions")
-	
+	// The inventory is loaded before the scenario so that the scenario can be
+	// checked against it; nil means the mock resources.
+	var inventory []models.CloudResource
+	if *inventoryPath != "" {
+		inv, err := utils.LoadInventory(*inventoryPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		inventory = inv
+	} else if restored != nil && len(restored.Resources) > 0 {
+		inventory = restored.Resources
+	}
+
+	simConfig := utils.LoadSimulationConfig()
+	var clock *sim.VirtualClock
+	var scenario *utils.Scenario
+	if *scenarioPath != "" {
+		sc, err := utils.LoadScenario(*scenarioPath, inventory)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		scenario = sc
+		clock = scenario.Install()
+	} else if simConfig.Seeded {
+		start := simConfig.Start
+		if restored != nil && start.IsZero() {
+			// Resume virtual time where the snapshot left off.
+			start = restored.Clock
+		}
+		clock = sim.UseSeed(simConfig.Seed, start)
+	}
+
+	resources := inventory
+	if resources == nil {
+		resources = utils.GenerateMockResources()
+	}
+
+	out := make(chan models.CloudResource)
 
+	suggestionSink := &analyzer.TenantSinks{}
 	suggestionSinkType := "redis"
+	if os.Getenv("SUGGESTION_SINK") == "memory" {
+		suggestionSink.New = func(string) analyzer.SuggestionSink { return &analyzer.InMemorySuggestionSink{} }
+		if snapshots != nil {
+			snapshots.Suggestions = suggestionSink
+		}
+		suggestionSinkType = "memory"
+	} else {
+		redisClient := api.GetRedisClient() 
+		suggestionSink.New = func(t string) analyzer.SuggestionSink {
+			return analyzer.NewRedisSuggestionSink(redisClient, tenant.Key(t, "suggestions"))
+		}
+	}
+
+	tracker := savings.Default()
+	tracker.Window = *savingsWindow
+	sink := &savings.TrackingSink{SuggestionSink: suggestionSink, Tracker: tracker}
+	budgetMonitor := &budget.Monitor{Budgets: budget.Default(), History: history.Default(), Sink: sink}
+
+	if snapshots != nil {
+		snapshots.Resources = func() []models.CloudResource { return resources }
+		snapshots.Monitor = budgetMonitor
+		if restored != nil {
+			snapshots.Apply(*restored)
+			info := restored.Info()
+			logger.Info("Restored snapshot", zap.Time("taken_at", info.TakenAt), zap.Int("resources", info.Resources), zap.Int("budgets", info.Budgets), zap.Int("findings", info.Findings))
+		}
+		api.SetSnapshotManager(snapshots)
+		go snapshots.Watch(ctx, *snapshotInterval, sim.Now, func(err error) {
+			logger.Error("Failed to save snapshot", zap.Error(err))
+		})
+	}
+
+	server := api.StartAPIServer(ctx, &resources, suggestionSink, suggestionSinkType)
 
-	server := api.StartAPIServer(ctx, &resources, redisSink, suggestionSinkType)
+	anomalyDetector := &anomaly.Detector{History: history.Default(), Sink: sink}
 
-	go utils.StartSimulation(ctx, resources, 1 * time.Second, out, logger, redisSink)
+	// The watchers run every 10 seconds: of virtual time on the simulation's
+	// own goroutine in seeded and scenario runs, so that they stay
+	// reproducible, and of wall-clock time otherwise.
+	const checkEvery = 10 * time.Second
+	checks := []func(now time.Time){
+		tracker.Sweep,
+		func(now time.Time) { budgetMonitor.Check(resources, now) },
+		func(now time.Time) { anomalyDetector.Check(resources, now) },
+	}
+
+	if scenario != nil {
+		simulator, err := scenario.NewSimulator(resources, clock, sink, logger)
+		if err != nil {
+			logger.Fatal("Invalid scenario", zap.Error(err))
+		}
+		simulator.Speed = simConfig.Speed
+		simulator.Checks, simulator.CheckEvery = checks, checkEvery
+		logger.Info("Starting scenario simulation", zap.String("scenario", scenario.Name))
+		go simulator.Run(ctx, out)
+	} else if clock != nil {
+		logger.Info("Starting seeded simulation", zap.Int64("seed", simConfig.Seed), zap.Float64("speed", simConfig.Speed))
+		go utils.StartSeededSimulation(ctx, resources, simConfig, out, logger, sink, clock, checkEvery, checks...)
+	} else {
+		go tracker.Watch(ctx, checkEvery, sim.Now)
+		go budgetMonitor.Watch(ctx, checkEvery, func() []models.CloudResource { return resources }, sim.Now)
+		go anomalyDetector.Watch(ctx, checkEvery, func() []models.CloudResource { return resources }, sim.Now)
+		go utils.StartSimulation(ctx, resources, 1 * time.Second, out, logger, sink)
+	}
+
+	var recorder *replay.Recorder
+	if *recordPath != "" {
+		r, err := replay.NewRecorder(*recordPath)
+		if err != nil {
+			logger.Fatal("Failed to open recording", zap.Error(err))
+		}
+		recorder = r
+		defer recorder.Close()
+		logger.Info("Recording resource snapshots", zap.String("path", *recordPath))
+	}
 
 	go func() {
 		for res := range out {
 			fmt.Println(res)
+			if recorder != nil {
+				if err := recorder.Record(res); err != nil {
+					logger.Error("Failed to record snapshot", zap.Error(err))
+				}
+			}
+			if err := api.SaveSnapshot(ctx, sim.Now(), res); err != nil {
+				logger.Debug("Failed to save snapshot to Redis", zap.String("id", res.GetId()), zap.Error(err))
+			}
 		}
 	}()
 
@@ -49,6 +353,160 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown", zap.Error(err))
 	}
+	if snapshots != nil {
+		if _, err := snapshots.Save(shutdownCtx, sim.Now()); err != nil {
+			logger.Error("Failed to save snapshot", zap.Error(err))
+		}
+	}
 	
 	logger.Info("Server exited")
 }
+
+func runHeadlessScenario(path string) int {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "-headless requires -scenario")
+		return 2
+	}
+	sc, err := utils.LoadScenario(path, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	result, err := utils.RunScenario(sc, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(b))
+	if !result.Passed {
+		return 1
+	}
+	return 0
+}
+
+func runBatch(days int, scenarioPath string, recordPath string) int {
+	simConfig := utils.LoadSimulationConfig()
+	sink := &analyzer.InMemorySuggestionSink{}
+
+	var simulator *utils.Simulator
+	var scenario *utils.Scenario
+	if scenarioPath != "" {
+		sc, err := utils.LoadScenario(scenarioPath, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		scenario = sc
+		clock := scenario.Install()
+		simulator, err = scenario.NewSimulator(utils.GenerateMockResources(), clock, sink, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	} else {
+		tick := simConfig.Tick
+		if tick == 0 {
+			tick = time.Hour
+		}
+		clock := sim.UseSeed(simConfig.Seed, simConfig.Start)
+		simulator = &utils.Simulator{
+			Resources: utils.GenerateMockResources(),
+			Interval:  tick,
+			Clock:     clock,
+			Sink:      sink,
+		}
+	}
+
+	if recordPath != "" {
+		recorder, err := replay.NewRecorder(recordPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer recorder.Close()
+		simulator.AfterAnalyze = func(res models.CloudResource) {
+			if err := recorder.Record(res); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+
+	report := utils.RunBatch(simulator, sink, time.Duration(days)*24*time.Hour)
+	if scenario != nil {
+		report.Failures = scenario.Check(sink.GetSuggestions())
+	}
+	b, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(b))
+	if len(report.Failures) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runReplay(path string, speed float64) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sink := &analyzer.InMemorySuggestionSink{}
+	result, err := replay.Replay(ctx, path, sink, replay.Options{Speed: speed, Rules: analyzer.CurrentRules()})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	suggestions := sink.GetSuggestions()
+	report := map[string]interface{}{
+		"replay":      result,
+		"suggestions": len(suggestions),
+		"rules":       analyzer.SummarizeRules(suggestions, result.Start),
+	}
+	b, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(b))
+	return 0
+}
+
+func runBacktest(path string) int {
+	report, err := replay.Backtest(context.Background(), path, analyzer.CurrentRules())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(b))
+	return 0
+}
+
+func runImportPrices(offerPath, catalogPath string) int {
+	if catalogPath == "" {
+		fmt.Fprintln(os.Stderr, "-import-aws-prices requires -pricing to name the catalog to write")
+		return 2
+	}
+	catalog, err := pricing.Load(catalogPath)
+	if errors.Is(err, os.ErrNotExist) {
+		// Start from the bundled catalog so its spot prices and other services carry over.
+		catalog, err = pricing.Default(), nil
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	f, err := os.Open(offerPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer f.Close()
+	instances, storage, err := pricing.ImportAWSPriceList(f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	catalog.Merge(instances, storage)
+	if err := catalog.Save(catalogPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Printf("imported %d instance prices and %d storage prices into %s\n", len(instances), len(storage), catalogPath)
+	return 0
+}
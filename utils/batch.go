@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+)
+
+// BatchReport summarizes the suggestions raised during a batch run, grouped by
+// resource type and action.
+type BatchReport struct {
+	VirtualStart time.Time              `json:"virtual_start"`
+	VirtualEnd   time.Time              `json:"virtual_end"`
+	Ticks        int                    `json:"ticks"`
+	WallTime     string                 `json:"wall_time"`
+	Suggestions  int                    `json:"suggestions"`
+	Rules        []analyzer.RuleSummary `json:"rules"`
+	Failures     []string               `json:"failures,omitempty"`
+}
+
+// RunBatch simulates d of virtual time as fast as possible and reports on the
+// suggestions collected in sink, which should be the simulator's sink.
+func RunBatch(s *Simulator, sink *analyzer.InMemorySuggestionSink, d time.Duration) *BatchReport {
+	started := time.Now()
+	report := &BatchReport{VirtualStart: s.Clock.Now()}
+	report.Ticks = s.RunFor(d)
+	report.VirtualEnd = s.Clock.Now()
+	report.WallTime = time.Since(started).String()
+
+	suggestions := sink.GetSuggestions()
+	report.Suggestions = len(suggestions)
+	report.Rules = analyzer.SummarizeRules(suggestions, report.VirtualStart)
+	return report
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+// LoadInventory reads a JSON array of resources in the models wire format, as
+// returned by GET /api/v1/resources.
+func LoadInventory(path string) ([]models.CloudResource, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rs models.Resources
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return nil, fmt.Errorf("parse inventory %s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// SaveInventory writes resources as an indented JSON array that LoadInventory
+// reads back.
+func SaveInventory(path string, resources []models.CloudResource) error {
+	b, err := json.MarshalIndent(models.Resources(resources), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -2,13 +2,18 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/chanducheryala/cloud-resource/internal/history"
 	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
 	"go.uber.org/zap"
 	"time"
 )
 
 func StartSimulation(ctx context.Context, resources []models.CloudResource, interval time.Duration, out chan models.CloudResource, logger *zap.Logger, suggestionSink analyzer.SuggestionSink) {
+	deps := graph.Build(resources)
 	for _, resource := range resources {
 		go func(res models.CloudResource) {
 			for {
@@ -17,7 +22,7 @@ func StartSimulation(ctx context.Context, resources []models.CloudResource, inte
 					return
 				default:
 					res.UpdateUsage()
-					analyzer.AnalyzeResource(res.GetUsage(), res, suggestionSink)
+					go analyzer.AnalyzeWith(res, suggestionSink, analyzer.Env{Rules: analyzer.CurrentRules(), Now: sim.Now(), History: history.Default(), Graph: deps})
 					logger.Info("Resource state", zap.String("resource", resourceToString(res)))
 					out <- res
 					time.Sleep(interval)
@@ -28,29 +33,40 @@ func StartSimulation(ctx context.Context, resources []models.CloudResource, inte
 }
 
 func GenerateMockResources() []models.CloudResource {
+	created := sim.Now().AddDate(0, -3, 0).Unix()
 	return []models.CloudResource{
-		&models.VM{ID: "vm-1", CostPerHour: 0.05, Owner: "Finance Team"},
-		&models.VM{ID: "vm-2", CostPerHour: 0.10, Owner: "Engineering"},
-		&models.Storage{ID: "s-1", CostPerGB: 0.02, LastAccessed: time.Now().Unix(), Owner: "Data Science"},
-		&models.Database{ID: "db-1", CostPerHr: 0.20, Owner: "Analytics"},
-		&models.S3{ID: "s3-1", UsedGB: 500, ObjectCount: 100000, CostPerGB: 0.023, Owner: "Backup", LastAccessed: time.Now().Unix()}, 
-		&models.DynamoDB{ID: "ddb-1", ReadCapacity: 10, WriteCapacity: 5, ItemCount: 10000, CostPerHr: 0.10, Owner: "Product", LastUpdated: time.Now().Unix()}, 
-		&models.Lambda{ID: "lambda-1", Invocations: 1000, Errors: 2, CostPerMillion: 0.20, Owner: "Automation", LastModified: time.Now().Unix()}, // Lambda (new struct)
-		&models.ELB{ID: "elb-1", RequestCount: 50000, HealthyHosts: 3, CostPerHour: 0.025, Owner: "WebOps", LastChecked: time.Now().Unix()}, 
+		&models.VM{ID: "vm-1", Account: "111122223333", InstanceType: "t3.medium", VCPU: 2, MemoryGiB: 4, Region: "us-east-1", AvailabilityZone: "us-east-1a", CostPerHour: 0.05, Owner: "Finance Team", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-1001", "environment": "production", "workload": "web"}},
+		&models.VM{ID: "vm-2", Account: "111122223333", InstanceType: "m5.large", VCPU: 2, MemoryGiB: 8, Region: "us-east-1", AvailabilityZone: "us-east-1b", CostPerHour: 0.10, Owner: "Engineering", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-2001", "environment": "production", "workload": "batch"}},
+		&models.Storage{ID: "s-1", Account: "111122223333", Region: "us-east-1", CostPerGB: 0.02, LastAccessed: sim.Now().Unix(), Owner: "Data Science", CreatedAt: created, Tags: map[string]string{"environment": "prod"}},
+		&models.Database{ID: "db-1", Account: "111122223333", InstanceClass: "db.m5.large", Engine: "postgres", VCPU: 2, MemoryGiB: 8, Region: "us-east-1", AvailabilityZone: "us-east-1a", CostPerHr: 0.20, Owner: "Analytics", CreatedAt: created, Tags: map[string]string{"cost-center": "CC-3001", "environment": "production"}},
+		&models.S3{ID: "s3-1", Account: "111122223333", Region: "us-east-1", UsedGB: 500, ObjectCount: 100000, CostPerGB: 0.023, Owner: "Backup", CreatedAt: created, LastAccessed: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-4001", "environment": "production"}}, 
+		&models.DynamoDB{ID: "ddb-1", Account: "111122223333", Region: "us-east-1", ReadCapacity: 10, WriteCapacity: 5, ItemCount: 10000, CostPerHr: 0.10, Owner: "Product", CreatedAt: created, LastUpdated: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-5001", "environment": "staging"}}, 
+		&models.Lambda{ID: "lambda-1", Account: "111122223333", Region: "us-east-1", Invocations: 1000, Errors: 2, CostPerMillion: 0.20, Owner: "Automation", CreatedAt: created, LastModified: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-6001", "environment": "production"}, ReadsFrom: []string{"s3-1"}, WritesTo: []string{"ddb-1"}}, // Lambda (new struct)
+		&models.ELB{ID: "elb-1", Account: "111122223333", Region: "us-east-1", RequestCount: 50000, HealthyHosts: 3, CostPerHour: 0.025, Owner: "WebOps", CreatedAt: created, LastChecked: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-7001", "environment": "production"}, Targets: []string{"vm-1", "vm-2"}}, 
+		&models.EBSVolume{ID: "vol-1", Account: "111122223333", Region: "us-east-1", AvailabilityZone: "us-east-1a", VolumeType: "gp3", SizeGB: 100, CostPerGBMonth: 0.08, AttachedTo: "vm-1", Owner: "Finance Team", CreatedAt: created, LastAttached: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-1001", "environment": "production"}},
+		&models.EBSVolume{ID: "vol-2", Account: "111122223333", Region: "us-east-1", AvailabilityZone: "us-east-1b", VolumeType: "gp2", SizeGB: 500, CostPerGBMonth: 0.10, Owner: "Engineering", CreatedAt: created, LastAttached: sim.Now().AddDate(0, 0, -10).Unix(), Tags: map[string]string{"cost-center": "CC-2001", "environment": "production"}},
+		&models.EBSSnapshot{ID: "snap-1", Account: "111122223333", Region: "us-east-1", VolumeID: "vol-1", SizeGB: 100, CostPerGBMonth: 0.05, Owner: "Finance Team", CreatedAt: created, Tags: map[string]string{"cost-center": "CC-1001", "environment": "production"}},
+		&models.EBSSnapshot{ID: "snap-2", Account: "111122223333", Region: "us-east-1", VolumeID: "vol-0", SizeGB: 250, CostPerGBMonth: 0.05, Owner: "Engineering", CreatedAt: created, Tags: map[string]string{"cost-center": "CC-2001", "environment": "production"}},
+		&models.NATGateway{ID: "nat-1", Account: "111122223333", Region: "us-east-1", AvailabilityZone: "us-east-1a", GBPerHour: 0.3, CostPerHour: 0.045, CostPerGB: 0.045, Owner: "WebOps", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-7001", "environment": "production"}},
+		&models.ElasticIP{ID: "eip-1", Account: "111122223333", Region: "us-east-1", PublicIP: "203.0.113.10", AssociatedWith: "nat-1", CostPerHour: 0.005, Owner: "WebOps", CreatedAt: created, LastAssociated: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-7001", "environment": "production"}},
+		&models.ElasticIP{ID: "eip-2", Account: "111122223333", Region: "us-east-1", PublicIP: "203.0.113.11", CostPerHour: 0.005, Owner: "WebOps", CreatedAt: created, Tags: map[string]string{"cost-center": "CC-7001", "environment": "production"}},
+		&models.EKSCluster{ID: "eks-1", Account: "111122223333", Region: "us-east-1", KubernetesVersion: "1.29", NodeGroups: []string{"ng-1", "ng-2"}, ControlPlaneCostPerHour: 0.10, Owner: "Platform", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-8001", "environment": "production"}},
+		&models.EKSNodeGroup{ID: "ng-1", Account: "111122223333", ClusterID: "eks-1", InstanceType: "m5.xlarge", Region: "us-east-1", NodeCount: 6, MinNodes: 2, MaxNodes: 10, AllocatableCPU: 3.92, AllocatableMemoryGiB: 14.5, RequestedCPU: 8, RequestedMemoryGiB: 24, Pods: 40, MaxPodsPerNode: 58, CostPerNodeHour: 0.192, Owner: "Platform", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-8001", "environment": "production"}},
+		&models.EKSNodeGroup{ID: "ng-2", Account: "111122223333", ClusterID: "eks-1", InstanceType: "m5.large", Region: "us-east-1", NodeCount: 3, MinNodes: 0, MaxNodes: 5, AllocatableCPU: 1.93, AllocatableMemoryGiB: 6.5, MaxPodsPerNode: 29, CostPerNodeHour: 0.096, Owner: "Platform", CreatedAt: created, LastActive: sim.Now().AddDate(0, 0, -2).Unix(), Tags: map[string]string{"cost-center": "CC-8001", "environment": "staging"}},
+		&models.GCEInstance{ID: "gce-1", Project: "analytics-prod", MachineType: "n2-standard-4", VCPU: 4, MemoryGiB: 16, Region: "us-central1", Zone: "us-central1-a", CostPerHour: 0.19, Owner: "Analytics", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-3001", "environment": "production"}},
+		&models.GCSBucket{ID: "gcs-1", Project: "analytics-prod", Region: "us-central1", StorageClass: "standard", UsedGB: 2000, CostPerGBMonth: 0.02, Owner: "Data Science", CreatedAt: created, LastAccessed: sim.Now().AddDate(0, 0, -120).Unix(), Tags: map[string]string{"cost-center": "CC-3002", "environment": "production"}},
+		&models.CloudSQLInstance{ID: "sql-1", Project: "analytics-prod", Tier: "db-custom-4-16384", DatabaseVersion: "POSTGRES_15", VCPU: 4, MemoryGiB: 16, Region: "us-central1", CostPerHour: 0.35, Owner: "Analytics", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-3001", "environment": "production"}},
+		&models.BigQueryDataset{ID: "bq-1", Project: "analytics-prod", Region: "us-central1", StoredGB: 5000, StorageCostPerGBMonth: 0.02, TBScannedPerDay: 6, CostPerTBScanned: 6.25, Owner: "Data Science", CreatedAt: created, LastQueried: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-3002", "environment": "production"}},
+		&models.AzureVM{ID: "azvm-1", Subscription: "sub-web-prod", ResourceGroup: "rg-web-prod", Size: "Standard_D4s_v5", VCPU: 4, MemoryGiB: 16, Region: "eastus", CostPerHour: 0.192, Owner: "WebOps", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-7001", "environment": "production"}},
+		&models.AzureBlobContainer{ID: "blob-1", Subscription: "sub-web-prod", ResourceGroup: "rg-backup", StorageAccount: "stbackupprod", Region: "eastus", AccessTier: "hot", UsedGB: 1500, CostPerGBMonth: 0.0184, RequestsPerDay: 500, Owner: "Backup", CreatedAt: created, LastAccessed: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-4001", "environment": "production"}},
+		&models.AzureSQLDatabase{ID: "azsql-1", Subscription: "sub-web-prod", ResourceGroup: "rg-web-prod", Server: "sql-web-prod", SKU: "GP_Gen5_4", VCores: 4, Region: "eastus", CostPerHour: 0.5, Owner: "Product", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-5001", "environment": "production"}},
+		&models.CosmosDBAccount{ID: "cosmos-1", Subscription: "sub-web-prod", ResourceGroup: "rg-web-prod", Region: "eastus", API: "NoSQL", ProvisionedRUs: 4000, ConsumedRUs: 600, CostPer100RUHour: 0.008, Owner: "Product", CreatedAt: created, LastActive: sim.Now().Unix(), Tags: map[string]string{"cost-center": "CC-5001", "environment": "production"}},
 	}
 }
 
 func resourceToString(res models.CloudResource) string {
-	switch r := res.(type) {
-	case *models.VM:
-		return r.String()
-	case *models.Storage:
-		return r.String()
-	case *models.Database:
-		return r.String()
-	case *models.Lambda:
-		return r.ID + ", Lambda, Owner: " + r.Owner
-	default:
-		return "unknown resource"
+	if s, ok := res.(fmt.Stringer); ok {
+		return s.String()
 	}
+	return fmt.Sprintf("%s[ID=%s, Owner=%s, Region=%s, MonthlyCost=%.2f]", res.GetType(), res.GetId(), res.GetOwner(), res.GetRegion(), res.GetMonthlyCost())
 }
@@ -0,0 +1,336 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a scripted simulation run loaded from YAML. Timeline events
+// override numeric resource fields (by Go field name) at offsets from the start
+// of the run, and Expect lists the suggestions the run must or must not raise.
+type Scenario struct {
+	Name     string        `yaml:"name"`
+	Seed     int64         `yaml:"seed"`
+	Start    time.Time     `yaml:"start"`
+	Interval time.Duration `yaml:"interval"`
+	Duration time.Duration `yaml:"duration"`
+	Timeline []Event       `yaml:"timeline"`
+	Expect   []Expectation `yaml:"expect"`
+}
+
+// Event changes one field of one resource from At for For (or until the end of
+// the run when For is zero). Exactly one of Set, Scale, Ramp or Hold may be
+// given; Noise can be combined with any of them or used on its own. Hold pins a
+// field at the value it had when the event started, e.g. to keep a VM idle.
+type Event struct {
+	Resource string        `yaml:"resource"`
+	Field    string        `yaml:"field"`
+	At       time.Duration `yaml:"at"`
+	For      time.Duration `yaml:"for"`
+	Set      *float64      `yaml:"set"`
+	Scale    *float64      `yaml:"scale"`
+	Ramp     *Ramp         `yaml:"ramp"`
+	Hold     bool          `yaml:"hold"`
+	Noise    *Noise        `yaml:"noise"`
+}
+
+// Ramp moves a field linearly from From to To over the event window.
+type Ramp struct {
+	From float64 `yaml:"from"`
+	To   float64 `yaml:"to"`
+}
+
+// Noise adds gaussian noise to a field on every tick of the event window.
+type Noise struct {
+	StdDev float64 `yaml:"stddev"`
+}
+
+// Expectation matches suggestions by resource and, optionally, action, severity
+// and message text, within the [After, Before) window of the run.
+type Expectation struct {
+	Resource        string        `yaml:"resource"`
+	Action          string        `yaml:"action"`
+	Severity        string        `yaml:"severity"`
+	MessageContains string        `yaml:"message_contains"`
+	After           time.Duration `yaml:"after"`
+	Before          time.Duration `yaml:"before"`
+	MinCount        int           `yaml:"min_count"`
+	Absent          bool          `yaml:"absent"`
+}
+
+type ScenarioResult struct {
+	Name        string   `json:"name"`
+	Passed      bool     `json:"passed"`
+	Ticks       int      `json:"ticks"`
+	Suggestions int      `json:"suggestions"`
+	Failures    []string `json:"failures,omitempty"`
+}
+
+// LoadScenario reads and validates the scenario at path. inventory is the set
+// of resources the scenario will run against, nil meaning the mock resources;
+// every event and expectation must name one of them.
+func LoadScenario(path string, inventory []models.CloudResource) (*Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc Scenario
+	if err := yaml.Unmarshal(b, &sc); err != nil {
+		return nil, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	if sc.Duration <= 0 {
+		return nil, fmt.Errorf("scenario %s: needs a positive duration", path)
+	}
+	if sc.Interval <= 0 {
+		sc.Interval = time.Second
+	}
+	if sc.Start.IsZero() {
+		sc.Start = sim.DefaultStart
+	}
+	if inventory == nil {
+		inventory = GenerateMockResources()
+	}
+	known := make(map[string]bool, len(inventory))
+	for _, r := range inventory {
+		known[r.GetId()] = true
+	}
+	for i, ev := range sc.Timeline {
+		if !known[ev.Resource] {
+			return nil, fmt.Errorf("scenario %s: event %d names unknown resource %q", path, i, ev.Resource)
+		}
+		n := 0
+		for _, set := range []bool{ev.Set != nil, ev.Scale != nil, ev.Ramp != nil, ev.Hold} {
+			if set {
+				n++
+			}
+		}
+		if n > 1 || (n == 0 && ev.Noise == nil) {
+			return nil, fmt.Errorf("scenario %s: event %d needs exactly one of set, scale, ramp or hold, or a noise profile", path, i)
+		}
+		if ev.Ramp != nil && ev.For <= 0 {
+			return nil, fmt.Errorf("scenario %s: ramp event %d needs a positive 'for'", path, i)
+		}
+	}
+	for i, exp := range sc.Expect {
+		if !known[exp.Resource] {
+			return nil, fmt.Errorf("scenario %s: expectation %d names unknown resource %q", path, i, exp.Resource)
+		}
+	}
+	return &sc, nil
+}
+
+type eventState struct {
+	active   bool
+	done     bool
+	baseline float64
+	written  float64
+}
+
+// Install seeds the process and installs the scenario's virtual clock. Call it
+// before generating resources so their timestamps come from the scenario start.
+func (sc *Scenario) Install() *sim.VirtualClock {
+	return sim.UseSeed(sc.Seed, sc.Start)
+}
+
+// NewSimulator returns a simulator that applies the timeline to resources on
+// every tick. clock must be the one returned by Install.
+func (sc *Scenario) NewSimulator(resources []models.CloudResource, clock *sim.VirtualClock, sink analyzer.SuggestionSink, logger *zap.Logger) (*Simulator, error) {
+	byID := make(map[string]models.CloudResource, len(resources))
+	for _, r := range resources {
+		byID[r.GetId()] = r
+	}
+	for i, ev := range sc.Timeline {
+		r, ok := byID[ev.Resource]
+		if !ok {
+			return nil, fmt.Errorf("event %d: unknown resource %q", i, ev.Resource)
+		}
+		if _, err := numericField(r, ev.Field); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+
+	states := make([]eventState, len(sc.Timeline))
+	s := &Simulator{
+		Resources: resources,
+		Interval:  sc.Interval,
+		Clock:     clock,
+		Sink:      sink,
+		Logger:    logger,
+	}
+	s.BeforeAnalyze = func(res models.CloudResource) {
+		elapsed := clock.Now().Sub(sc.Start)
+		for i := range sc.Timeline {
+			if sc.Timeline[i].Resource == res.GetId() {
+				sc.Timeline[i].apply(res, elapsed, &states[i])
+			}
+		}
+	}
+	return s, nil
+}
+
+func (ev *Event) apply(res models.CloudResource, elapsed time.Duration, st *eventState) {
+	if st.done || elapsed < ev.At {
+		return
+	}
+	f, _ := numericField(res, ev.Field)
+	if ev.For > 0 && elapsed >= ev.At+ev.For {
+		// Put the field back unless the model has since overwritten it itself.
+		if st.active && getNumeric(f) == st.written && ev.Noise == nil {
+			setNumeric(f, st.baseline)
+		}
+		st.done = true
+		return
+	}
+	if !st.active {
+		st.active = true
+		st.baseline = getNumeric(f)
+	}
+
+	v := getNumeric(f)
+	switch {
+	case ev.Set != nil:
+		v = *ev.Set
+	case ev.Scale != nil:
+		v = st.baseline * *ev.Scale
+	case ev.Ramp != nil:
+		progress := float64(elapsed-ev.At) / float64(ev.For)
+		v = ev.Ramp.From + (ev.Ramp.To-ev.Ramp.From)*progress
+	case ev.Hold:
+		v = st.baseline
+	}
+	if ev.Noise != nil {
+		v = math.Max(0, v+sim.NormFloat64()*ev.Noise.StdDev)
+	}
+	setNumeric(f, v)
+	st.written = getNumeric(f)
+}
+
+func numericField(res models.CloudResource, name string) (reflect.Value, error) {
+	v := reflect.ValueOf(res)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("resource %q is not a struct pointer", res.GetId())
+	}
+	f := v.Elem().FieldByName(name)
+	if !f.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%s %q has no field %q", res.GetType(), res.GetId(), name)
+	}
+	switch f.Kind() {
+	case reflect.Float64, reflect.Int, reflect.Int64:
+		return f, nil
+	}
+	return reflect.Value{}, fmt.Errorf("field %q of %q is not numeric", name, res.GetId())
+}
+
+func getNumeric(f reflect.Value) float64 {
+	if f.Kind() == reflect.Float64 {
+		return f.Float()
+	}
+	return float64(f.Int())
+}
+
+func setNumeric(f reflect.Value, v float64) {
+	if f.Kind() == reflect.Float64 {
+		f.SetFloat(v)
+		return
+	}
+	f.SetInt(int64(math.Round(v)))
+}
+
+// RunScenario runs sc headless against the mock resources as fast as possible
+// and checks its expectations.
+func RunScenario(sc *Scenario, logger *zap.Logger) (*ScenarioResult, error) {
+	defer sim.SetClock(sim.RealClock{})
+
+	clock := sc.Install()
+	sink := &analyzer.InMemorySuggestionSink{}
+	s, err := sc.NewSimulator(GenerateMockResources(), clock, sink, logger)
+	if err != nil {
+		return nil, err
+	}
+	s.History = history.NewStore(0)
+	result := &ScenarioResult{Name: sc.Name}
+	result.Ticks = s.RunFor(sc.Duration)
+
+	suggestions := sink.GetSuggestions()
+	result.Suggestions = len(suggestions)
+	result.Failures = sc.Check(suggestions)
+	result.Passed = len(result.Failures) == 0
+	return result, nil
+}
+
+// Check returns one message per expectation that suggestions do not satisfy.
+func (sc *Scenario) Check(suggestions []analyzer.Suggestion) []string {
+	var failures []string
+	for _, exp := range sc.Expect {
+		count := 0
+		for _, s := range suggestions {
+			if exp.matches(s, s.Timestamp.Sub(sc.Start)) {
+				count++
+			}
+		}
+		min := exp.MinCount
+		if min == 0 {
+			min = 1
+		}
+		switch {
+		case exp.Absent && count > 0:
+			failures = append(failures, fmt.Sprintf("%s: expected no suggestions, got %d", exp, count))
+		case !exp.Absent && count < min:
+			failures = append(failures, fmt.Sprintf("%s: expected at least %d suggestions, got %d", exp, min, count))
+		}
+	}
+	return failures
+}
+
+func (exp Expectation) matches(s analyzer.Suggestion, at time.Duration) bool {
+	if s.ResourceID != exp.Resource {
+		return false
+	}
+	if exp.Action != "" && s.Action != exp.Action {
+		return false
+	}
+	if exp.Severity != "" && s.Severity != exp.Severity {
+		return false
+	}
+	if exp.MessageContains != "" && !strings.Contains(s.Message, exp.MessageContains) {
+		return false
+	}
+	if at < exp.After {
+		return false
+	}
+	if exp.Before > 0 && at >= exp.Before {
+		return false
+	}
+	return true
+}
+
+func (exp Expectation) String() string {
+	parts := []string{"resource=" + exp.Resource}
+	if exp.Action != "" {
+		parts = append(parts, "action="+exp.Action)
+	}
+	if exp.Severity != "" {
+		parts = append(parts, "severity="+exp.Severity)
+	}
+	if exp.MessageContains != "" {
+		parts = append(parts, "message~"+exp.MessageContains)
+	}
+	if exp.After > 0 {
+		parts = append(parts, "after="+exp.After.String())
+	}
+	if exp.Before > 0 {
+		parts = append(parts, "before="+exp.Before.String())
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chanducheryala/cloud-resource/internal/models"
+)
+
+func TestBundledScenariosPass(t *testing.T) {
+	paths, err := filepath.Glob("../scenarios/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no scenarios found: %v", err)
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			sc, err := LoadScenario(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := RunScenario(sc, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Passed {
+				t.Errorf("scenario failed after %d ticks: %v", result.Ticks, result.Failures)
+			}
+		})
+	}
+}
+
+func TestLoadScenarioRejectsInvalid(t *testing.T) {
+	cases := map[string]struct {
+		yaml string
+		err  string
+	}{
+		"no duration": {
+			yaml: "expect:\n  - resource: vm-1\n",
+			err:  "positive duration",
+		},
+		"unknown event resource": {
+			yaml: "duration: 1m\ntimeline:\n  - resource: vm-9\n    field: CPUUsage\n    set: 1\n",
+			err:  `unknown resource "vm-9"`,
+		},
+		"unknown expected resource": {
+			yaml: "duration: 1m\nexpect:\n  - resource: vm-9\n",
+			err:  `unknown resource "vm-9"`,
+		},
+	}
+	for name, c := range cases {
+		path := filepath.Join(t.TempDir(), "scenario.yaml")
+		if err := os.WriteFile(path, []byte(c.yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScenario(path, nil); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want it to mention %q", name, err, c.err)
+		}
+	}
+
+	// A custom inventory replaces the mock resources.
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte("duration: 1m\nexpect:\n  - resource: vm-9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScenario(path, []models.CloudResource{&models.VM{ID: "vm-9"}}); err != nil {
+		t.Errorf("custom inventory: %v", err)
+	}
+}
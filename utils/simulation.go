@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/graph"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/models"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+type SimulationConfig struct {
+	Seeded bool
+	Seed   int64
+	Start  time.Time
+	// Tick is the virtual time that passes per simulation step; zero leaves the
+	// choice to the caller.
+	Tick time.Duration
+	// Speed is how many times faster than wall-clock time the virtual clock runs.
+	Speed float64
+}
+
+// LoadSimulationConfig reads SIM_SEED, SIM_START (RFC 3339), SIM_TICK and
+// SIM_SPEED. Setting SIM_SEED switches the simulation to seeded mode on a
+// virtual clock; setting SIM_SPEED or SIM_TICK does too, with a time-based seed.
+func LoadSimulationConfig() SimulationConfig {
+	_ = godotenv.Load()
+
+	cfg := SimulationConfig{Speed: 1}
+	if tickStr := os.Getenv("SIM_TICK"); tickStr != "" {
+		if d, err := time.ParseDuration(tickStr); err == nil && d > 0 {
+			cfg.Seeded = true
+			cfg.Tick = d
+		}
+	}
+	if speedStr := os.Getenv("SIM_SPEED"); speedStr != "" {
+		if v, err := strconv.ParseFloat(speedStr, 64); err == nil && v > 0 {
+			cfg.Seeded = true
+			cfg.Speed = v
+		}
+	}
+	if cfg.Seeded {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if seedStr := os.Getenv("SIM_SEED"); seedStr != "" {
+		if v, err := strconv.ParseInt(seedStr, 10, 64); err == nil {
+			cfg.Seeded = true
+			cfg.Seed = v
+		}
+	}
+	if startStr := os.Getenv("SIM_START"); startStr != "" {
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			cfg.Start = t
+		}
+	}
+	return cfg
+}
+
+// Simulator steps every resource in order on one goroutine and advances the
+// virtual clock after each tick, so two runs with the same seed are identical.
+type Simulator struct {
+	Resources []models.CloudResource
+	Interval  time.Duration
+	Clock     *sim.VirtualClock
+	Sink      analyzer.SuggestionSink
+	Logger    *zap.Logger
+	// Speed divides the wall-clock pause between ticks in Run; zero means 1.
+	Speed float64
+	// History collects usage for rightsizing; nil means history.Default().
+	History *history.Store
+
+	// BeforeAnalyze, when set, runs after each resource's usage update and
+	// before it is analyzed. Scenarios use it to override fields.
+	BeforeAnalyze func(res models.CloudResource)
+	// AfterAnalyze, when set, runs after each resource has been analyzed, e.g.
+	// to record snapshots in batch runs where nothing reads the out channel.
+	AfterAnalyze func(res models.CloudResource)
+	// Checks run at the end of a step once CheckEvery of virtual time has
+	// passed since they last ran (every step when zero). Watchers that raise
+	// suggestions, such as budget and anomaly checks, run here rather than on
+	// wall-clock tickers so that seeded runs stay reproducible.
+	Checks     []func(now time.Time)
+	CheckEvery time.Duration
+
+	graph     *graph.Graph
+	lastCheck time.Time
+}
+
+// Step updates and analyzes each resource once, then advances the clock by
+// Interval. It returns false if ctx was cancelled while emitting to out.
+func (s *Simulator) Step(ctx context.Context, out chan models.CloudResource) bool {
+	if s.graph == nil {
+		s.graph = graph.Build(s.Resources)
+	}
+	for _, res := range s.Resources {
+		res.UpdateUsage()
+		if s.BeforeAnalyze != nil {
+			s.BeforeAnalyze(res)
+		}
+		analyzer.AnalyzeWith(res, s.Sink, analyzer.Env{Rules: analyzer.CurrentRules(), Now: s.Clock.Now(), History: s.history(), Graph: s.graph})
+		if s.AfterAnalyze != nil {
+			s.AfterAnalyze(res)
+		}
+		if s.Logger != nil {
+			s.Logger.Info("Resource state", zap.String("resource", resourceToString(res)))
+		}
+		if out != nil {
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	s.runChecks(s.Clock.Now())
+	s.Clock.Advance(s.Interval)
+	return true
+}
+
+func (s *Simulator) runChecks(now time.Time) {
+	if len(s.Checks) == 0 || (!s.lastCheck.IsZero() && now.Sub(s.lastCheck) < s.CheckEvery) {
+		return
+	}
+	s.lastCheck = now
+	for _, check := range s.Checks {
+		check(now)
+	}
+}
+
+func (s *Simulator) history() *history.Store {
+	if s.History == nil {
+		return history.Default()
+	}
+	return s.History
+}
+
+// StartSeededSimulation is the deterministic counterpart of StartSimulation. The
+// clock must be the one installed by sim.UseSeed. checks run every checkEvery
+// of virtual time, as Simulator.Checks.
+func StartSeededSimulation(ctx context.Context, resources []models.CloudResource, cfg SimulationConfig, out chan models.CloudResource, logger *zap.Logger, suggestionSink analyzer.SuggestionSink, clock *sim.VirtualClock, checkEvery time.Duration, checks ...func(now time.Time)) {
+	tick := cfg.Tick
+	if tick == 0 {
+		tick = time.Second
+	}
+	s := &Simulator{
+		Resources:  resources,
+		Interval:   tick,
+		Clock:      clock,
+		Sink:       suggestionSink,
+		Logger:     logger,
+		Speed:      cfg.Speed,
+		Checks:     checks,
+		CheckEvery: checkEvery,
+	}
+	s.Run(ctx, out)
+}
+
+// Run steps the simulation until ctx is cancelled, pausing Interval/Speed of
+// wall-clock time between ticks.
+func (s *Simulator) Run(ctx context.Context, out chan models.CloudResource) {
+	pause := s.Interval
+	if s.Speed > 0 {
+		pause = time.Duration(float64(s.Interval) / s.Speed)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if !s.Step(ctx, out) {
+			return
+		}
+		time.Sleep(pause)
+	}
+}
+
+// RunFor steps the simulation without pausing until d of virtual time has
+// passed, and returns the number of ticks taken.
+func (s *Simulator) RunFor(d time.Duration) int {
+	end := s.Clock.Now().Add(d)
+	ticks := 0
+	for s.Clock.Now().Before(end) {
+		s.Step(context.Background(), nil)
+		ticks++
+	}
+	return ticks
+}
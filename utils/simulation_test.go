@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chanducheryala/cloud-resource/internal/analyzer"
+	"github.com/chanducheryala/cloud-resource/internal/anomaly"
+	"github.com/chanducheryala/cloud-resource/internal/budget"
+	"github.com/chanducheryala/cloud-resource/internal/history"
+	"github.com/chanducheryala/cloud-resource/internal/savings"
+	"github.com/chanducheryala/cloud-resource/internal/sim"
+)
+
+// runSeeded steps a seeded simulation with the service's watchers: a budget
+// small enough to cross its thresholds, anomaly detection and savings
+// tracking, all driven by the simulation's virtual clock.
+func runSeeded(t *testing.T, seed int64, steps int) []byte {
+	clock := sim.UseSeed(seed, time.Time{})
+	hist := history.NewStore(0)
+	sink := &analyzer.InMemorySuggestionSink{}
+	resources := GenerateMockResources()
+	budgets := budget.NewStore()
+	if _, err := budgets.Create(budget.Budget{MonthlyLimitUSD: 1}, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	monitor := &budget.Monitor{Budgets: budgets, History: hist, Sink: sink}
+	detector := &anomaly.Detector{History: hist, Sink: sink}
+	tracker := savings.NewTracker(hist, 0, 0)
+	s := &Simulator{
+		Resources: resources,
+		Interval:  time.Second,
+		Clock:     clock,
+		Sink:      &savings.TrackingSink{SuggestionSink: sink, Tracker: tracker},
+		History:   hist,
+		Checks: []func(now time.Time){
+			tracker.Sweep,
+			func(now time.Time) { monitor.Check(resources, now) },
+			func(now time.Time) { detector.Check(resources, now) },
+		},
+		CheckEvery: 10 * time.Second,
+	}
+	for i := 0; i < steps; i++ {
+		s.Step(context.Background(), nil)
+	}
+	budgetAlerts := 0
+	for _, sg := range sink.GetSuggestions() {
+		if sg.ResourceType == "Budget" {
+			budgetAlerts++
+		}
+	}
+	if budgetAlerts == 0 {
+		t.Error("the budget monitor raised no alerts")
+	}
+	b, err := json.Marshal(sink.GetSuggestions())
+	if err != nil {
+		t.Fatalf("marshal suggestions: %v", err)
+	}
+	return b
+}
+
+func TestSeededSimulationIsDeterministic(t *testing.T) {
+	defer sim.SetClock(sim.RealClock{})
+
+	first := runSeeded(t, 42, 50)
+	second := runSeeded(t, 42, 50)
+	if !bytes.Equal(first, second) {
+		t.Fatal("same seed produced different suggestion output")
+	}
+	other := runSeeded(t, 7, 50)
+	if bytes.Equal(first, other) {
+		t.Error("different seeds produced identical suggestion output")
+	}
+}